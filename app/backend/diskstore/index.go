@@ -0,0 +1,42 @@
+package diskstore
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+var _ store.IndexStore = (*IndexStore)(nil)
+
+// IndexStore 以单个文件保存离线文件索引。
+type IndexStore struct {
+	path string
+}
+
+// NewIndexStore 创建索引文件存储，所在目录不存在时自动创建。
+func NewIndexStore(path string) (*IndexStore, error) {
+	if path == "" {
+		return nil, errors.New("diskstore: 索引文件路径为空")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &IndexStore{path: path}, nil
+}
+
+// LoadIndex 读取索引，文件不存在时返回 nil, nil。
+func (s *IndexStore) LoadIndex() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SaveIndex 原子覆盖索引文件。
+func (s *IndexStore) SaveIndex(data []byte) error {
+	return writeAtomic(filepath.Dir(s.path), s.path, data)
+}
@@ -0,0 +1,25 @@
+package diskstore
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestIndexStore_SaveLoad 未保存时返回 nil，保存后覆盖读取。
+func TestIndexStore_SaveLoad(t *testing.T) {
+	s, err := NewIndexStore(filepath.Join(t.TempDir(), "cache", "index.json"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	if data, err := s.LoadIndex(); data != nil || err != nil {
+		t.Fatalf("未保存时应返回 nil, nil: %q %v", data, err)
+	}
+	for _, content := range []string{"first", "second"} {
+		if err := s.SaveIndex([]byte(content)); err != nil {
+			t.Fatalf("保存失败: %v", err)
+		}
+	}
+	if data, err := s.LoadIndex(); err != nil || string(data) != "second" {
+		t.Fatalf("读取内容不符: %q %v", data, err)
+	}
+}
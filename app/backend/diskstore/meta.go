@@ -0,0 +1,86 @@
+package diskstore
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+var _ store.MetaCacheStore = (*MetaStore)(nil)
+
+// MetaStore 以目录保存文件元数据缓存，每个键一个文件。
+type MetaStore struct {
+	dir string
+}
+
+// NewMetaStore 创建元数据目录存储。
+func NewMetaStore(dir string) (*MetaStore, error) {
+	if dir == "" {
+		return nil, errors.New("diskstore: 元数据目录为空")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &MetaStore{dir: dir}, nil
+}
+
+// LoadMeta 读取缓存，未命中时返回 nil, nil。
+func (s *MetaStore) LoadMeta(key string) ([]byte, error) {
+	p, err := keyPath(s.dir, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SaveMeta 原子写入缓存。
+func (s *MetaStore) SaveMeta(key string, data []byte) error {
+	p, err := keyPath(s.dir, key)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(s.dir, p, data)
+}
+
+// DeleteMeta 删除缓存，不存在时忽略。
+func (s *MetaStore) DeleteMeta(key string) error {
+	p, err := keyPath(s.dir, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ClearMeta 删除目录下的列表与文件信息缓存（list_、info_ 前缀），不影响目录中的其他文件。
+func (s *MetaStore) ClearMeta() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !isMetaKey(e.Name()) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, e.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isMetaKey 判断文件名是否为 MetaCache 写入的缓存键。
+func isMetaKey(name string) bool {
+	return strings.HasPrefix(name, "list_") || strings.HasPrefix(name, "info_")
+}
@@ -0,0 +1,44 @@
+package diskstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMetaStore_SaveLoadClear 写入后可跨实例读取，清空后缓存全部未命中，目录中的其他文件保留。
+func TestMetaStore_SaveLoadClear(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewMetaStore(dir)
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	for _, key := range []string{"list_-11", "info_30"} {
+		if err := s.SaveMeta(key, []byte(key)); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	reopened, _ := NewMetaStore(dir)
+	if data, err := reopened.LoadMeta("list_-11"); err != nil || string(data) != "list_-11" {
+		t.Fatalf("重新打开后应读取到缓存: %q %v", data, err)
+	}
+	if err := reopened.DeleteMeta("missing"); err != nil {
+		t.Fatalf("删除不存在的键不应报错: %v", err)
+	}
+	other := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(other, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("写入其他文件失败: %v", err)
+	}
+	if err := reopened.ClearMeta(); err != nil {
+		t.Fatalf("清空失败: %v", err)
+	}
+	if data, _ := s.LoadMeta("info_30"); data != nil {
+		t.Fatalf("清空后不应命中: %q", data)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("清空不应删除非缓存文件: %v", err)
+	}
+	if _, err := s.LoadMeta("../escape"); err == nil {
+		t.Fatalf("非法缓存键应返回错误")
+	}
+}
@@ -0,0 +1,121 @@
+package diskstore
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+var _ store.SyncStateStore = (*SyncStateStore)(nil)
+
+// SyncStateStore 以每个同步目录对一个 JSON 文件保存同步记录。
+type SyncStateStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewSyncStateStore 创建同步状态存储，目录不存在时自动创建。
+func NewSyncStateStore(dir string) (*SyncStateStore, error) {
+	if dir == "" {
+		return nil, errors.New("diskstore: 同步状态目录为空")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &SyncStateStore{dir: dir}, nil
+}
+
+// LoadRecords 读取目录对的全部记录，从未保存时返回空。
+func (s *SyncStateStore) LoadRecords(pairID string) ([]store.SyncRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.load(pairID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]store.SyncRecord, 0, len(records))
+	for _, rec := range records {
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// SaveRecords 按路径写入或覆盖记录。
+func (s *SyncStateStore) SaveRecords(pairID string, records []store.SyncRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.load(pairID)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		current[rec.Path] = rec
+	}
+	return s.save(pairID, current)
+}
+
+// DeleteRecords 删除指定路径的记录。
+func (s *SyncStateStore) DeleteRecords(pairID string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.load(pairID)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		delete(current, p)
+	}
+	return s.save(pairID, current)
+}
+
+func (s *SyncStateStore) load(pairID string) (map[string]store.SyncRecord, error) {
+	p, err := keyPath(s.dir, pairID+".json")
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]store.SyncRecord)
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []store.SyncRecord
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	for _, rec := range list {
+		records[rec.Path] = rec
+	}
+	return records, nil
+}
+
+func (s *SyncStateStore) save(pairID string, records map[string]store.SyncRecord) error {
+	p, err := keyPath(s.dir, pairID+".json")
+	if err != nil {
+		return err
+	}
+	list := make([]store.SyncRecord, 0, len(records))
+	for _, rec := range records {
+		list = append(list, rec)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(s.dir, p, data)
+}
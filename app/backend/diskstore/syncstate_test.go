@@ -0,0 +1,46 @@
+package diskstore
+
+import (
+	"testing"
+
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+// TestSyncStateStore_Records 按目录对隔离，写入按路径覆盖，删除后重新打开仍一致。
+func TestSyncStateStore_Records(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSyncStateStore(dir)
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	if records, err := s.LoadRecords("docs"); err != nil || len(records) != 0 {
+		t.Fatalf("未保存时应为空: %v %v", records, err)
+	}
+	if err := s.SaveRecords("docs", []store.SyncRecord{
+		{Path: "b.txt", Size: 1},
+		{Path: "a", IsFolder: true},
+	}); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if err := s.SaveRecords("docs", []store.SyncRecord{{Path: "b.txt", Size: 2}}); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if err := s.SaveRecords("photos", []store.SyncRecord{{Path: "c.jpg"}}); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if err := s.DeleteRecords("docs", []string{"a", "missing"}); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+
+	reopened, _ := NewSyncStateStore(dir)
+	records, err := reopened.LoadRecords("docs")
+	if err != nil || len(records) != 1 || records[0].Path != "b.txt" || records[0].Size != 2 {
+		t.Fatalf("记录不符: %+v %v", records, err)
+	}
+	if records, _ := reopened.LoadRecords("photos"); len(records) != 1 {
+		t.Fatalf("目录对之间不应互相影响: %+v", records)
+	}
+	if _, err := s.LoadRecords("../escape"); err == nil {
+		t.Fatalf("非法目录对标识应返回错误")
+	}
+}
@@ -0,0 +1,159 @@
+// Package diskstore 提供 core/store 接口的本地磁盘实现。
+package diskstore
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+var _ store.ThumbnailStore = (*ThumbnailStore)(nil)
+
+// ThumbnailStore 以目录保存缩略图，超出容量上限时按最近访问时间淘汰。
+type ThumbnailStore struct {
+	dir      string
+	maxBytes int64
+
+	mu sync.Mutex
+}
+
+// NewThumbnailStore 创建缩略图目录存储，maxBytes <= 0 表示不限容量。
+func NewThumbnailStore(dir string, maxBytes int64) (*ThumbnailStore, error) {
+	if dir == "" {
+		return nil, errors.New("diskstore: 缩略图目录为空")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &ThumbnailStore{dir: dir, maxBytes: maxBytes}, nil
+}
+
+// LoadThumbnail 读取缓存并刷新访问时间，未命中时返回 nil, nil。
+func (s *ThumbnailStore) LoadThumbnail(key string) ([]byte, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return data, nil
+}
+
+// SaveThumbnail 原子写入缓存，必要时淘汰旧文件。
+func (s *ThumbnailStore) SaveThumbnail(key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(s.dir, p, data); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// DeleteThumbnail 删除缓存，不存在时忽略。
+func (s *ThumbnailStore) DeleteThumbnail(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *ThumbnailStore) path(key string) (string, error) {
+	return keyPath(s.dir, key)
+}
+
+// keyPath 返回缓存键在目录中的文件路径，拒绝可能逃逸目录或与临时文件冲突的键。
+func keyPath(dir, key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." || strings.HasPrefix(key, ".tmp-") {
+		return "", errors.New("diskstore: 非法缓存键 " + key)
+	}
+	return filepath.Join(dir, key), nil
+}
+
+// writeAtomic 先写临时文件再重命名，避免读到半截内容。
+func writeAtomic(dir, p string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// prune 在总大小超过上限时删除最久未访问的文件。
+func (s *ThumbnailStore) prune() error {
+	if s.maxBytes <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	type item struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var (
+		items []item
+		total int64
+	)
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".tmp-") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		items = append(items, item{path: filepath.Join(s.dir, e.Name()), size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	if total <= s.maxBytes {
+		return nil
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].modTime.Before(items[j].modTime) })
+	for _, it := range items {
+		if total <= s.maxBytes {
+			break
+		}
+		if err := os.Remove(it.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		total -= it.size
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package diskstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestThumbnailStore_PruneOldest 超出容量时淘汰最久未访问的缓存。
+func TestThumbnailStore_PruneOldest(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewThumbnailStore(dir, 10)
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	if err := s.SaveThumbnail("old", []byte("123456")); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "old"), past, past); err != nil {
+		t.Fatalf("修改时间失败: %v", err)
+	}
+	if err := s.SaveThumbnail("new", []byte("123456")); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if data, _ := s.LoadThumbnail("old"); data != nil {
+		t.Fatalf("旧缓存应被淘汰")
+	}
+	if data, _ := s.LoadThumbnail("new"); string(data) != "123456" {
+		t.Fatalf("新缓存内容不符: %q", data)
+	}
+	if _, err := s.LoadThumbnail("../escape"); err == nil {
+		t.Fatalf("非法缓存键应返回错误")
+	}
+}
@@ -164,8 +164,8 @@ func readLine(r *bufio.Reader) string {
 func runAllTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Client) {
 	fmt.Println("\n========== 开始测试所有 API ==========")
 
-	// 用于清理的 ID 列表
-	var cleanupIDs []string
+	// 用于清理的文件列表
+	var cleanup []cloud189.FileInfo
 	var testFolderID string
 
 	// 1. GetUserInfo
@@ -222,13 +222,16 @@ func runAllTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Cli
 		fmt.Println("创建文件夹失败，后续测试可能受影响")
 	} else {
 		testFolderID = folder.ID.String()
-		cleanupIDs = append(cleanupIDs, folder.ID.String())
+		cleanup = append(cleanup, cloud189.FileInfo{ID: folder.ID, FileName: folder.FileName, IsFolder: true})
 		fmt.Printf("创建成功: %s (ID: %s)\n", folder.FileName, folder.ID)
 	}
 
 	// 6. SimpleUpload
 	fmt.Println("\n--- 6. SimpleUpload 上传文件 ---")
-	var uploadedFileID string
+	var (
+		uploadedFileID string
+		uploadedFile   cloud189.FileInfo
+	)
 	if testFolderID != "" {
 		uploadName := fmt.Sprintf("test_file_%d.txt", time.Now().Unix())
 		payload := make([]byte, 1024)
@@ -237,7 +240,8 @@ func runAllTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Cli
 		uploaded, err := client.SimpleUpload(ctx, testFolderID, uploadName, bytes.NewReader(payload))
 		if !checkErr("SimpleUpload", err) {
 			uploadedFileID = uploaded.ID.String()
-			cleanupIDs = append(cleanupIDs, uploaded.ID.String())
+			uploadedFile = cloud189.FileInfo{ID: uploaded.ID, FileName: uploaded.FileName}
+			cleanup = append(cleanup, uploadedFile)
 			fmt.Printf("上传成功: %s (ID: %s, 大小: %d)\n", uploaded.FileName, uploaded.ID, uploaded.FileSize)
 		}
 	} else {
@@ -307,8 +311,8 @@ func runAllTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Cli
 		copyDestName := fmt.Sprintf("copy_dest_%d", time.Now().Unix())
 		copyDest, err := client.CreateFolder(ctx, rootFolderID, copyDestName)
 		if !checkErr("CreateFolder(复制目标)", err) {
-			cleanupIDs = append(cleanupIDs, copyDest.ID.String())
-			err = client.CopyFiles(ctx, []string{uploadedFileID}, copyDest.ID.String())
+			cleanup = append(cleanup, cloud189.FileInfo{ID: copyDest.ID, FileName: copyDest.FileName, IsFolder: true})
+			err = client.CopyFiles(ctx, []cloud189.FileInfo{uploadedFile}, copyDest.ID.String())
 			if !checkErr("CopyFiles", err) {
 				fmt.Printf("复制成功: 文件已复制到 %s\n", copyDestName)
 			}
@@ -324,14 +328,14 @@ func runAllTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Cli
 		moveDestName := fmt.Sprintf("move_dest_%d", time.Now().Unix())
 		moveDest, err := client.CreateFolder(ctx, rootFolderID, moveDestName)
 		if !checkErr("CreateFolder(移动目标)", err) {
-			cleanupIDs = append(cleanupIDs, moveDest.ID.String())
-			err = client.MoveFiles(ctx, []string{uploadedFileID}, moveDest.ID.String())
+			cleanup = append(cleanup, cloud189.FileInfo{ID: moveDest.ID, FileName: moveDest.FileName, IsFolder: true})
+			err = client.MoveFiles(ctx, []cloud189.FileInfo{uploadedFile}, moveDest.ID.String())
 			if !checkErr("MoveFiles", err) {
 				fmt.Printf("移动成功: 文件已移动到 %s\n", moveDestName)
-				// 移动后文件不在原文件夹了，从 cleanupIDs 移除（会随目标文件夹一起删除）
-				for i, id := range cleanupIDs {
-					if id == uploadedFileID {
-						cleanupIDs = append(cleanupIDs[:i], cleanupIDs[i+1:]...)
+				// 移动后文件不在原文件夹了，从 cleanup 移除（会随目标文件夹一起删除）
+				for i, f := range cleanup {
+					if f.ID.String() == uploadedFileID {
+						cleanup = append(cleanup[:i], cleanup[i+1:]...)
 						break
 					}
 				}
@@ -343,9 +347,9 @@ func runAllTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Cli
 
 	// 13. DeleteFiles
 	fmt.Println("\n--- 13. DeleteFiles 删除测试数据 ---")
-	if len(cleanupIDs) > 0 {
-		fmt.Printf("准备删除 %d 个测试文件/文件夹...\n", len(cleanupIDs))
-		err := client.DeleteFiles(ctx, cleanupIDs)
+	if len(cleanup) > 0 {
+		fmt.Printf("准备删除 %d 个测试文件/文件夹...\n", len(cleanup))
+		err := client.DeleteFiles(ctx, cleanup)
 		if !checkErr("DeleteFiles", err) {
 			fmt.Println("删除成功!")
 		}
@@ -126,8 +126,8 @@ func truncate(s string, n int) string {
 func runWebTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Client) {
 	fmt.Println("\n========== 开始测试 Web API ==========")
 
-	// 用于清理的 ID 列表
-	var cleanupIDs []string
+	// 用于清理的文件列表
+	var cleanup []cloud189.FileInfo
 	var testFolderID string
 
 	// 1. 获取用户简要信息（Web API）
@@ -167,7 +167,7 @@ func runWebTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Cli
 		fmt.Println("创建文件夹失败，后续测试可能受影响")
 	} else {
 		testFolderID = folder.ID.String()
-		cleanupIDs = append(cleanupIDs, folder.ID.String())
+		cleanup = append(cleanup, cloud189.FileInfo{ID: folder.ID, FileName: folder.FileName, IsFolder: true})
 		fmt.Printf("创建成功: %s (ID: %s)\n", folder.FileName, folder.ID)
 	}
 
@@ -183,7 +183,7 @@ func runWebTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Cli
 		uploaded, err := client.WebSimpleUpload(ctx, testFolderID, uploadName, bytes.NewReader(payload), rsaKey)
 		if !webCheckErr("WebSimpleUpload", err) {
 			uploadedFileID = uploaded.ID.String()
-			cleanupIDs = append(cleanupIDs, uploaded.ID.String())
+			cleanup = append(cleanup, cloud189.FileInfo{ID: uploaded.ID, FileName: uploaded.FileName})
 			fmt.Printf("Web 上传成功: %s (ID: %s, 大小: %d)\n", uploaded.FileName, uploaded.ID, uploaded.FileSize)
 		}
 	} else {
@@ -257,9 +257,9 @@ func runWebTests(ctx context.Context, client *cloud189.Client, rawHTTP *http.Cli
 
 	// 10. 删除测试数据
 	fmt.Println("\n--- 10. DeleteFiles 删除测试数据 ---")
-	if len(cleanupIDs) > 0 {
-		fmt.Printf("准备删除 %d 个测试文件/文件夹...\n", len(cleanupIDs))
-		err := client.DeleteFiles(ctx, cleanupIDs)
+	if len(cleanup) > 0 {
+		fmt.Printf("准备删除 %d 个测试文件/文件夹...\n", len(cleanup))
+		err := client.DeleteFiles(ctx, cleanup)
 		if !webCheckErr("DeleteFiles", err) {
 			fmt.Println("删除成功!")
 		}
@@ -306,7 +306,7 @@ func runWebTaskTests(ctx context.Context, client *cloud189.Client, rawHTTP *http
 	// 清理函数
 	defer func() {
 		fmt.Println("\n--- 清理测试数据 ---")
-		if err := client.DeleteFiles(ctx, []string{testFolderID}); err != nil {
+		if err := client.DeleteFiles(ctx, []cloud189.FileInfo{{ID: folder.ID, FileName: folder.FileName, IsFolder: true}}); err != nil {
 			fmt.Printf("清理失败: %v\n", err)
 		} else {
 			fmt.Println("清理成功!")
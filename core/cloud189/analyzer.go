@@ -0,0 +1,274 @@
+package cloud189
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// defaultAnalyzeTopN 默认保留的最大文件/文件夹数量。
+const defaultAnalyzeTopN = 20
+
+// categoryOther 无法识别媒体类型的文件归入此分类。
+const categoryOther = "other"
+
+// FolderUsage 文件夹占用统计，Size 与 FileCount 包含全部子孙。
+type FolderUsage struct {
+	ID        string `json:"id"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	FileCount int    `json:"fileCount"`
+
+	parentID string
+}
+
+// FileUsage 单个文件的占用。
+type FileUsage struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// CategoryUsage 按媒体分类的占用统计。
+type CategoryUsage struct {
+	Category  string `json:"category"`
+	Size      int64  `json:"size"`
+	FileCount int    `json:"fileCount"`
+}
+
+// UsageReport 子树空间占用报告。
+type UsageReport struct {
+	RootID      string              `json:"rootId"`
+	RootPath    string              `json:"rootPath"`
+	Size        int64               `json:"size"`
+	FileCount   int                 `json:"fileCount"`
+	FolderCount int                 `json:"folderCount"`
+	Folders     []FolderUsage       `json:"folders"`
+	Categories  []CategoryUsage     `json:"categories"`
+	TopFiles    []FileUsage         `json:"topFiles"`
+	TopFolders  []FolderUsage       `json:"topFolders"`
+	Quota       *model.StorageQuota `json:"quota,omitempty"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+}
+
+// Headroom 返回剩余可用空间与已用百分比，未获取配额时 ok 为 false。
+func (r *UsageReport) Headroom() (available uint64, usedPercent float64, ok bool) {
+	if r == nil || r.Quota == nil || r.Quota.Capacity == 0 {
+		return 0, 0, false
+	}
+	return r.Quota.Available, float64(r.Quota.Used) / float64(r.Quota.Capacity) * 100, true
+}
+
+// SharePercent 返回本子树占已用空间的百分比，未获取配额时 ok 为 false。
+func (r *UsageReport) SharePercent() (percent float64, ok bool) {
+	if r == nil || r.Quota == nil || r.Quota.Used == 0 {
+		return 0, false
+	}
+	return float64(r.Size) / float64(r.Quota.Used) * 100, true
+}
+
+// WriteJSON 以缩进 JSON 输出报告。
+func (r *UsageReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV 以 CSV 输出报告，每行一条记录：type,path,id,size,fileCount。
+// type 取值 summary/quota/category/folder/top_file/top_folder。
+func (r *UsageReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"type", "path", "id", "size", "fileCount"},
+		{"summary", r.RootPath, r.RootID, formatInt(r.Size), strconv.Itoa(r.FileCount)},
+	}
+	if r.Quota != nil {
+		rows = append(rows,
+			[]string{"quota", "capacity", "", strconv.FormatUint(r.Quota.Capacity, 10), ""},
+			[]string{"quota", "used", "", strconv.FormatUint(r.Quota.Used, 10), ""},
+			[]string{"quota", "available", "", strconv.FormatUint(r.Quota.Available, 10), ""},
+		)
+	}
+	for _, c := range r.Categories {
+		rows = append(rows, []string{"category", c.Category, "", formatInt(c.Size), strconv.Itoa(c.FileCount)})
+	}
+	for _, f := range r.Folders {
+		rows = append(rows, []string{"folder", f.Path, f.ID, formatInt(f.Size), strconv.Itoa(f.FileCount)})
+	}
+	for _, f := range r.TopFiles {
+		rows = append(rows, []string{"top_file", f.Path, f.ID, formatInt(f.Size), "1"})
+	}
+	for _, f := range r.TopFolders {
+		rows = append(rows, []string{"top_folder", f.Path, f.ID, formatInt(f.Size), strconv.Itoa(f.FileCount)})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// AnalyzeOption 配置空间分析。
+type AnalyzeOption func(*analyzeConfig)
+
+type analyzeConfig struct {
+	topN      int
+	skipQuota bool
+	walkOpts  []WalkOption
+}
+
+// WithAnalyzeTopN 设置报告中最大文件/文件夹的数量。
+func WithAnalyzeTopN(n int) AnalyzeOption {
+	return func(cfg *analyzeConfig) {
+		if n > 0 {
+			cfg.topN = n
+		}
+	}
+}
+
+// WithAnalyzeSkipQuota 不查询账号配额。
+func WithAnalyzeSkipQuota() AnalyzeOption {
+	return func(cfg *analyzeConfig) {
+		cfg.skipQuota = true
+	}
+}
+
+// WithAnalyzeWalkOptions 透传遍历参数（并发、深度等）。
+func WithAnalyzeWalkOptions(opts ...WalkOption) AnalyzeOption {
+	return func(cfg *analyzeConfig) {
+		cfg.walkOpts = append(cfg.walkOpts, opts...)
+	}
+}
+
+// AnalyzeUsage 遍历 rootID 子树并统计空间占用。
+func (c *Client) AnalyzeUsage(ctx context.Context, rootID, rootPath string, opts ...AnalyzeOption) (*UsageReport, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	cfg := analyzeConfig{topN: defaultAnalyzeTopN}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	rootPath = CleanPath(rootPath)
+	folders := map[string]*FolderUsage{rootID: {ID: rootID, Path: rootPath}}
+	categories := make(map[string]*CategoryUsage)
+	var files []FileUsage
+
+	err := c.Walk(ctx, rootID, rootPath, func(f model.File) error {
+		if f.IsFolder {
+			folders[f.ID] = &FolderUsage{ID: f.ID, Path: f.Path, parentID: f.ParentID}
+			return nil
+		}
+		if parent, ok := folders[f.ParentID]; ok {
+			parent.Size += f.Size
+			parent.FileCount++
+		}
+		name := categoryOf(f)
+		cat, ok := categories[name]
+		if !ok {
+			cat = &CategoryUsage{Category: name}
+			categories[name] = cat
+		}
+		cat.Size += f.Size
+		cat.FileCount++
+		files = append(files, FileUsage{ID: f.ID, Path: f.Path, Size: f.Size})
+		return nil
+	}, cfg.walkOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{
+		RootID:      rootID,
+		RootPath:    rootPath,
+		FolderCount: len(folders) - 1,
+		GeneratedAt: time.Now(),
+	}
+	report.Folders = rollupFolders(folders, rootID)
+	root := folders[rootID]
+	report.Size, report.FileCount = root.Size, root.FileCount
+
+	for _, cat := range categories {
+		report.Categories = append(report.Categories, *cat)
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		if report.Categories[i].Size != report.Categories[j].Size {
+			return report.Categories[i].Size > report.Categories[j].Size
+		}
+		return report.Categories[i].Category < report.Categories[j].Category
+	})
+
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+	report.TopFiles = files[:min(cfg.topN, len(files))]
+	for _, f := range report.Folders {
+		if len(report.TopFolders) >= cfg.topN {
+			break
+		}
+		if f.ID != rootID {
+			report.TopFolders = append(report.TopFolders, f)
+		}
+	}
+
+	if !cfg.skipQuota {
+		capacity, err := c.GetCapacity(ctx)
+		if err != nil {
+			return nil, err
+		}
+		quota := capacity.ToModel()
+		report.Quota = &quota
+	}
+	return report, nil
+}
+
+// rollupFolders 自底向上累加子目录占用，返回按大小倒序的全部文件夹（含根）。
+func rollupFolders(folders map[string]*FolderUsage, rootID string) []FolderUsage {
+	list := make([]*FolderUsage, 0, len(folders))
+	for _, f := range folders {
+		list = append(list, f)
+	}
+	// 路径越深越先处理，保证累加到父目录时子目录已完整
+	sort.Slice(list, func(i, j int) bool {
+		return strings.Count(list[i].Path, "/") > strings.Count(list[j].Path, "/")
+	})
+	for _, f := range list {
+		if f.ID == rootID {
+			continue
+		}
+		if parent, ok := folders[f.parentID]; ok {
+			parent.Size += f.Size
+			parent.FileCount += f.FileCount
+		}
+	}
+	result := make([]FolderUsage, 0, len(list))
+	for _, f := range list {
+		result = append(result, *f)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Size != result[j].Size {
+			return result[i].Size > result[j].Size
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+func categoryOf(f model.File) string {
+	kind := mediaKindOf(f.MediaType, f.Category)
+	if kind == 0 {
+		return categoryOther
+	}
+	return kind.String()
+}
+
+func formatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
@@ -0,0 +1,69 @@
+package cloud189
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestAnalyzeUsage_Aggregates 验证目录累加、分类统计、TopN 与配额余量。
+func TestAnalyzeUsage_Aggregates(t *testing.T) {
+	tree := newWalkTree()
+	tree.children["11"][0]["mediaType"] = 2
+	mux := http.NewServeMux()
+	mux.Handle("/", tree.handler())
+	mux.HandleFunc("/getUserInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "capacity": 1000, "usedSize": 400, "available": 600})
+	})
+	client := newTestClient(t, mux)
+
+	report, err := client.AnalyzeUsage(context.Background(), RootFolderID, "/", WithAnalyzeTopN(2))
+	if err != nil {
+		t.Fatalf("分析失败: %v", err)
+	}
+	if report.Size != 48 || report.FileCount != 3 || report.FolderCount != 3 {
+		t.Fatalf("汇总不符: size=%d files=%d folders=%d", report.Size, report.FileCount, report.FolderCount)
+	}
+	sizes := map[string]int64{}
+	for _, f := range report.Folders {
+		sizes[f.Path] = f.Size
+	}
+	if sizes["/Documents"] != 42 || sizes["/Documents/2024"] != 42 || sizes["/Music"] != 5 {
+		t.Fatalf("目录累加不符: %v", sizes)
+	}
+	if len(report.TopFiles) != 2 || report.TopFiles[0].Path != "/Documents/2024/report.pdf" {
+		t.Fatalf("TopFiles 不符: %+v", report.TopFiles)
+	}
+	if len(report.TopFolders) != 2 || report.TopFolders[0].Size != 42 {
+		t.Fatalf("TopFolders 不符: %+v", report.TopFolders)
+	}
+	if len(report.Categories) != 2 || report.Categories[0].Category != categoryOther || report.Categories[1].Category != "music" {
+		t.Fatalf("分类统计不符: %+v", report.Categories)
+	}
+	if avail, used, ok := report.Headroom(); !ok || avail != 600 || used != 40 {
+		t.Fatalf("配额余量不符: %d %.1f %v", avail, used, ok)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("导出 JSON 失败: %v", err)
+	}
+	var decoded UsageReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Size != 48 {
+		t.Fatalf("JSON 回读失败: %v size=%d", err, decoded.Size)
+	}
+	buf.Reset()
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("导出 CSV 失败: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV 解析失败: %v", err)
+	}
+	if rows[1][0] != "summary" || rows[1][3] != "48" {
+		t.Fatalf("CSV 汇总行不符: %v", rows[1])
+	}
+}
@@ -0,0 +1,419 @@
+package cloud189
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 批量任务类型。
+const (
+	BatchTaskDelete = "DELETE"
+	BatchTaskMove   = "MOVE"
+	BatchTaskCopy   = "COPY"
+	BatchTaskSave   = "SHARE_SAVE"
+
+	BatchTaskRestore      = "RESTORE"
+	BatchTaskClearRecycle = "CLEAR_RECYCLE"
+	BatchTaskEmptyRecycle = "EMPTY_RECYCLE"
+)
+
+// 批量任务状态。响应中缺少状态字段（0）时视为尚未开始。
+const (
+	batchTaskStatusPending  = 1
+	batchTaskStatusConflict = 2
+	batchTaskStatusRunning  = 3
+	batchTaskStatusDone     = 4
+)
+
+// DefaultBatchPollInterval 批量任务状态默认轮询间隔。
+const DefaultBatchPollInterval = 500 * time.Millisecond
+
+// DefaultBatchTimeout 单次等待批量任务完成或出现冲突的默认最长时间。
+const DefaultBatchTimeout = 30 * time.Minute
+
+// ConflictPolicy 描述批量任务遇到同名文件时的处理策略。
+type ConflictPolicy int
+
+const (
+	// ConflictSkip 跳过同名文件。
+	ConflictSkip ConflictPolicy = iota
+	// ConflictRename 保留两者，服务端自动重命名新文件。
+	ConflictRename
+	// ConflictOverwrite 覆盖目标目录中的同名文件。
+	ConflictOverwrite
+)
+
+// String 返回冲突策略的字符串表示。
+func (p ConflictPolicy) String() string {
+	switch p {
+	case ConflictSkip:
+		return "skip"
+	case ConflictRename:
+		return "rename"
+	case ConflictOverwrite:
+		return "overwrite"
+	default:
+		return "unknown"
+	}
+}
+
+// dealWay 转换为服务端 dealWay 编码。
+func (p ConflictPolicy) dealWay() int {
+	switch p {
+	case ConflictRename:
+		return 2
+	case ConflictOverwrite:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// batchTaskInfo 描述批量任务中的单个文件。
+type batchTaskInfo struct {
+	FileID   FlexString `json:"fileId"`
+	FileName string     `json:"fileName"`
+	IsFolder int        `json:"isFolder"`
+	DealWay  int        `json:"dealWay,omitempty"`
+}
+
+func (i batchTaskInfo) file() FileInfo {
+	return FileInfo{ID: i.FileID, FileName: i.FileName, IsFolder: i.IsFolder == 1}
+}
+
+type batchConflictResponse struct {
+	CodeResponse
+	TaskInfos []batchTaskInfo `json:"taskInfos,omitempty"`
+}
+
+type batchTaskCreateResponse struct {
+	CodeResponse
+	TaskID FlexString `json:"taskId,omitempty"`
+}
+
+// BatchTaskStatus 描述批量任务执行进度。
+type BatchTaskStatus struct {
+	CodeResponse
+	TaskID         FlexString `json:"taskId,omitempty"`
+	TaskStatus     int        `json:"taskStatus,omitempty"`
+	SubTaskCount   int        `json:"subTaskCount,omitempty"`
+	SuccessedCount int        `json:"successedCount,omitempty"`
+	FailedCount    int        `json:"failedCount,omitempty"`
+	SkipCount      int        `json:"skipCount,omitempty"`
+
+	SuccessedFileIDList []FlexString `json:"successedFileIdList,omitempty"`
+}
+
+// Done 判断任务是否执行完毕。
+func (s BatchTaskStatus) Done() bool {
+	return s.TaskStatus == batchTaskStatusDone
+}
+
+// Conflict 判断任务是否因同名冲突而挂起。
+func (s BatchTaskStatus) Conflict() bool {
+	return s.TaskStatus == batchTaskStatusConflict
+}
+
+// BatchTaskRequest 描述一次服务端批量任务。
+type BatchTaskRequest struct {
+	Type           string         // 任务类型，如 BatchTaskCopy
+	Files          []FileInfo     // 参与任务的文件，需包含 ID、文件名与是否文件夹
+	TargetFolderID string         // 目标目录（复制/移动时必填）
+	FamilyID       string         // 家庭云 ID，为空表示个人云
+	Policy         ConflictPolicy // 同名冲突处理策略
+}
+
+// BatchConflict 记录一次同名冲突及其处理方式。
+type BatchConflict struct {
+	File   FileInfo
+	Policy ConflictPolicy
+}
+
+// BatchResult 汇总批量任务的执行结果。
+type BatchResult struct {
+	TaskID    string
+	Status    BatchTaskStatus
+	Conflicts []BatchConflict
+}
+
+// Failed 判断是否存在执行失败的子任务。
+func (r *BatchResult) Failed() bool {
+	return r != nil && r.Status.FailedCount > 0
+}
+
+func newBatchTaskInfos(files []FileInfo) []batchTaskInfo {
+	infos := make([]batchTaskInfo, 0, len(files))
+	for _, f := range files {
+		info := batchTaskInfo{FileID: f.ID, FileName: f.FileName}
+		if f.IsFolder {
+			info.IsFolder = 1
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func (r BatchTaskRequest) params() map[string]string {
+	params := map[string]string{}
+	if r.TargetFolderID != "" {
+		params["targetFolderId"] = r.TargetFolderID
+	}
+	if r.FamilyID != "" {
+		params["familyId"] = r.FamilyID
+	}
+	return params
+}
+
+func (r BatchTaskRequest) validate() error {
+	if r.Type == "" {
+		return WrapCloudError(ErrCodeInvalidRequest, "任务类型不能为空", errors.New("cloud189: 批量任务类型为空"))
+	}
+	if (r.Type == BatchTaskCopy || r.Type == BatchTaskMove) && r.TargetFolderID == "" {
+		return WrapCloudError(ErrCodeInvalidRequest, "目标目录不能为空", errors.New("cloud189: targetFolderId 为空"))
+	}
+	return nil
+}
+
+// CreateBatchTask 提交批量任务，返回 taskId。
+func (c *Client) CreateBatchTask(ctx context.Context, req BatchTaskRequest) (string, error) {
+	if err := req.validate(); err != nil {
+		return "", err
+	}
+	return c.createBatchTask(ctx, req.Type, newBatchTaskInfos(req.Files), req.params())
+}
+
+// CheckBatchTask 查询一次批量任务状态。
+func (c *Client) CheckBatchTask(ctx context.Context, taskType, taskID string) (*BatchTaskStatus, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if taskType == "" || taskID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "参数缺失", errors.New("cloud189: taskType 或 taskId 为空"))
+	}
+	params := map[string]string{
+		"type":   taskType,
+		"taskId": taskID,
+	}
+	var status BatchTaskStatus
+	if err := c.AppPost(ctx, "/batch/checkBatchTask.action", params, &status); err != nil {
+		return nil, err
+	}
+	if status.TaskID == "" {
+		status.TaskID = FlexString(taskID)
+	}
+	return &status, nil
+}
+
+// RunBatchTask 提交批量任务并轮询至完成，冲突按 req.Policy 处理并记录在结果中。
+func (c *Client) RunBatchTask(ctx context.Context, req BatchTaskRequest) (*BatchResult, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	if len(req.Files) == 0 {
+		return &BatchResult{}, nil
+	}
+	taskID, status, conflicts, err := c.executeBatchTask(ctx, req.Type, newBatchTaskInfos(req.Files), req.params(), req.Policy)
+	if err != nil {
+		return nil, err
+	}
+	c.notifyBatchChange(req.Type, req.FamilyID, req.Files, req.TargetFolderID)
+	result := &BatchResult{TaskID: taskID, Status: *status}
+	for _, info := range conflicts {
+		result.Conflicts = append(result.Conflicts, BatchConflict{File: info.file(), Policy: req.Policy})
+	}
+	return result, nil
+}
+
+// BatchCopy 以单个批量任务复制文件到目标目录。
+func (c *Client) BatchCopy(ctx context.Context, files []FileInfo, destFolderID string, policy ConflictPolicy) (*BatchResult, error) {
+	return c.RunBatchTask(ctx, BatchTaskRequest{Type: BatchTaskCopy, Files: files, TargetFolderID: destFolderID, Policy: policy})
+}
+
+// BatchMove 以单个批量任务移动文件到目标目录。
+func (c *Client) BatchMove(ctx context.Context, files []FileInfo, destFolderID string, policy ConflictPolicy) (*BatchResult, error) {
+	return c.RunBatchTask(ctx, BatchTaskRequest{Type: BatchTaskMove, Files: files, TargetFolderID: destFolderID, Policy: policy})
+}
+
+// BatchDelete 以单个批量任务删除文件（移入回收站）。
+func (c *Client) BatchDelete(ctx context.Context, files []FileInfo) (*BatchResult, error) {
+	return c.RunBatchTask(ctx, BatchTaskRequest{Type: BatchTaskDelete, Files: files})
+}
+
+// fileBatch 以跳过策略执行删除/移动/复制批量任务，存在冲突或失败的子任务时返回错误。
+func (c *Client) fileBatch(ctx context.Context, familyID, taskType string, files []FileInfo, destFolderID string) error {
+	result, err := c.RunBatchTask(ctx, BatchTaskRequest{Type: taskType, Files: files, TargetFolderID: destFolderID, FamilyID: familyID, Policy: ConflictSkip})
+	if err != nil {
+		return err
+	}
+	if len(result.Conflicts) > 0 {
+		return WrapCloudError(ErrCodeInvalidRequest, "目标目录存在同名文件", errors.New("cloud189: 批量任务存在冲突"))
+	}
+	if result.Failed() {
+		return WrapCloudError(ErrCodeUnknown, "批量任务部分失败", fmt.Errorf("cloud189: %d 个子任务失败", result.Status.FailedCount))
+	}
+	return nil
+}
+
+// notifyBatchChange 将删除/移动/复制类批量任务转换为变更事件。
+func (c *Client) notifyBatchChange(taskType, familyID string, files []FileInfo, targetFolderID string) {
+	var op ChangeOp
+	switch taskType {
+	case BatchTaskDelete:
+		op = ChangeDelete
+	case BatchTaskMove:
+		op = ChangeMove
+	case BatchTaskCopy:
+		op = ChangeCopy
+	default:
+		return
+	}
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		ids = append(ids, f.ID.String())
+	}
+	c.notifyChange(ChangeEvent{Op: op, FamilyID: familyID, FileIDs: ids, ParentID: targetFolderID})
+}
+
+// executeBatchTask 提交批量任务并等待完成，遇到同名冲突时按策略处理后继续，
+// 返回 taskId、最终状态与发生冲突的文件（每个文件只记录一次）。
+func (c *Client) executeBatchTask(ctx context.Context, taskType string, infos []batchTaskInfo, params map[string]string, policy ConflictPolicy) (string, *BatchTaskStatus, []batchTaskInfo, error) {
+	taskID, err := c.createBatchTask(ctx, taskType, infos, params)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	var conflicts []batchTaskInfo
+	handled := make(map[string]bool)
+	for {
+		status, err := c.waitBatchTask(ctx, taskType, taskID)
+		if err != nil {
+			return taskID, nil, conflicts, err
+		}
+		if !status.Conflict() {
+			return taskID, status, conflicts, nil
+		}
+		pending, err := c.batchConflicts(ctx, taskType, taskID)
+		if err != nil {
+			return taskID, nil, conflicts, err
+		}
+		if len(pending) == 0 {
+			return taskID, nil, conflicts, WrapCloudError(ErrCodeUnknown, "批量任务冲突信息缺失", errors.New("cloud189: 冲突列表为空"))
+		}
+		// 已处理过的冲突再次出现说明处理未生效，直接报错以免无限循环。
+		progressed := false
+		for _, info := range pending {
+			if !handled[info.FileID.String()] {
+				handled[info.FileID.String()] = true
+				conflicts = append(conflicts, info)
+				progressed = true
+			}
+		}
+		if !progressed {
+			return taskID, nil, conflicts, WrapCloudError(ErrCodeUnknown, "批量任务冲突处理未生效", errors.New("cloud189: 冲突文件处理后仍被报告"))
+		}
+		if err := c.resolveBatchConflicts(ctx, taskType, taskID, params["targetFolderId"], pending, policy); err != nil {
+			return taskID, nil, conflicts, err
+		}
+	}
+}
+
+// createBatchTask 提交批量任务，params 携带 targetFolderId/familyId/shareId 等附加参数。
+func (c *Client) createBatchTask(ctx context.Context, taskType string, infos []batchTaskInfo, params map[string]string) (string, error) {
+	if c == nil {
+		return "", WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	taskInfos, err := json.Marshal(infos)
+	if err != nil {
+		return "", WrapCloudError(ErrCodeInvalidRequest, "编码任务信息失败", err)
+	}
+	form := map[string]string{
+		"type":      taskType,
+		"taskInfos": string(taskInfos),
+	}
+	for k, v := range params {
+		form[k] = v
+	}
+	var created batchTaskCreateResponse
+	if err := c.AppPost(ctx, "/batch/createBatchTask.action", form, &created); err != nil {
+		return "", err
+	}
+	if created.TaskID == "" {
+		return "", WrapCloudError(ErrCodeUnknown, "获取 taskId 失败", errors.New("cloud189: taskId 缺失"))
+	}
+	return created.TaskID.String(), nil
+}
+
+// waitBatchTask 轮询批量任务直到完成或出现冲突，遇到未知状态或等待超时时返回错误。
+func (c *Client) waitBatchTask(ctx context.Context, taskType, taskID string) (*BatchTaskStatus, error) {
+	interval := c.batchPollInterval
+	if interval <= 0 {
+		interval = DefaultBatchPollInterval
+	}
+	timeout := c.batchTimeout
+	if timeout <= 0 {
+		timeout = DefaultBatchTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := c.CheckBatchTask(ctx, taskType, taskID)
+		if err != nil {
+			return nil, err
+		}
+		switch status.TaskStatus {
+		case batchTaskStatusDone, batchTaskStatusConflict:
+			return status, nil
+		case 0, batchTaskStatusPending, batchTaskStatusRunning:
+		default:
+			return nil, WrapCloudError(ErrCodeServer, "批量任务状态异常", fmt.Errorf("cloud189: 任务 %s 返回未知状态 %d", taskID, status.TaskStatus))
+		}
+		if !time.Now().Before(deadline) {
+			return nil, WrapCloudError(ErrCodeServer, "等待批量任务超时", fmt.Errorf("cloud189: 任务 %s 超过 %s 未完成", taskID, timeout))
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, WrapCloudError(ErrCodeUnknown, "等待批量任务被取消", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// batchConflicts 获取批量任务中与目标目录同名的文件。
+func (c *Client) batchConflicts(ctx context.Context, taskType, taskID string) ([]batchTaskInfo, error) {
+	params := map[string]string{
+		"type":   taskType,
+		"taskId": taskID,
+	}
+	var rsp batchConflictResponse
+	if err := c.AppPost(ctx, "/batch/getConflictTaskInfo.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp.TaskInfos, nil
+}
+
+// resolveBatchConflicts 按策略处理冲突文件，使任务继续执行。
+func (c *Client) resolveBatchConflicts(ctx context.Context, taskType, taskID, targetFolderID string, infos []batchTaskInfo, policy ConflictPolicy) error {
+	resolved := make([]batchTaskInfo, len(infos))
+	for i, info := range infos {
+		info.DealWay = policy.dealWay()
+		resolved[i] = info
+	}
+	taskInfos, err := json.Marshal(resolved)
+	if err != nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "编码任务信息失败", err)
+	}
+	params := map[string]string{
+		"type":           taskType,
+		"taskId":         taskID,
+		"targetFolderId": targetFolderID,
+		"taskInfos":      string(taskInfos),
+	}
+	var rsp CodeResponse
+	return c.AppPost(ctx, "/batch/manageBatchTask.action", params, &rsp)
+}
@@ -0,0 +1,289 @@
+package cloud189
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/auth"
+)
+
+// memorySessionStore 内存实现的 SessionStore，便于测试。
+type memorySessionStore struct {
+	mu      sync.Mutex
+	session *auth.Session
+}
+
+func (s *memorySessionStore) SaveSession(session *auth.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = session.Clone()
+	return nil
+}
+
+func (s *memorySessionStore) LoadSession() (*auth.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
+	return s.session.Clone(), nil
+}
+
+func (s *memorySessionStore) ClearSession() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = nil
+	return nil
+}
+
+// newTestClient 创建指向本地假服务的客户端。
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	store := &memorySessionStore{}
+	_ = store.SaveSession(&auth.Session{
+		SessionKey:      "test-session-key",
+		SessionSecret:   "0123456789abcdef0123",
+		SSON:            "sson",
+		CookieLoginUser: "user",
+	})
+	manager := auth.NewAuthManager()
+	if err := manager.AddAccount("test", auth.AccountSession{Store: store}); err != nil {
+		t.Fatalf("添加账号失败: %v", err)
+	}
+	opts = append([]Option{
+		WithBaseURLs(srv.URL, srv.URL, srv.URL),
+		WithBatchPollInterval(time.Millisecond),
+	}, opts...)
+	return NewClient(manager, opts...).WithAccount("test")
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// TestRunBatchTask_ResolvesConflicts 冲突应按策略提交处理并记录在结果中。
+func TestRunBatchTask_ResolvesConflicts(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		resolved bool
+		dealWay  int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("type") != BatchTaskMove || r.Form.Get("targetFolderId") != "dest" {
+			t.Errorf("创建任务参数异常: %v", r.Form)
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "task-1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !resolved {
+			writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 2})
+			return
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 4, "successedCount": 2})
+	})
+	mux.HandleFunc("/batch/getConflictTaskInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"res_code":  0,
+			"taskInfos": []map[string]any{{"fileId": "2", "fileName": "b.txt", "isFolder": 0}},
+		})
+	})
+	mux.HandleFunc("/batch/manageBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		var infos []batchTaskInfo
+		if err := json.NewDecoder(strings.NewReader(r.Form.Get("taskInfos"))).Decode(&infos); err != nil || len(infos) != 1 {
+			t.Errorf("冲突处理参数异常: %v", r.Form.Get("taskInfos"))
+		}
+		mu.Lock()
+		resolved = true
+		if len(infos) > 0 {
+			dealWay = infos[0].DealWay
+		}
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+
+	client := newTestClient(t, mux)
+	files := []FileInfo{{ID: "1", FileName: "a.txt"}, {ID: "2", FileName: "b.txt"}}
+	result, err := client.BatchMove(context.Background(), files, "dest", ConflictOverwrite)
+	if err != nil {
+		t.Fatalf("批量移动失败: %v", err)
+	}
+	if result.TaskID != "task-1" || !result.Status.Done() {
+		t.Fatalf("任务结果异常: %+v", result)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].File.ID != "2" || result.Conflicts[0].Policy != ConflictOverwrite {
+		t.Fatalf("冲突记录异常: %+v", result.Conflicts)
+	}
+	if dealWay != 3 {
+		t.Fatalf("覆盖策略 dealWay 应为 3，实际 %d", dealWay)
+	}
+}
+
+// TestRunBatchTask_RequiresTarget 复制/移动缺少目标目录时应直接报错。
+func TestRunBatchTask_RequiresTarget(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler())
+	_, err := client.BatchCopy(context.Background(), []FileInfo{{ID: "1"}}, "", ConflictSkip)
+	ce, ok := err.(*CloudError)
+	if !ok || ce.Code != ErrCodeInvalidRequest {
+		t.Fatalf("期望参数错误，实际: %v", err)
+	}
+}
+
+// TestRunBatchTask_StopsOnPersistentConflict 处理后仍报告相同冲突时应报错退出而不是无限轮询。
+func TestRunBatchTask_StopsOnPersistentConflict(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		manages int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "task-1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 2})
+	})
+	mux.HandleFunc("/batch/getConflictTaskInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskInfos": []map[string]any{{"fileId": "1", "fileName": "a.txt", "isFolder": 0}}})
+	})
+	mux.HandleFunc("/batch/manageBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		manages++
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+
+	client := newTestClient(t, mux)
+	_, err := client.BatchCopy(context.Background(), []FileInfo{{ID: "1", FileName: "a.txt"}}, "dest", ConflictRename)
+	if err == nil {
+		t.Fatalf("冲突处理未生效时应返回错误")
+	}
+	if manages != 1 {
+		t.Fatalf("同一冲突只应处理一次，实际 %d 次", manages)
+	}
+}
+
+// TestRunBatchTask_StopsOnUnknownStatus 未知或负数状态立即报错，执行中的任务超过等待时限后报错。
+func TestRunBatchTask_StopsOnUnknownStatus(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		status int
+		checks int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "task-1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		checks++
+		writeJSON(w, map[string]any{"res_code": 0, "taskStatus": status})
+	})
+	client := newTestClient(t, mux, WithBatchTimeout(20*time.Millisecond))
+	run := func(s int) (int, error) {
+		mu.Lock()
+		status, checks = s, 0
+		mu.Unlock()
+		_, err := client.BatchDelete(context.Background(), []FileInfo{{ID: "1", FileName: "a.txt"}})
+		mu.Lock()
+		defer mu.Unlock()
+		return checks, err
+	}
+
+	for _, s := range []int{-1, 5} {
+		n, err := run(s)
+		var ce *CloudError
+		if !errors.As(err, &ce) || ce.Code != ErrCodeServer || n != 1 {
+			t.Fatalf("状态 %d 应立即报错: %v（轮询 %d 次）", s, err, n)
+		}
+	}
+	n, err := run(batchTaskStatusRunning)
+	if err == nil || !strings.Contains(err.Error(), "超时") || n < 2 {
+		t.Fatalf("执行中的任务超时后应报错: %v（轮询 %d 次）", err, n)
+	}
+}
+
+// TestFileBatch_UsesBatchTasks 复制/移动/删除各以单个批量任务提交全部文件，冲突与失败返回错误。
+func TestFileBatch_UsesBatchTasks(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		tasks    []string
+		resolved bool
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		tasks = append(tasks, r.Form.Get("type")+":"+r.Form.Get("targetFolderId")+":"+r.Form.Get("taskInfos"))
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": r.Form.Get("type") + "-" + r.Form.Get("targetFolderId")})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.Form.Get("taskId") {
+		case BatchTaskMove + "-conflict":
+			mu.Lock()
+			defer mu.Unlock()
+			if !resolved {
+				writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 2})
+				return
+			}
+			writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 4, "skipCount": 1})
+		case BatchTaskCopy + "-failed":
+			writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 4, "failedCount": 1})
+		default:
+			writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 4})
+		}
+	})
+	mux.HandleFunc("/batch/getConflictTaskInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskInfos": []map[string]any{{"fileId": "1", "fileName": "a.txt", "isFolder": 0}}})
+	})
+	mux.HandleFunc("/batch/manageBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		resolved = true
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+	files := []FileInfo{{ID: "1", FileName: "a.txt"}, {ID: "2", FileName: "dir", IsFolder: true}}
+	const infos = `[{"fileId":"1","fileName":"a.txt","isFolder":0},{"fileId":"2","fileName":"dir","isFolder":1}]`
+
+	if err := client.CopyFiles(ctx, files, "10"); err != nil {
+		t.Fatalf("复制失败: %v", err)
+	}
+	if err := client.MoveFiles(ctx, files, "11"); err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	if err := client.DeleteFiles(ctx, files); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	want := []string{BatchTaskCopy + ":10:" + infos, BatchTaskMove + ":11:" + infos, BatchTaskDelete + "::" + infos}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Fatalf("批量任务异常:\n得到 %q\n期望 %q", tasks, want)
+	}
+
+	if err := client.MoveFiles(ctx, files, "conflict"); err == nil {
+		t.Fatalf("存在同名冲突时应返回错误")
+	}
+	if err := client.CopyFiles(ctx, files, "failed"); err == nil {
+		t.Fatalf("存在失败子任务时应返回错误")
+	}
+}
@@ -0,0 +1,225 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"path"
+)
+
+// familyOrderBy 将个人云排序字段转换为家庭云的数字编码。
+func familyOrderBy(orderBy string) string {
+	switch orderBy {
+	case "filename":
+		return "1"
+	case "filesize":
+		return "2"
+	case "lastOpTime":
+		return "3"
+	default:
+		return orderBy
+	}
+}
+
+// ListFamilies 列出当前账号加入的家庭云。
+func (c *Client) ListFamilies(ctx context.Context) ([]FamilyInfo, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	var rsp FamilyListResponse
+	if err := c.AppGet(ctx, "/family/manage/getFamilyList.action", nil, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp.Families, nil
+}
+
+// ListFamilyFiles 列出家庭云指定文件夹内容，folderID 为空表示家庭云根目录。
+func (c *Client) ListFamilyFiles(ctx context.Context, familyID, folderID string, opts ...ListOption) (*FileListResponse, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if familyID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "familyID 不能为空", errors.New("cloud189: familyID 为空"))
+	}
+	params := map[string]string{
+		"familyId":   familyID,
+		"fileType":   "0",
+		"mediaType":  "0",
+		"mediaAttr":  "0",
+		"iconOption": "0",
+		"orderBy":    "filename",
+		"descending": "true",
+		"pageNum":    "1",
+		"pageSize":   "100",
+	}
+	if folderID != "" {
+		params["folderId"] = folderID
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	params["orderBy"] = familyOrderBy(params["orderBy"])
+	var rsp FileListResponse
+	if err := c.AppGet(ctx, "/family/file/listFiles.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// SearchFamilyFiles 在家庭云中搜索文件或文件夹。
+func (c *Client) SearchFamilyFiles(ctx context.Context, familyID, keyword string, opts ...SearchOption) (*SearchResponse, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if familyID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "familyID 不能为空", errors.New("cloud189: familyID 为空"))
+	}
+	params := map[string]string{
+		"familyId":   familyID,
+		"filename":   keyword,
+		"fileType":   "0",
+		"mediaType":  "0",
+		"mediaAttr":  "0",
+		"recursive":  "0",
+		"iconOption": "0",
+		"orderBy":    "filename",
+		"descending": "true",
+		"pageNum":    "1",
+		"pageSize":   "100",
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	params["orderBy"] = familyOrderBy(params["orderBy"])
+	var rsp SearchResponse
+	if err := c.AppGet(ctx, "/family/file/searchFiles.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// CreateFamilyFolder 在家庭云中创建文件夹，parentID 为空表示家庭云根目录。
+func (c *Client) CreateFamilyFolder(ctx context.Context, familyID, parentID, name string) (*FileInfo, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if familyID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "familyID 不能为空", errors.New("cloud189: familyID 为空"))
+	}
+	dir, base := path.Split(name)
+	if base == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "文件夹名不能为空", errors.New("cloud189: 文件夹名不能为空"))
+	}
+	params := map[string]string{
+		"familyId":     familyID,
+		"folderName":   base,
+		"relativePath": dir,
+	}
+	if parentID != "" {
+		params["parentId"] = parentID
+	}
+	var rsp struct {
+		CodeResponse
+		FileInfo
+	}
+	if err := c.AppPost(ctx, "/family/file/createFolder.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	rsp.FileInfo.IsFolder = true
+	c.notifyChange(ChangeEvent{Op: ChangeCreate, FamilyID: familyID, FileIDs: []string{rsp.ID.String()}, ParentID: parentID, Name: name})
+	return &rsp.FileInfo, nil
+}
+
+// DeleteFamilyFiles 通过批量任务删除家庭云文件或文件夹。
+func (c *Client) DeleteFamilyFiles(ctx context.Context, familyID string, files []FileInfo) error {
+	return c.familyBatch(ctx, familyID, BatchTaskDelete, files, "")
+}
+
+// MoveFamilyFiles 通过批量任务移动家庭云文件到目标目录。
+func (c *Client) MoveFamilyFiles(ctx context.Context, familyID string, files []FileInfo, destFolderID string) error {
+	return c.familyBatch(ctx, familyID, BatchTaskMove, files, destFolderID)
+}
+
+// CopyFamilyFiles 通过批量任务复制家庭云文件到目标目录。
+func (c *Client) CopyFamilyFiles(ctx context.Context, familyID string, files []FileInfo, destFolderID string) error {
+	return c.familyBatch(ctx, familyID, BatchTaskCopy, files, destFolderID)
+}
+
+func (c *Client) familyBatch(ctx context.Context, familyID, taskType string, files []FileInfo, destFolderID string) error {
+	if c == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if familyID == "" {
+		return WrapCloudError(ErrCodeInvalidRequest, "familyID 不能为空", errors.New("cloud189: familyID 为空"))
+	}
+	return c.fileBatch(ctx, familyID, taskType, files, destFolderID)
+}
+
+// RenameFamilyFile 重命名家庭云文件。
+func (c *Client) RenameFamilyFile(ctx context.Context, familyID, fileID, newName string) error {
+	if c == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if familyID == "" || fileID == "" || newName == "" {
+		return WrapCloudError(ErrCodeInvalidRequest, "参数缺失", errors.New("cloud189: familyID、fileID 或 newName 为空"))
+	}
+	params := map[string]string{
+		"familyId":     familyID,
+		"fileId":       fileID,
+		"destFileName": newName,
+	}
+	var rsp CodeResponse
+	if err := c.AppPost(ctx, "/family/file/renameFile.action", params, &rsp); err != nil {
+		return err
+	}
+	c.notifyChange(ChangeEvent{Op: ChangeRename, FamilyID: familyID, FileIDs: []string{fileID}, Name: newName})
+	return nil
+}
+
+// GetFamilyFileInfo 获取家庭云文件信息。
+func (c *Client) GetFamilyFileInfo(ctx context.Context, familyID, fileID string) (*FileInfo, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if familyID == "" || fileID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "参数缺失", errors.New("cloud189: familyID 或 fileID 为空"))
+	}
+	params := map[string]string{
+		"familyId":   familyID,
+		"fileId":     fileID,
+		"iconOption": "0",
+	}
+	var rsp struct {
+		CodeResponse
+		FileInfo
+	}
+	if err := c.AppGet(ctx, "/family/file/getFileInfo.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp.FileInfo, nil
+}
+
+// GetFamilyDownloadURL 获取家庭云文件下载链接。
+func (c *Client) GetFamilyDownloadURL(ctx context.Context, familyID, fileID string) (string, error) {
+	if c == nil {
+		return "", WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if familyID == "" || fileID == "" {
+		return "", WrapCloudError(ErrCodeInvalidRequest, "参数缺失", errors.New("cloud189: familyID 或 fileID 为空"))
+	}
+	params := map[string]string{
+		"familyId": familyID,
+		"fileId":   fileID,
+	}
+	var rsp struct {
+		CodeResponse
+		FileDownloadURL string `json:"fileDownloadUrl,omitempty"`
+	}
+	if err := c.AppGet(ctx, "/family/file/getFileDownloadUrl.action", params, &rsp); err != nil {
+		return "", err
+	}
+	return rsp.FileDownloadURL, nil
+}
@@ -0,0 +1,121 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// TestFamilyAPIs_Requests 家庭云接口应携带 familyId、走 /family 前缀并转换排序字段。
+func TestFamilyAPIs_Requests(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches []string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/family/manage/getFamilyList.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "familyInfoResp": []map[string]any{{"familyId": 88, "remarkName": "家"}}})
+	})
+	mux.HandleFunc("/family/file/listFiles.action", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("familyId") != "88" || q.Get("folderId") != "7" || q.Get("orderBy") != "3" {
+			t.Errorf("列表参数异常: %v", q)
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "fileListAO": map[string]any{
+			"count":    1,
+			"fileList": []map[string]any{{"id": "9", "name": "a.txt", "size": 3}},
+		}})
+	})
+	mux.HandleFunc("/family/file/createFolder.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("familyId") != "88" || r.Form.Get("folderName") != "new" || r.Form.Get("parentId") != "7" {
+			t.Errorf("建目录参数异常: %v", r.Form)
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "id": "10", "name": "new"})
+	})
+	mux.HandleFunc("/family/file/getFileDownloadUrl.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "fileDownloadUrl": "https://dl/" + r.URL.Query().Get("fileId")})
+	})
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("familyId") != "88" {
+			t.Errorf("批量任务缺少 familyId: %v", r.Form)
+		}
+		mu.Lock()
+		batches = append(batches, r.Form.Get("type")+":"+r.Form.Get("targetFolderId"))
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "t1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 4})
+	})
+	mux.HandleFunc("/family/initMultiUpload", func(w http.ResponseWriter, r *http.Request) {
+		params := decodeUploadParams(t, r)
+		if params.Get("familyId") != "88" || params.Get("parentFolderId") != "7" {
+			t.Errorf("家庭云上传参数异常: %v", params)
+		}
+		writeJSON(w, map[string]any{"code": "SUCCESS", "data": map[string]any{"uploadFileId": "up-1"}})
+	})
+	mux.HandleFunc("/family/commitMultiUploadFile", func(w http.ResponseWriter, r *http.Request) {
+		if decodeUploadParams(t, r).Get("familyId") != "88" {
+			t.Errorf("提交上传缺少 familyId")
+		}
+		writeJSON(w, map[string]any{"code": "SUCCESS", "file": map[string]any{"userFileId": "11", "file_name": "up.txt"}})
+	})
+
+	client := newTestClient(t, mux)
+	var events []ChangeEvent
+	client.SubscribeChanges(func(evt ChangeEvent) { events = append(events, evt) })
+	ctx := context.Background()
+
+	families, err := client.ListFamilies(ctx)
+	if err != nil || len(families) != 1 || families[0].FamilyID != "88" {
+		t.Fatalf("家庭云列表异常: %+v %v", families, err)
+	}
+	list, err := client.ListFamilyFiles(ctx, "88", "7", WithListOrder("lastOpTime", false))
+	if err != nil || len(list.FileListAO.Files) != 1 {
+		t.Fatalf("家庭云文件列表异常: %+v %v", list, err)
+	}
+	if _, err := client.CreateFamilyFolder(ctx, "88", "7", "new"); err != nil {
+		t.Fatalf("创建家庭云目录失败: %v", err)
+	}
+	if u, err := client.GetFamilyDownloadURL(ctx, "88", "9"); err != nil || u != "https://dl/9" {
+		t.Fatalf("下载链接异常: %q %v", u, err)
+	}
+	files := []FileInfo{{ID: "9", FileName: "a.txt"}}
+	if err := client.MoveFamilyFiles(ctx, "88", files, "10"); err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	if err := client.DeleteFamilyFiles(ctx, "88", files); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if len(batches) != 2 || batches[0] != BatchTaskMove+":10" || batches[1] != BatchTaskDelete+":" {
+		t.Fatalf("批量任务异常: %v", batches)
+	}
+
+	session, err := client.InitFamilyUpload(ctx, "88", "7", "up.txt", 0)
+	if err != nil {
+		t.Fatalf("初始化家庭云上传失败: %v", err)
+	}
+	session.FileMD5, session.SliceMD5 = "d41d8cd98f00b204e9800998ecf8427e", "d41d8cd98f00b204e9800998ecf8427e"
+	if info, err := client.CommitUpload(ctx, session); err != nil || info.ID != "11" {
+		t.Fatalf("提交家庭云上传失败: %+v %v", info, err)
+	}
+
+	for _, evt := range events {
+		if evt.FamilyID != "88" {
+			t.Fatalf("家庭云变更事件应携带 familyId: %+v", evt)
+		}
+	}
+	if len(events) != 4 {
+		t.Fatalf("应产生 4 个变更事件，实际 %d", len(events))
+	}
+
+	if _, err := client.ListFamilyFiles(ctx, "", "7"); err == nil {
+		t.Fatalf("缺少 familyId 应返回错误")
+	}
+	if _, err := client.InitFamilyUpload(ctx, "", "7", "x", 1); err == nil {
+		t.Fatalf("缺少 familyId 应返回错误")
+	}
+}
@@ -41,6 +41,13 @@ func WithListOrder(orderBy string, descending bool) ListOption {
 	}
 }
 
+// WithListIcons 在列表结果中附带缩略图地址。
+func WithListIcons() ListOption {
+	return func(params map[string]string) {
+		params["iconOption"] = iconOptionAll
+	}
+}
+
 // WithListMedia 设置媒体过滤参数。
 func WithListMedia(mediaType, mediaAttr string) ListOption {
 	return func(params map[string]string) {
@@ -196,60 +203,25 @@ func (c *Client) CreateFolder(ctx context.Context, parentID, name string) (*File
 	if err := c.AppPost(ctx, "/createFolder.action", params, &rsp); err != nil {
 		return nil, err
 	}
+	c.notifyChange(ChangeEvent{Op: ChangeCreate, FileIDs: []string{rsp.ID.String()}, ParentID: parentID, Name: name})
 	return &rsp.FileInfo, nil
 }
 
-// DeleteFiles 批量删除文件或文件夹。
-func (c *Client) DeleteFiles(ctx context.Context, fileIDs []string) error {
-	if c == nil {
-		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
-	}
-	if len(fileIDs) == 0 {
-		return nil
-	}
-	params := map[string]string{
-		"fileIdList": joinIDs(fileIDs),
-	}
-	var rsp CodeResponse
-	return c.AppPost(ctx, "/batchDeleteFile.action", params, &rsp)
+// DeleteFiles 通过批量任务删除文件或文件夹（移入回收站）。
+func (c *Client) DeleteFiles(ctx context.Context, files []FileInfo) error {
+	return c.fileBatch(ctx, "", BatchTaskDelete, files, "")
 }
 
-// CopyFiles 复制文件到目标目录。
-func (c *Client) CopyFiles(ctx context.Context, fileIDs []string, destFolderID string) error {
-	if c == nil {
-		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
-	}
-	if len(fileIDs) == 0 {
-		return nil
-	}
-	for _, id := range fileIDs {
-		params := map[string]string{
-			"fileId":             id,
-			"destParentFolderId": destFolderID,
-			"destFileName":       "",
-		}
-		var rsp CodeResponse
-		if err := c.AppPost(ctx, "/copyFile.action", params, &rsp); err != nil {
-			return err
-		}
-	}
-	return nil
+// CopyFiles 通过批量任务复制文件到目标目录。目标目录存在同名文件时跳过并返回错误，
+// 需要其他冲突策略时使用 BatchCopy。
+func (c *Client) CopyFiles(ctx context.Context, files []FileInfo, destFolderID string) error {
+	return c.fileBatch(ctx, "", BatchTaskCopy, files, destFolderID)
 }
 
-// MoveFiles 移动文件到目标目录。
-func (c *Client) MoveFiles(ctx context.Context, fileIDs []string, destFolderID string) error {
-	if c == nil {
-		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
-	}
-	if len(fileIDs) == 0 {
-		return nil
-	}
-	params := map[string]string{
-		"fileIdList":         joinIDs(fileIDs),
-		"destParentFolderId": destFolderID,
-	}
-	var rsp CodeResponse
-	return c.AppPost(ctx, "/batchMoveFile.action", params, &rsp)
+// MoveFiles 通过批量任务移动文件到目标目录。目标目录存在同名文件时跳过并返回错误，
+// 需要其他冲突策略时使用 BatchMove。
+func (c *Client) MoveFiles(ctx context.Context, files []FileInfo, destFolderID string) error {
+	return c.fileBatch(ctx, "", BatchTaskMove, files, destFolderID)
 }
 
 // RenameFile 重命名文件。
@@ -265,7 +237,11 @@ func (c *Client) RenameFile(ctx context.Context, fileID, newName string) error {
 		"destFileName": newName,
 	}
 	var rsp CodeResponse
-	return c.AppPost(ctx, "/renameFile.action", params, &rsp)
+	if err := c.AppPost(ctx, "/renameFile.action", params, &rsp); err != nil {
+		return err
+	}
+	c.notifyChange(ChangeEvent{Op: ChangeRename, FileIDs: []string{fileID}, Name: newName})
+	return nil
 }
 
 // GetFileInfo 获取文件信息。
@@ -276,11 +252,15 @@ func (c *Client) GetFileInfo(ctx context.Context, fileID string) (*FileInfo, err
 	if fileID == "" {
 		return nil, WrapCloudError(ErrCodeInvalidRequest, "fileID 不能为空", errors.New("cloud189: fileID 为空"))
 	}
+	return c.getFileInfo(ctx, fileID, "0")
+}
+
+func (c *Client) getFileInfo(ctx context.Context, fileID, iconOption string) (*FileInfo, error) {
 	params := map[string]string{
 		"fileId":     fileID,
 		"filePath":   "",
 		"pathList":   "1",
-		"iconOption": "0",
+		"iconOption": iconOption,
 	}
 	var rsp struct {
 		CodeResponse
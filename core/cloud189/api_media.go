@@ -0,0 +1,125 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// ThumbnailSize 缩略图尺寸。
+type ThumbnailSize int
+
+const (
+	// ThumbnailSmall 小图，适合列表图标。
+	ThumbnailSmall ThumbnailSize = iota
+	// ThumbnailMedium 中图，适合网格相册。
+	ThumbnailMedium
+	// ThumbnailLarge 大图，适合预览。
+	ThumbnailLarge
+)
+
+// String 返回尺寸名称。
+func (s ThumbnailSize) String() string {
+	switch s {
+	case ThumbnailSmall:
+		return "small"
+	case ThumbnailMedium:
+		return "medium"
+	case ThumbnailLarge:
+		return "large"
+	default:
+		return "unknown"
+	}
+}
+
+// ThumbnailURL 按尺寸从缩略图集合中取地址，缺失时依次回退到相邻尺寸。
+func ThumbnailURL(thumbs model.Thumbnails, size ThumbnailSize) string {
+	var order []string
+	switch size {
+	case ThumbnailSmall:
+		order = []string{thumbs.Small, thumbs.Medium, thumbs.Large}
+	case ThumbnailLarge:
+		order = []string{thumbs.Large, thumbs.Medium, thumbs.Small}
+	default:
+		order = []string{thumbs.Medium, thumbs.Large, thumbs.Small}
+	}
+	for _, u := range order {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// VideoStreamType 视频播放地址类型。
+type VideoStreamType int
+
+const (
+	// VideoStreamDirect 原始文件直链，支持 Range 拖动。
+	VideoStreamDirect VideoStreamType = iota
+	// VideoStreamHLS 服务端转码后的 HLS（m3u8）播放列表。
+	VideoStreamHLS
+)
+
+func (t VideoStreamType) param() string {
+	if t == VideoStreamHLS {
+		return "2"
+	}
+	return "1"
+}
+
+// GetThumbnails 获取文件全部尺寸的缩略图地址。
+func (c *Client) GetThumbnails(ctx context.Context, fileID string) (model.Thumbnails, error) {
+	if c == nil {
+		return model.Thumbnails{}, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if fileID == "" {
+		return model.Thumbnails{}, WrapCloudError(ErrCodeInvalidRequest, "fileID 不能为空", errors.New("cloud189: fileID 为空"))
+	}
+	info, err := c.getFileInfo(ctx, fileID, iconOptionAll)
+	if err != nil {
+		return model.Thumbnails{}, err
+	}
+	return info.ToModel().Thumbnails, nil
+}
+
+// GetThumbnailURL 获取指定尺寸的缩略图地址，文件无缩略图时返回 ErrCodeFileNotFound。
+func (c *Client) GetThumbnailURL(ctx context.Context, fileID string, size ThumbnailSize) (string, error) {
+	thumbs, err := c.GetThumbnails(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+	u := ThumbnailURL(thumbs, size)
+	if u == "" {
+		return "", NewCloudError(ErrCodeFileNotFound, "文件没有缩略图")
+	}
+	return u, nil
+}
+
+// GetVideoPlayURL 获取视频在线播放地址，HLS 需服务端转码完成后才可用。
+func (c *Client) GetVideoPlayURL(ctx context.Context, fileID string, streamType VideoStreamType) (string, error) {
+	if c == nil {
+		return "", WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if fileID == "" {
+		return "", WrapCloudError(ErrCodeInvalidRequest, "fileID 不能为空", errors.New("cloud189: fileID 为空"))
+	}
+	params := map[string]string{
+		"fileId": fileID,
+		"type":   streamType.param(),
+	}
+	var rsp struct {
+		CodeResponse
+		Normal struct {
+			URL string `json:"url,omitempty"`
+		} `json:"normal,omitempty"`
+	}
+	if err := c.AppGet(ctx, "/getNewVlcVideoPlayUrl.action", params, &rsp); err != nil {
+		return "", err
+	}
+	if rsp.Normal.URL == "" {
+		return "", NewCloudError(ErrCodeFileNotFound, "视频播放地址不可用")
+	}
+	return rsp.Normal.URL, nil
+}
@@ -0,0 +1,173 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// OfflineTaskStatus 离线下载任务状态。
+type OfflineTaskStatus int
+
+const (
+	// OfflineStatusPending 排队等待服务端处理。
+	OfflineStatusPending OfflineTaskStatus = iota
+	// OfflineStatusDownloading 服务端下载中。
+	OfflineStatusDownloading
+	// OfflineStatusCompleted 已下载完成并保存到目标目录。
+	OfflineStatusCompleted
+	// OfflineStatusFailed 下载失败。
+	OfflineStatusFailed
+	// OfflineStatusCanceled 已取消。
+	OfflineStatusCanceled
+)
+
+// String 返回状态名称。
+func (s OfflineTaskStatus) String() string {
+	switch s {
+	case OfflineStatusPending:
+		return "pending"
+	case OfflineStatusDownloading:
+		return "downloading"
+	case OfflineStatusCompleted:
+		return "completed"
+	case OfflineStatusFailed:
+		return "failed"
+	case OfflineStatusCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// Finished 判断任务是否已进入终态。
+func (s OfflineTaskStatus) Finished() bool {
+	return s == OfflineStatusCompleted || s == OfflineStatusFailed || s == OfflineStatusCanceled
+}
+
+// ValidateOfflineURL 校验离线下载链接，支持 http(s)、magnet 与 ed2k。
+func ValidateOfflineURL(raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return WrapCloudError(ErrCodeInvalidRequest, "下载链接不能为空", errors.New("cloud189: 离线链接为空"))
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "下载链接格式错误", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host != "" {
+			return nil
+		}
+	case "magnet":
+		if strings.Contains(strings.ToLower(u.RawQuery), "xt=urn:btih:") {
+			return nil
+		}
+	case "ed2k":
+		if strings.HasPrefix(strings.ToLower(u.Opaque), "//|file|") {
+			return nil
+		}
+	}
+	return WrapCloudError(ErrCodeInvalidRequest, "不支持的下载链接", errors.New("cloud189: 不支持的离线链接 "+raw))
+}
+
+// AddOfflineTask 提交离线下载任务，文件下载完成后保存到 folderID，返回任务 ID。
+func (c *Client) AddOfflineTask(ctx context.Context, rawURL, folderID string) (string, error) {
+	if c == nil {
+		return "", WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if err := ValidateOfflineURL(rawURL); err != nil {
+		return "", err
+	}
+	if folderID == "" {
+		return "", WrapCloudError(ErrCodeInvalidRequest, "folderID 不能为空", errors.New("cloud189: folderID 为空"))
+	}
+	params := map[string]string{
+		"url":      strings.TrimSpace(rawURL),
+		"folderId": folderID,
+	}
+	var rsp struct {
+		CodeResponse
+		TaskID FlexString `json:"taskId,omitempty"`
+	}
+	if err := c.AppPost(ctx, "/offline/createTask.action", params, &rsp); err != nil {
+		return "", err
+	}
+	if rsp.TaskID == "" {
+		return "", NewCloudError(ErrCodeServer, "离线任务创建失败：未返回任务 ID")
+	}
+	return rsp.TaskID.String(), nil
+}
+
+// ListOfflineTasks 分页列出离线下载任务，返回任务列表与总数。
+func (c *Client) ListOfflineTasks(ctx context.Context, opts ...ListOption) ([]OfflineTask, int, error) {
+	if c == nil {
+		return nil, 0, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	params := map[string]string{
+		"pageNum":  "1",
+		"pageSize": "100",
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	var rsp OfflineTaskListResponse
+	if err := c.AppGet(ctx, "/offline/listTasks.action", params, &rsp); err != nil {
+		return nil, 0, err
+	}
+	return rsp.Tasks, rsp.Count, nil
+}
+
+// GetOfflineTask 查询单个离线下载任务。
+func (c *Client) GetOfflineTask(ctx context.Context, taskID string) (*OfflineTask, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if taskID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "taskID 不能为空", errors.New("cloud189: taskID 为空"))
+	}
+	params := map[string]string{"taskId": taskID}
+	var rsp struct {
+		CodeResponse
+		OfflineTask
+	}
+	if err := c.AppGet(ctx, "/offline/getTaskInfo.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp.OfflineTask, nil
+}
+
+// CancelOfflineTasks 取消仍在进行中的离线下载任务。
+func (c *Client) CancelOfflineTasks(ctx context.Context, taskIDs []string) error {
+	if c == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if len(taskIDs) == 0 {
+		return WrapCloudError(ErrCodeInvalidRequest, "taskIDs 不能为空", errors.New("cloud189: taskIDs 为空"))
+	}
+	params := map[string]string{"taskIds": joinIDs(taskIDs)}
+	var rsp CodeResponse
+	return c.AppPost(ctx, "/offline/cancelTask.action", params, &rsp)
+}
+
+// DeleteOfflineTasks 删除离线下载任务记录，deleteFiles 为 true 时同时删除已下载的文件。
+func (c *Client) DeleteOfflineTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error {
+	if c == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if len(taskIDs) == 0 {
+		return WrapCloudError(ErrCodeInvalidRequest, "taskIDs 不能为空", errors.New("cloud189: taskIDs 为空"))
+	}
+	params := map[string]string{"taskIds": joinIDs(taskIDs)}
+	if deleteFiles {
+		params["deleteFile"] = "1"
+	} else {
+		params["deleteFile"] = "0"
+	}
+	var rsp CodeResponse
+	return c.AppPost(ctx, "/offline/deleteTask.action", params, &rsp)
+}
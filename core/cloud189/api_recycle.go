@@ -0,0 +1,112 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// ListRecycleBin 分页列出回收站内容，返回文件列表与回收站总数。
+// model.File.ParentPath 记录文件删除前所在的路径。
+func (c *Client) ListRecycleBin(ctx context.Context, opts ...ListOption) ([]model.File, int, error) {
+	if c == nil {
+		return nil, 0, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	params := map[string]string{
+		"iconOption": "0",
+		"orderBy":    "lastOpTime",
+		"descending": "true",
+		"pageNum":    "1",
+		"pageSize":   "100",
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	var rsp RecycleBinResponse
+	if err := c.AppGet(ctx, "/listRecycleBinFiles.action", params, &rsp); err != nil {
+		return nil, 0, err
+	}
+	items := rsp.Items()
+	files := make([]model.File, 0, len(items))
+	for _, item := range items {
+		files = append(files, item.ToModel())
+	}
+	return files, rsp.Total(), nil
+}
+
+// RestoreRecycleBin 将回收站中的文件还原到原位置。原位置已存在同名文件的条目
+// 会被跳过并保留在回收站，记录在 BatchResult.Conflicts 中。
+func (c *Client) RestoreRecycleBin(ctx context.Context, files []model.File) (*BatchResult, error) {
+	result, err := c.recycleBatch(ctx, BatchTaskRestore, files)
+	if err != nil {
+		return nil, err
+	}
+	c.notifyRestore(files, result.Conflicts)
+	return result, nil
+}
+
+// PurgeRecycleBin 彻底删除回收站中的指定文件。
+func (c *Client) PurgeRecycleBin(ctx context.Context, files []model.File) error {
+	_, err := c.recycleBatch(ctx, BatchTaskClearRecycle, files)
+	return err
+}
+
+// EmptyRecycleBin 清空回收站。
+func (c *Client) EmptyRecycleBin(ctx context.Context) error {
+	if c == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	_, _, _, err := c.executeBatchTask(ctx, BatchTaskEmptyRecycle, []batchTaskInfo{}, nil, ConflictSkip)
+	return err
+}
+
+func (c *Client) recycleBatch(ctx context.Context, taskType string, files []model.File) (*BatchResult, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if len(files) == 0 {
+		return &BatchResult{}, nil
+	}
+	infos := make([]batchTaskInfo, 0, len(files))
+	for _, f := range files {
+		info := batchTaskInfo{FileID: FlexString(f.ID), FileName: f.Name}
+		if f.IsFolder {
+			info.IsFolder = 1
+		}
+		infos = append(infos, info)
+	}
+	taskID, status, conflicts, err := c.executeBatchTask(ctx, taskType, infos, nil, ConflictSkip)
+	if err != nil {
+		return nil, err
+	}
+	result := &BatchResult{TaskID: taskID, Status: *status}
+	for _, info := range conflicts {
+		result.Conflicts = append(result.Conflicts, BatchConflict{File: info.file(), Policy: ConflictSkip})
+	}
+	return result, nil
+}
+
+// notifyRestore 按原父目录分组通知已还原的文件，跳过的冲突条目不计入。
+func (c *Client) notifyRestore(files []model.File, conflicts []BatchConflict) {
+	skipped := make(map[string]bool, len(conflicts))
+	for _, conflict := range conflicts {
+		skipped[conflict.File.ID.String()] = true
+	}
+	var parents []string
+	byParent := make(map[string][]string)
+	for _, f := range files {
+		if skipped[f.ID] {
+			continue
+		}
+		if _, ok := byParent[f.ParentID]; !ok {
+			parents = append(parents, f.ParentID)
+		}
+		byParent[f.ParentID] = append(byParent[f.ParentID], f.ID)
+	}
+	for _, parent := range parents {
+		c.notifyChange(ChangeEvent{Op: ChangeRestore, FileIDs: byParent[parent], ParentID: parent})
+	}
+}
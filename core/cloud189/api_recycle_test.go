@@ -0,0 +1,160 @@
+package cloud189
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// TestListRecycleBin 兼容 fileListAO 与顶层列表两种结构，并保留删除前路径。
+func TestListRecycleBin(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/listRecycleBinFiles.action", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("pageNum") == "1" {
+			if q.Get("orderBy") != "lastOpTime" || q.Get("descending") != "true" {
+				t.Errorf("默认排序参数异常: %v", q)
+			}
+			writeJSON(w, map[string]any{"res_code": 0, "fileListAO": map[string]any{
+				"count":      3,
+				"fileList":   []map[string]any{{"id": "1", "name": "a.txt", "parentId": "10", "filePath": "/docs"}},
+				"folderList": []map[string]any{{"id": "2", "name": "old"}},
+			}})
+			return
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "count": 3, "fileList": []map[string]any{{"id": "3", "name": "b.txt"}}})
+	})
+	client := newTestClient(t, mux)
+
+	files, total, err := client.ListRecycleBin(context.Background())
+	if err != nil {
+		t.Fatalf("列出回收站失败: %v", err)
+	}
+	if total != 3 || len(files) != 2 {
+		t.Fatalf("回收站数量异常: total=%d files=%+v", total, files)
+	}
+	if files[0].ID != "2" || !files[0].IsFolder || files[1].ParentPath != "/docs" || files[1].ParentID != "10" {
+		t.Fatalf("回收站条目异常: %+v", files)
+	}
+	files, total, err = client.ListRecycleBin(context.Background(), WithListPagination(2, 0))
+	if err != nil || total != 3 || len(files) != 1 || files[0].ID != "3" {
+		t.Fatalf("顶层结构解析异常: %+v %d %v", files, total, err)
+	}
+}
+
+// TestRestoreRecycleBin_ReportsConflicts 原位置同名的条目跳过并返回，其余条目按原父目录通知变更。
+func TestRestoreRecycleBin_ReportsConflicts(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		polls   int
+		dealWay int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("type") != BatchTaskRestore {
+			t.Errorf("任务类型异常: %v", r.Form)
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "t1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls++
+		if polls == 1 {
+			writeJSON(w, map[string]any{"res_code": 0, "taskStatus": batchTaskStatusConflict})
+			return
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "taskStatus": batchTaskStatusDone, "successedCount": 2, "skipCount": 1})
+	})
+	mux.HandleFunc("/batch/getConflictTaskInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskInfos": []map[string]any{{"fileId": "2", "fileName": "b.txt", "isFolder": 0}}})
+	})
+	mux.HandleFunc("/batch/manageBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		var infos []batchTaskInfo
+		if err := json.Unmarshal([]byte(r.Form.Get("taskInfos")), &infos); err != nil || len(infos) != 1 {
+			t.Errorf("冲突处理参数异常: %v", r.Form)
+			return
+		}
+		mu.Lock()
+		dealWay = infos[0].DealWay
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+	client := newTestClient(t, mux, WithBatchPollInterval(1))
+	var events []ChangeEvent
+	client.SubscribeChanges(func(evt ChangeEvent) { events = append(events, evt) })
+
+	files := []model.File{
+		{ID: "1", Name: "a.txt", ParentID: "10"},
+		{ID: "2", Name: "b.txt", ParentID: "10"},
+		{ID: "3", Name: "dir", ParentID: "20", IsFolder: true},
+	}
+	result, err := client.RestoreRecycleBin(context.Background(), files)
+	if err != nil {
+		t.Fatalf("还原失败: %v", err)
+	}
+	if dealWay != ConflictSkip.dealWay() {
+		t.Fatalf("还原冲突应跳过，dealWay=%d", dealWay)
+	}
+	if result.TaskID != "t1" || len(result.Conflicts) != 1 || result.Conflicts[0].File.ID != "2" || result.Conflicts[0].Policy != ConflictSkip {
+		t.Fatalf("冲突结果异常: %+v", result)
+	}
+	want := []ChangeEvent{
+		{Op: ChangeRestore, FileIDs: []string{"1"}, ParentID: "10"},
+		{Op: ChangeRestore, FileIDs: []string{"3"}, ParentID: "20"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("变更事件异常:\n得到 %+v\n期望 %+v", events, want)
+	}
+
+	if result, err := client.RestoreRecycleBin(context.Background(), nil); err != nil || len(result.Conflicts) != 0 {
+		t.Fatalf("空列表应直接返回: %+v %v", result, err)
+	}
+}
+
+// TestPurgeAndEmptyRecycleBin 彻底删除与清空回收站提交对应的批量任务。
+func TestPurgeAndEmptyRecycleBin(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		types []string
+		infos []string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		types = append(types, r.Form.Get("type"))
+		infos = append(infos, r.Form.Get("taskInfos"))
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "t1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskStatus": batchTaskStatusDone})
+	})
+	client := newTestClient(t, mux)
+	var events int
+	client.SubscribeChanges(func(ChangeEvent) { events++ })
+	ctx := context.Background()
+
+	if err := client.PurgeRecycleBin(ctx, []model.File{{ID: "1", Name: "a.txt"}}); err != nil {
+		t.Fatalf("彻底删除失败: %v", err)
+	}
+	if err := client.EmptyRecycleBin(ctx); err != nil {
+		t.Fatalf("清空回收站失败: %v", err)
+	}
+	wantTypes := []string{BatchTaskClearRecycle, BatchTaskEmptyRecycle}
+	wantInfos := []string{`[{"fileId":"1","fileName":"a.txt","isFolder":0}]`, `[]`}
+	if !reflect.DeepEqual(types, wantTypes) || !reflect.DeepEqual(infos, wantInfos) {
+		t.Fatalf("批量任务异常: %v %v", types, infos)
+	}
+	if events != 0 {
+		t.Fatalf("回收站内的删除不应产生变更事件，实际 %d 个", events)
+	}
+}
@@ -0,0 +1,115 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// 分享有效期（天），ShareExpirePermanent 表示永久有效。
+const (
+	ShareExpireOneDay    = 1
+	ShareExpireSevenDays = 7
+	ShareExpirePermanent = 2099
+)
+
+// 分享类型。
+const (
+	shareTypePublic  = "1"
+	shareTypePrivate = "3"
+)
+
+// ShareOption 配置分享参数。
+type ShareOption func(params map[string]string)
+
+// WithShareExpire 设置分享有效期（天），days<=0 表示永久有效。
+func WithShareExpire(days int) ShareOption {
+	return func(params map[string]string) {
+		if days <= 0 {
+			days = ShareExpirePermanent
+		}
+		params["expireTime"] = strconv.Itoa(days)
+	}
+}
+
+// WithShareAccessCode 开启/关闭访问码，关闭后任何人可通过链接访问。
+func WithShareAccessCode(enabled bool) ShareOption {
+	return func(params map[string]string) {
+		if enabled {
+			params["shareType"] = shareTypePrivate
+		} else {
+			params["shareType"] = shareTypePublic
+		}
+	}
+}
+
+// CreateShare 为文件或文件夹创建分享链接，默认 7 天有效并带访问码。
+func (c *Client) CreateShare(ctx context.Context, fileID string, opts ...ShareOption) (*ShareInfo, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if fileID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "fileID 不能为空", errors.New("cloud189: fileID 为空"))
+	}
+	params := map[string]string{
+		"fileId":     fileID,
+		"expireTime": strconv.Itoa(ShareExpireSevenDays),
+		"shareType":  shareTypePrivate,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	var rsp ShareCreateResponse
+	if err := c.AppGet(ctx, "/createShareLink.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	info := rsp.Share()
+	if info.FileID == "" {
+		info.FileID = FlexString(fileID)
+	}
+	if days, err := strconv.Atoi(params["expireTime"]); err == nil {
+		info.ExpireDays = days
+	}
+	return &info, nil
+}
+
+// ListShares 列出当前账号创建的分享，分页参数复用 WithListPagination。
+func (c *Client) ListShares(ctx context.Context, opts ...ListOption) (*ShareListResponse, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	params := map[string]string{
+		"shareType": "1",
+		"pageNum":   "1",
+		"pageSize":  "100",
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	var rsp ShareListResponse
+	if err := c.AppGet(ctx, "/listShares.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// CancelShares 批量取消分享。
+func (c *Client) CancelShares(ctx context.Context, shareIDs []string) error {
+	if c == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if len(shareIDs) == 0 {
+		return nil
+	}
+	params := map[string]string{
+		"shareIdList": strings.Join(shareIDs, ","),
+		"cancelType":  "1",
+	}
+	var rsp CodeResponse
+	return c.AppPost(ctx, "/cancelShare.action", params, &rsp)
+}
@@ -0,0 +1,184 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	shareCodePattern  = regexp.MustCompile(`/t/([0-9A-Za-z]+)`)
+	accessCodePattern = regexp.MustCompile(`访问码[：:\s]*([0-9A-Za-z]{4,})`)
+)
+
+// ParseShareURL 解析分享链接，返回分享码与链接文本中携带的访问码。
+// 支持 https://cloud.189.cn/t/xxxx、https://cloud.189.cn/web/share?code=xxxx
+// 以及附带“（访问码：xxxx）”的复制文本。
+func ParseShareURL(raw string) (shareCode, accessCode string, err error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", "", WrapCloudError(ErrCodeInvalidRequest, "分享链接不能为空", errors.New("cloud189: 分享链接为空"))
+	}
+	if m := accessCodePattern.FindStringSubmatch(raw); m != nil {
+		accessCode = m[1]
+	}
+	if m := shareCodePattern.FindStringSubmatch(raw); m != nil {
+		return m[1], accessCode, nil
+	}
+	link := raw
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	if u, parseErr := url.Parse(link); parseErr == nil {
+		if code := u.Query().Get("code"); code != "" {
+			return code, accessCode, nil
+		}
+	}
+	return "", "", WrapCloudError(ErrCodeInvalidRequest, "无法识别的分享链接", errors.New("cloud189: 无法识别的分享链接 "+raw))
+}
+
+// OpenShare 解析分享链接并校验访问码，accessCode 为空时使用链接文本中的访问码。
+func (c *Client) OpenShare(ctx context.Context, rawURL, accessCode string) (*PublicShare, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	code, parsedCode, err := ParseShareURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if accessCode == "" {
+		accessCode = parsedCode
+	}
+	var share PublicShare
+	if err := c.WebGet(ctx, "/open/share/getShareInfoByCodeV2.action", map[string]string{"shareCode": code}, &share); err != nil {
+		return nil, err
+	}
+	share.ShareCode = code
+	if share.NeedAccessCode == 1 || share.ShareID == "" {
+		if accessCode == "" {
+			return nil, WrapCloudError(ErrCodeForbidden, "分享需要访问码", errors.New("cloud189: 分享需要访问码"))
+		}
+		var check struct {
+			CodeResponse
+			ShareID FlexString `json:"shareId,omitempty"`
+		}
+		params := map[string]string{
+			"shareCode":  code,
+			"accessCode": accessCode,
+		}
+		if err := c.WebGet(ctx, "/open/share/checkAccessCode.action", params, &check); err != nil {
+			return nil, ensureCloudError(ErrCodeForbidden, "访问码校验失败", err)
+		}
+		if check.ShareID == "" {
+			return nil, WrapCloudError(ErrCodeForbidden, "访问码错误", errors.New("cloud189: 访问码错误"))
+		}
+		share.ShareID = check.ShareID
+	}
+	share.AccessCode = accessCode
+	return &share, nil
+}
+
+// ListShareFiles 浏览分享内容，folderID 为空表示分享根目录，分页与排序复用 ListOption。
+func (c *Client) ListShareFiles(ctx context.Context, share *PublicShare, folderID string, opts ...ListOption) (*FileListResponse, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if share == nil || share.ShareID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "分享未打开", errors.New("cloud189: PublicShare 为空"))
+	}
+	// 单文件分享没有目录结构，直接返回分享文件本身。
+	if !share.IsFolder {
+		file := share.File()
+		return &FileListResponse{
+			FileListAO: FileListResult{Count: 1, Files: []FileInfo{file}},
+		}, nil
+	}
+	if folderID == "" {
+		folderID = share.FileID.String()
+	}
+	params := map[string]string{
+		"fileId":         folderID,
+		"shareDirFileId": folderID,
+		"isFolder":       "true",
+		"shareId":        share.ShareID.String(),
+		"shareMode":      share.ShareMode.String(),
+		"accessCode":     share.AccessCode,
+		"iconOption":     "0",
+		"orderBy":        "filename",
+		"descending":     "true",
+		"pageNum":        "1",
+		"pageSize":       "100",
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	var rsp FileListResponse
+	if err := c.WebGet(ctx, "/open/share/listShareDir.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// SaveShareFiles 将分享中的文件转存到自己的 destFolderID 目录。
+// 返回值逐项记录转存结果，同名冲突的文件会被跳过并标记 CloudError；
+// 服务端未返回逐项结果且部分失败时，非冲突文件标记为 Unknown。
+func (c *Client) SaveShareFiles(ctx context.Context, share *PublicShare, files []FileInfo, destFolderID string) ([]ShareSaveResult, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if share == nil || share.ShareID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "分享未打开", errors.New("cloud189: PublicShare 为空"))
+	}
+	if destFolderID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "目标目录不能为空", errors.New("cloud189: destFolderID 为空"))
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	params := map[string]string{
+		"targetFolderId": destFolderID,
+		"shareId":        share.ShareID.String(),
+	}
+	_, status, skipped, err := c.executeBatchTask(ctx, BatchTaskSave, newBatchTaskInfos(files), params, ConflictSkip)
+	if err != nil {
+		return nil, err
+	}
+	// 转存生成的新文件 ID 未知，只通知目标目录内容已变化。
+	c.notifyChange(ChangeEvent{Op: ChangeCopy, ParentID: destFolderID})
+	conflicts := make(map[string]bool, len(skipped))
+	for _, info := range skipped {
+		conflicts[info.FileID.String()] = true
+	}
+	return shareSaveResults(files, status, conflicts), nil
+}
+
+func shareSaveResults(files []FileInfo, status *BatchTaskStatus, conflicts map[string]bool) []ShareSaveResult {
+	succeeded := make(map[string]bool, len(status.SuccessedFileIDList))
+	for _, id := range status.SuccessedFileIDList {
+		succeeded[id.String()] = true
+	}
+	results := make([]ShareSaveResult, 0, len(files))
+	for _, f := range files {
+		id := f.ID.String()
+		result := ShareSaveResult{File: f}
+		switch {
+		case conflicts[id]:
+			result.Err = NewCloudError(ErrCodeInvalidRequest, "目标目录存在同名文件")
+		case len(succeeded) > 0:
+			if !succeeded[id] {
+				result.Err = NewCloudError(ErrCodeServer, "转存失败")
+			}
+		case status.FailedCount > 0 && status.SuccessedCount == 0:
+			result.Err = NewCloudError(ErrCodeServer, "转存失败")
+		case status.FailedCount > 0:
+			// 只有计数没有逐项结果，无法判断该文件属于成功还是失败。
+			result.Unknown = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,240 @@
+package cloud189
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestParseShareURL 覆盖常见分享链接格式与访问码提取。
+func TestParseShareURL(t *testing.T) {
+	cases := []struct {
+		name       string
+		raw        string
+		code       string
+		accessCode string
+	}{
+		{name: "short_link", raw: "https://cloud.189.cn/t/AbCd1234", code: "AbCd1234"},
+		{name: "no_scheme", raw: "cloud.189.cn/t/AbCd1234", code: "AbCd1234"},
+		{name: "web_share", raw: "https://cloud.189.cn/web/share?code=XyZ987", code: "XyZ987"},
+		{name: "with_access_code", raw: "https://cloud.189.cn/t/AbCd1234（访问码：k9x2）", code: "AbCd1234", accessCode: "k9x2"},
+		{name: "ascii_colon", raw: "链接 https://cloud.189.cn/t/AbCd1234 访问码: ab12", code: "AbCd1234", accessCode: "ab12"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, accessCode, err := ParseShareURL(tc.raw)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if code != tc.code || accessCode != tc.accessCode {
+				t.Fatalf("期望 (%s, %s)，实际 (%s, %s)", tc.code, tc.accessCode, code, accessCode)
+			}
+		})
+	}
+
+	if _, _, err := ParseShareURL("https://example.com/nothing"); err == nil {
+		t.Fatalf("无法识别的链接应返回错误")
+	}
+}
+
+// TestOpenShare_AccessCode 公开分享直接返回；加密分享缺少或填错访问码时拒绝，链接文本中的访问码可直接使用。
+func TestOpenShare_AccessCode(t *testing.T) {
+	var checks []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/open/share/getShareInfoByCodeV2.action", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("shareCode") {
+		case "public":
+			writeJSON(w, map[string]any{"res_code": 0, "shareId": 7, "fileId": "70", "fileName": "pub.txt"})
+		default:
+			writeJSON(w, map[string]any{"res_code": 0, "needAccessCode": 1, "fileId": "80", "fileName": "docs", "isFolder": true})
+		}
+	})
+	mux.HandleFunc("/open/share/checkAccessCode.action", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		checks = append(checks, q.Get("accessCode"))
+		if q.Get("shareCode") != "secret" {
+			t.Errorf("校验访问码的分享码异常: %v", q)
+		}
+		if q.Get("accessCode") != "k9x2" {
+			writeJSON(w, map[string]any{"res_code": 0})
+			return
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "shareId": 8})
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	share, err := client.OpenShare(ctx, "https://cloud.189.cn/t/public", "")
+	if err != nil || share.ShareID != "7" || share.ShareCode != "public" || len(checks) != 0 {
+		t.Fatalf("公开分享应直接返回: %v %+v %v", err, share, checks)
+	}
+	forbidden := func(err error) bool {
+		var ce *CloudError
+		return errors.As(err, &ce) && ce.Code == ErrCodeForbidden
+	}
+	if _, err := client.OpenShare(ctx, "https://cloud.189.cn/t/secret", ""); !forbidden(err) || len(checks) != 0 {
+		t.Fatalf("缺少访问码应直接拒绝: %v %v", err, checks)
+	}
+	if _, err := client.OpenShare(ctx, "https://cloud.189.cn/t/secret", "bad1"); !forbidden(err) {
+		t.Fatalf("错误访问码应返回 Forbidden，实际 %v", err)
+	}
+	share, err = client.OpenShare(ctx, "https://cloud.189.cn/t/secret（访问码：k9x2）", "")
+	if err != nil {
+		t.Fatalf("使用链接中的访问码打开失败: %v", err)
+	}
+	if share.ShareID != "8" || share.AccessCode != "k9x2" || !share.IsFolder || share.FileID != "80" {
+		t.Fatalf("分享信息异常: %+v", share)
+	}
+	if strings.Join(checks, ",") != "bad1,k9x2" {
+		t.Fatalf("访问码校验记录异常: %v", checks)
+	}
+}
+
+// TestListShareFiles_Pagination 文件夹分享默认列出根目录并透传分页参数，单文件分享不发请求。
+func TestListShareFiles_Pagination(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/open/share/listShareDir.action", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("shareId") != "8" || q.Get("accessCode") != "k9x2" || q.Get("shareMode") != "1" {
+			t.Errorf("分享参数异常: %v", q)
+		}
+		switch q.Get("fileId") {
+		case "80":
+			if q.Get("pageNum") != "1" || q.Get("pageSize") != "100" || q.Get("shareDirFileId") != "80" {
+				t.Errorf("默认分页参数异常: %v", q)
+			}
+			writeJSON(w, map[string]any{"res_code": 0, "fileListAO": map[string]any{
+				"count":      2,
+				"fileList":   []map[string]any{{"id": "81", "name": "a.txt", "size": 3}},
+				"folderList": []map[string]any{{"id": "82", "name": "sub"}},
+			}})
+		default:
+			if q.Get("fileId") != "82" || q.Get("pageNum") != "3" || q.Get("pageSize") != "20" || q.Get("orderBy") != "lastOpTime" {
+				t.Errorf("自定义分页参数异常: %v", q)
+			}
+			writeJSON(w, map[string]any{"res_code": 0, "fileListAO": map[string]any{"count": 41}})
+		}
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+	share := &PublicShare{ShareID: "8", ShareMode: "1", AccessCode: "k9x2", FileID: "80", IsFolder: true}
+
+	rsp, err := client.ListShareFiles(ctx, share, "")
+	if err != nil {
+		t.Fatalf("列出分享根目录失败: %v", err)
+	}
+	if items := rsp.Items(); len(items) != 2 || rsp.FileListAO.Count != 2 {
+		t.Fatalf("分享根目录条目异常: %+v", rsp.FileListAO)
+	}
+	rsp, err = client.ListShareFiles(ctx, share, "82", WithListPagination(3, 20), WithListOrder("lastOpTime", false))
+	if err != nil || rsp.FileListAO.Count != 41 {
+		t.Fatalf("分页列出子目录失败: %v %+v", err, rsp)
+	}
+
+	single := &PublicShare{ShareID: "7", FileID: "70", FileName: "pub.txt", FileSize: 5}
+	rsp, err = client.ListShareFiles(ctx, single, "")
+	if err != nil || len(rsp.Items()) != 1 || rsp.Items()[0].ID != "70" {
+		t.Fatalf("单文件分享应返回文件本身: %v %+v", err, rsp)
+	}
+	if _, err := client.ListShareFiles(ctx, &PublicShare{}, ""); err == nil {
+		t.Fatalf("未打开的分享应返回错误")
+	}
+}
+
+// TestSaveShareFiles_Results 逐项映射成功、冲突与失败；只有计数时部分失败的条目标记为未知，并通知目标目录变化。
+func TestSaveShareFiles_Results(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		conflict bool
+		final    map[string]any
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("type") != BatchTaskSave || r.Form.Get("targetFolderId") != "dest" || r.Form.Get("shareId") != "8" {
+			t.Errorf("转存任务参数异常: %v", r.Form)
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "save-1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if conflict {
+			writeJSON(w, map[string]any{"res_code": 0, "taskStatus": 2})
+			return
+		}
+		writeJSON(w, final)
+	})
+	mux.HandleFunc("/batch/getConflictTaskInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"res_code":  0,
+			"taskInfos": []map[string]any{{"fileId": "2", "fileName": "b.txt", "isFolder": 0}},
+		})
+	})
+	mux.HandleFunc("/batch/manageBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		var infos []batchTaskInfo
+		if err := json.Unmarshal([]byte(r.Form.Get("taskInfos")), &infos); err != nil || len(infos) != 1 || infos[0].DealWay != 1 {
+			t.Errorf("冲突应按跳过处理: %v", r.Form.Get("taskInfos"))
+		}
+		mu.Lock()
+		conflict = false
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+	client := newTestClient(t, mux)
+	var events []ChangeEvent
+	client.SubscribeChanges(func(evt ChangeEvent) { events = append(events, evt) })
+	ctx := context.Background()
+	share := &PublicShare{ShareID: "8", FileID: "80", IsFolder: true}
+	files := []FileInfo{{ID: "1", FileName: "a.txt"}, {ID: "2", FileName: "b.txt"}, {ID: "3", FileName: "c.txt"}}
+	save := func(status map[string]any, withConflict bool) []ShareSaveResult {
+		t.Helper()
+		mu.Lock()
+		conflict, final = withConflict, status
+		mu.Unlock()
+		results, err := client.SaveShareFiles(ctx, share, files, "dest")
+		if err != nil || len(results) != len(files) {
+			t.Fatalf("转存失败: %v %+v", err, results)
+		}
+		return results
+	}
+
+	results := save(map[string]any{"res_code": 0, "taskStatus": 4, "successedCount": 1, "failedCount": 1, "successedFileIdList": []string{"1"}}, true)
+	if results[0].Err != nil || results[0].Unknown {
+		t.Fatalf("成功条目不应带错误: %+v", results[0])
+	}
+	var ce *CloudError
+	if !errors.As(results[1].Err, &ce) || ce.Code != ErrCodeInvalidRequest {
+		t.Fatalf("冲突条目应标记同名错误: %+v", results[1])
+	}
+	if !errors.As(results[2].Err, &ce) || ce.Code != ErrCodeServer {
+		t.Fatalf("未列入成功列表的条目应标记失败: %+v", results[2])
+	}
+	if len(events) != 1 || events[0].Op != ChangeCopy || events[0].ParentID != "dest" {
+		t.Fatalf("转存后应通知目标目录变化: %+v", events)
+	}
+
+	results = save(map[string]any{"res_code": 0, "taskStatus": 4, "successedCount": 2, "failedCount": 1}, false)
+	for _, r := range results {
+		if r.Err != nil || !r.Unknown {
+			t.Fatalf("只有计数时应标记为未知而不是失败: %+v", r)
+		}
+	}
+	results = save(map[string]any{"res_code": 0, "taskStatus": 4, "failedCount": 3}, false)
+	for _, r := range results {
+		if r.Err == nil || r.Unknown {
+			t.Fatalf("全部失败时每项都应带错误: %+v", r)
+		}
+	}
+	results = save(map[string]any{"res_code": 0, "taskStatus": 4, "successedCount": 3}, false)
+	for _, r := range results {
+		if r.Err != nil || r.Unknown {
+			t.Fatalf("没有失败时每项都应成功: %+v", r)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// TestShareAPIs_Requests 覆盖创建（含默认参数与 shareLinkList 结构）、列表分页与取消分享。
+func TestShareAPIs_Requests(t *testing.T) {
+	var canceled []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/createShareLink.action", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch q.Get("fileId") {
+		case "1":
+			if q.Get("expireTime") != "7" || q.Get("shareType") != "3" {
+				t.Errorf("默认分享参数异常: %v", q)
+			}
+			writeJSON(w, map[string]any{"res_code": 0, "shareId": 100, "accessCode": "ab12", "shortShareUrl": "https://cloud.189.cn/t/short"})
+		default:
+			if q.Get("expireTime") != "2099" || q.Get("shareType") != "1" {
+				t.Errorf("自定义分享参数异常: %v", q)
+			}
+			writeJSON(w, map[string]any{"res_code": 0, "shareLinkList": []map[string]any{
+				{"shareId": 101, "url": "https://cloud.189.cn/t/list", "accessCode": ""},
+			}})
+		}
+	})
+	mux.HandleFunc("/listShares.action", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("pageNum") != "2" || q.Get("pageSize") != "10" {
+			t.Errorf("分页参数异常: %v", q)
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "recordCount": 11, "data": []map[string]any{{
+			"shareId":       "102",
+			"fileId":        "3",
+			"fileName":      "docs",
+			"isFolder":      true,
+			"accessURL":     "https://cloud.189.cn/web/share?code=x",
+			"expireType":    1,
+			"expireTime":    "2024-01-02 00:00:00",
+			"shareDate":     "2024-01-01 00:00:00",
+			"previewCount":  5,
+			"downloadCount": 2,
+			"saveCount":     1,
+		}}})
+	})
+	mux.HandleFunc("/cancelShare.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("cancelType") != "1" {
+			t.Errorf("取消类型异常: %v", r.Form)
+		}
+		canceled = append(canceled, r.Form.Get("shareIdList"))
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	share, err := client.CreateShare(ctx, "1")
+	if err != nil {
+		t.Fatalf("创建分享失败: %v", err)
+	}
+	if share.ShareID != "100" || share.FileID != "1" || share.URL() != "https://cloud.189.cn/t/short" || share.ExpireDays != ShareExpireSevenDays {
+		t.Fatalf("分享信息异常: %+v", share)
+	}
+	share, err = client.CreateShare(ctx, "2", WithShareExpire(0), WithShareAccessCode(false))
+	if err != nil {
+		t.Fatalf("创建公开分享失败: %v", err)
+	}
+	if share.ShareID != "101" || share.FileID != "2" || share.URL() != "https://cloud.189.cn/t/list" || !share.ToModel().Permanent {
+		t.Fatalf("shareLinkList 结构解析异常: %+v", share)
+	}
+	if _, err := client.CreateShare(ctx, ""); err == nil {
+		t.Fatalf("缺少 fileID 应返回错误")
+	}
+
+	list, err := client.ListShares(ctx, WithListPagination(2, 10))
+	if err != nil || len(list.Data) != 1 || list.RecordCount != 11 {
+		t.Fatalf("分享列表异常: %+v %v", list, err)
+	}
+	got := list.Data[0].ToModel()
+	want := model.Share{
+		ID:            "102",
+		FileID:        "3",
+		FileName:      "docs",
+		IsFolder:      true,
+		URL:           "https://cloud.189.cn/web/share?code=x",
+		ExpiresAt:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		CreatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ViewCount:     5,
+		DownloadCount: 2,
+		SaveCount:     1,
+	}
+	if got != want {
+		t.Fatalf("领域模型转换异常:\n得到 %+v\n期望 %+v", got, want)
+	}
+
+	if err := client.CancelShares(ctx, nil); err != nil {
+		t.Fatalf("空列表应直接返回: %v", err)
+	}
+	if err := client.CancelShares(ctx, []string{"100", "101"}); err != nil {
+		t.Fatalf("取消分享失败: %v", err)
+	}
+	if len(canceled) != 1 || canceled[0] != "100,101" {
+		t.Fatalf("取消请求异常: %v", canceled)
+	}
+}
+
+// TestShare_Expired 永久或未知到期时间的分享不过期，到期时刻起视为过期。
+func TestShare_Expired(t *testing.T) {
+	deadline := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name  string
+		share model.Share
+		now   time.Time
+		want  bool
+	}{
+		{name: "before", share: model.Share{ExpiresAt: deadline}, now: deadline.Add(-time.Second), want: false},
+		{name: "at_deadline", share: model.Share{ExpiresAt: deadline}, now: deadline, want: true},
+		{name: "permanent", share: model.Share{Permanent: true, ExpiresAt: deadline}, now: deadline.Add(time.Hour), want: false},
+		{name: "unknown", share: model.Share{}, now: deadline, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.share.Expired(tc.now); got != tc.want {
+				t.Fatalf("期望 %v，实际 %v", tc.want, got)
+			}
+		})
+	}
+}
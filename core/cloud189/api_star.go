@@ -0,0 +1,82 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+)
+
+// StarFiles 批量收藏文件或文件夹。
+func (c *Client) StarFiles(ctx context.Context, fileIDs []string) error {
+	return c.setStar(ctx, fileIDs, true)
+}
+
+// UnstarFiles 批量取消收藏。
+func (c *Client) UnstarFiles(ctx context.Context, fileIDs []string) error {
+	return c.setStar(ctx, fileIDs, false)
+}
+
+// ListStarredFiles 分页列出已收藏的文件与文件夹，分页与排序复用 ListOption。
+func (c *Client) ListStarredFiles(ctx context.Context, opts ...ListOption) (*FileListResponse, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	params := map[string]string{
+		"iconOption": "0",
+		"orderBy":    "lastOpTime",
+		"descending": "true",
+		"pageNum":    "1",
+		"pageSize":   "100",
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	var rsp FileListResponse
+	if err := c.AppGet(ctx, "/listStarFiles.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	// 收藏列表中的条目不一定携带 starLabel，统一标记为已收藏。
+	markStarred(rsp.FileListAO.Files)
+	markStarred(rsp.FileListAO.Folders)
+	markStarred(rsp.Data)
+	return &rsp, nil
+}
+
+// IterateStarredFiles 返回遍历全部收藏分页的迭代器。
+func (c *Client) IterateStarredFiles(ctx context.Context, opts ...ListOption) *FileIterator {
+	return newFileIterator(ctx, opts, func(ctx context.Context, pageNum, pageSize int) (*FileListResponse, error) {
+		return c.ListStarredFiles(ctx, append(opts, WithListPagination(pageNum, pageSize))...)
+	})
+}
+
+func (c *Client) setStar(ctx context.Context, fileIDs []string, starred bool) error {
+	if c == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if len(fileIDs) == 0 {
+		return nil
+	}
+	label := "0"
+	if starred {
+		label = "1"
+	}
+	params := map[string]string{
+		"fileIdList": joinIDs(fileIDs),
+		"starLabel":  label,
+	}
+	var rsp CodeResponse
+	if err := c.AppPost(ctx, "/batchStarFile.action", params, &rsp); err != nil {
+		return err
+	}
+	c.notifyChange(ChangeEvent{Op: ChangeStar, FileIDs: fileIDs})
+	return nil
+}
+
+func markStarred(items []FileInfo) {
+	for i := range items {
+		if items[i].StarLabel == 0 {
+			items[i].StarLabel = 1
+		}
+	}
+}
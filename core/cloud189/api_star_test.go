@@ -0,0 +1,97 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// TestStarFiles 收藏与取消收藏以单次请求提交并通知变更，空列表不发请求。
+func TestStarFiles(t *testing.T) {
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batchStarFile.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		requests = append(requests, r.Form.Get("fileIdList")+"="+r.Form.Get("starLabel"))
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+	client := newTestClient(t, mux)
+	var events []ChangeEvent
+	client.SubscribeChanges(func(evt ChangeEvent) { events = append(events, evt) })
+	ctx := context.Background()
+
+	if err := client.StarFiles(ctx, []string{"1", "2"}); err != nil {
+		t.Fatalf("收藏失败: %v", err)
+	}
+	if err := client.UnstarFiles(ctx, []string{"3"}); err != nil {
+		t.Fatalf("取消收藏失败: %v", err)
+	}
+	if err := client.StarFiles(ctx, nil); err != nil {
+		t.Fatalf("空列表应直接返回: %v", err)
+	}
+	if want := []string{"1;2=1", "3=0"}; !reflect.DeepEqual(requests, want) {
+		t.Fatalf("请求异常: 得到 %q，期望 %q", requests, want)
+	}
+	want := []ChangeEvent{
+		{Op: ChangeStar, FileIDs: []string{"1", "2"}},
+		{Op: ChangeStar, FileIDs: []string{"3"}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("变更事件异常: %+v", events)
+	}
+}
+
+// TestListStarredFiles 收藏列表条目统一标记为已收藏，迭代器按总数翻页。
+func TestListStarredFiles(t *testing.T) {
+	var pages []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/listStarFiles.action", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		pages = append(pages, q.Get("pageNum"))
+		if q.Get("orderBy") != "lastOpTime" || q.Get("pageSize") != "2" {
+			t.Errorf("列表参数异常: %v", q)
+		}
+		if q.Get("pageNum") == "1" {
+			writeJSON(w, map[string]any{"res_code": 0, "fileListAO": map[string]any{
+				"count":      3,
+				"fileList":   []map[string]any{{"id": "1", "name": "a.txt"}},
+				"folderList": []map[string]any{{"id": "2", "name": "dir", "starLabel": 2}},
+			}})
+			return
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "fileListAO": map[string]any{
+			"count":    3,
+			"fileList": []map[string]any{{"id": "3", "name": "b.txt"}},
+		}})
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	rsp, err := client.ListStarredFiles(ctx, WithListPagination(1, 2))
+	if err != nil {
+		t.Fatalf("列出收藏失败: %v", err)
+	}
+	for _, item := range rsp.Items() {
+		if !item.ToModel().Starred {
+			t.Fatalf("收藏列表条目应标记为已收藏: %+v", item)
+		}
+	}
+	if rsp.FileListAO.Folders[0].StarLabel != 2 {
+		t.Fatalf("已有的 starLabel 不应被覆盖: %+v", rsp.FileListAO.Folders[0])
+	}
+
+	pages = nil
+	it := client.IterateStarredFiles(ctx, WithListPagination(0, 2))
+	defer it.Close()
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.File().ID.String())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("遍历收藏失败: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"2", "1", "3"}) || !reflect.DeepEqual(pages, []string{"1", "2"}) {
+		t.Fatalf("遍历结果异常: ids=%v pages=%v", ids, pages)
+	}
+}
@@ -22,6 +22,7 @@ const DefaultSliceSize = 10 * 1024 * 1024
 // UploadSession 记录上传上下文与已上传分片信息。
 type UploadSession struct {
 	UploadInitData
+	FamilyID  string // 家庭云 ID，为空表示个人云
 	ParentID  string
 	FileName  string
 	FileSize  int64
@@ -48,6 +49,18 @@ type uploadURLsResponse struct {
 
 // InitUpload 初始化分片上传会话。
 func (c *Client) InitUpload(ctx context.Context, parentID, filename string, size int64) (*UploadSession, error) {
+	return c.initUpload(ctx, "", parentID, filename, size)
+}
+
+// InitFamilyUpload 初始化家庭云分片上传会话，后续 UploadPart/CommitUpload 会自动使用家庭云接口。
+func (c *Client) InitFamilyUpload(ctx context.Context, familyID, parentID, filename string, size int64) (*UploadSession, error) {
+	if familyID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "familyID 不能为空", errors.New("cloud189: familyID 为空"))
+	}
+	return c.initUpload(ctx, familyID, parentID, filename, size)
+}
+
+func (c *Client) initUpload(ctx context.Context, familyID, parentID, filename string, size int64) (*UploadSession, error) {
 	if c == nil {
 		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
 	}
@@ -63,9 +76,12 @@ func (c *Client) InitUpload(ctx context.Context, parentID, filename string, size
 	params.Set("sliceSize", strconv.Itoa(DefaultSliceSize))
 	params.Set("lazyCheck", "1")
 	params.Set("extend", `{"opScene":"1","relativepath":"","rootfolderid":""}`)
+	if familyID != "" {
+		params.Set("familyId", familyID)
+	}
 
 	var rsp UploadInitResponse
-	if err := c.AppUpload(ctx, "/person/initMultiUpload", params, &rsp); err != nil {
+	if err := c.AppUpload(ctx, uploadPrefix(familyID)+"/initMultiUpload", params, &rsp); err != nil {
 		return nil, err
 	}
 	if rsp.Data.UploadFileID == "" {
@@ -73,6 +89,7 @@ func (c *Client) InitUpload(ctx context.Context, parentID, filename string, size
 	}
 	session := &UploadSession{
 		UploadInitData: rsp.Data,
+		FamilyID:       familyID,
 		ParentID:       parentID,
 		FileName:       filename,
 		FileSize:       size,
@@ -116,6 +133,9 @@ func (c *Client) uploadPartInternal(
 	params := url.Values{}
 	params.Set("partInfo", partInfo)
 	params.Set("uploadFileId", session.UploadFileID)
+	if session.FamilyID != "" {
+		params.Set("familyId", session.FamilyID)
+	}
 
 	var rsp uploadURLsResponse
 	if err := fetchUploadURLs(ctx, params, &rsp); err != nil {
@@ -161,7 +181,7 @@ func (c *Client) uploadPartInternal(
 // UploadPart 上传单个分片。
 func (c *Client) UploadPart(ctx context.Context, session *UploadSession, partNum int, data io.Reader) error {
 	return c.uploadPartInternal(ctx, session, partNum, data, func(ctx context.Context, params url.Values, rsp *uploadURLsResponse) error {
-		return c.AppUpload(ctx, "/person/getMultiUploadUrls", params, rsp)
+		return c.AppUpload(ctx, uploadPrefix(session.FamilyID)+"/getMultiUploadUrls", params, rsp)
 	})
 }
 
@@ -172,6 +192,9 @@ func (c *Client) CommitUpload(ctx context.Context, session *UploadSession) (*Fil
 	}
 	params := url.Values{}
 	params.Set("uploadFileId", session.UploadFileID)
+	if session.FamilyID != "" {
+		params.Set("familyId", session.FamilyID)
+	}
 	if session.LazyCheck {
 		session.computeHashes()
 		if session.FileMD5 != "" {
@@ -187,10 +210,11 @@ func (c *Client) CommitUpload(ctx context.Context, session *UploadSession) (*Fil
 	}
 
 	var rsp UploadCommitResponse
-	if err := c.AppUpload(ctx, "/person/commitMultiUploadFile", params, &rsp); err != nil {
+	if err := c.AppUpload(ctx, uploadPrefix(session.FamilyID)+"/commitMultiUploadFile", params, &rsp); err != nil {
 		return nil, err
 	}
 	meta := rsp.File
+	c.notifyChange(ChangeEvent{Op: ChangeUpload, FamilyID: session.FamilyID, FileIDs: []string{meta.ID}, ParentID: session.ParentID, Name: meta.FileName})
 	return &FileInfo{
 		ID:       FlexString(meta.ID),
 		FileName: meta.FileName,
@@ -281,7 +305,7 @@ func (c *Client) WebInitUpload(ctx context.Context, parentID, filename string, s
 // WebUploadPart 使用 Web 签名上传单个分片。
 func (c *Client) WebUploadPart(ctx context.Context, session *UploadSession, partNum int, data io.Reader, rsaKey *WebRSA) error {
 	return c.uploadPartInternal(ctx, session, partNum, data, func(ctx context.Context, params url.Values, rsp *uploadURLsResponse) error {
-		return c.WebUpload(ctx, "/person/getMultiUploadUrls", params, rsaKey, rsp)
+		return c.WebUpload(ctx, uploadPrefix(session.FamilyID)+"/getMultiUploadUrls", params, rsaKey, rsp)
 	})
 }
 
@@ -292,6 +316,9 @@ func (c *Client) WebCommitUpload(ctx context.Context, session *UploadSession, rs
 	}
 	params := url.Values{}
 	params.Set("uploadFileId", session.UploadFileID)
+	if session.FamilyID != "" {
+		params.Set("familyId", session.FamilyID)
+	}
 	if session.LazyCheck {
 		session.computeHashes()
 		if session.FileMD5 != "" {
@@ -307,10 +334,11 @@ func (c *Client) WebCommitUpload(ctx context.Context, session *UploadSession, rs
 	}
 
 	var rsp UploadCommitResponse
-	if err := c.WebUpload(ctx, "/person/commitMultiUploadFile", params, rsaKey, &rsp); err != nil {
+	if err := c.WebUpload(ctx, uploadPrefix(session.FamilyID)+"/commitMultiUploadFile", params, rsaKey, &rsp); err != nil {
 		return nil, err
 	}
 	meta := rsp.File
+	c.notifyChange(ChangeEvent{Op: ChangeUpload, FamilyID: session.FamilyID, FileIDs: []string{meta.ID}, ParentID: session.ParentID, Name: meta.FileName})
 	return &FileInfo{
 		ID:       FlexString(meta.ID),
 		FileName: meta.FileName,
@@ -338,6 +366,14 @@ func (c *Client) WebSimpleUpload(ctx context.Context, parentID, filename string,
 	)
 }
 
+// uploadPrefix 根据家庭云 ID 选择上传接口前缀。
+func uploadPrefix(familyID string) string {
+	if familyID != "" {
+		return "/family"
+	}
+	return "/person"
+}
+
 func (s *UploadSession) recordHashes(partNum int, sum []byte, data []byte) {
 	if s == nil {
 		return
@@ -0,0 +1,224 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// FolderChangeKind 描述目录条目的变化类型。
+type FolderChangeKind int
+
+const (
+	// FolderChangeCreated 新增条目。
+	FolderChangeCreated FolderChangeKind = iota
+	// FolderChangeModified 内容或修订号变化。
+	FolderChangeModified
+	// FolderChangeRenamed 名称变化（ID 不变）。
+	FolderChangeRenamed
+	// FolderChangeDeleted 条目已不在目录中（删除或移出）。
+	FolderChangeDeleted
+)
+
+// String 返回变化类型的字符串表示。
+func (k FolderChangeKind) String() string {
+	switch k {
+	case FolderChangeCreated:
+		return "created"
+	case FolderChangeModified:
+		return "modified"
+	case FolderChangeRenamed:
+		return "renamed"
+	case FolderChangeDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// FolderChange 描述目录中一个条目的变化。
+type FolderChange struct {
+	Kind    FolderChangeKind
+	File    model.File
+	OldName string // 重命名前的名称
+}
+
+// SnapshotEntry 记录目录条目上次被观察到的状态。
+type SnapshotEntry struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Rev      string `json:"rev,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	MD5      string `json:"md5,omitempty"`
+	IsFolder bool   `json:"isFolder,omitempty"`
+}
+
+// FolderSnapshot 记录目录上次被观察到的修订号与条目，可由上层序列化持久化。
+type FolderSnapshot struct {
+	FolderID string                   `json:"folderId"`
+	LastRev  int64                    `json:"lastRev,omitempty"`
+	Entries  map[string]SnapshotEntry `json:"entries,omitempty"`
+}
+
+// ChangeDetector 基于列表接口的 lastRev 与条目 rev 检测目录增量变化。
+type ChangeDetector struct {
+	client *Client
+
+	mu        sync.Mutex
+	snapshots map[string]*FolderSnapshot
+}
+
+// NewChangeDetector 创建变化检测器。
+func NewChangeDetector(client *Client) *ChangeDetector {
+	return &ChangeDetector{
+		client:    client,
+		snapshots: make(map[string]*FolderSnapshot),
+	}
+}
+
+// Changes 返回 folderID 自上次调用以来的变化。
+// 首次调用某目录时没有基线，所有条目以 FolderChangeCreated 返回。
+// 目录 lastRev 未变化时只发出一次单条目的列表请求。
+func (d *ChangeDetector) Changes(ctx context.Context, folderID string) ([]FolderChange, error) {
+	if d == nil || d.client == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "变化检测器未初始化", errors.New("cloud189: ChangeDetector 未初始化"))
+	}
+	prev := d.snapshot(folderID)
+	if prev != nil && prev.LastRev > 0 {
+		head, err := d.client.ListFiles(ctx, folderID, WithListPagination(1, 1))
+		if err != nil {
+			return nil, err
+		}
+		if head.LastRev == prev.LastRev {
+			return nil, nil
+		}
+	}
+
+	it := d.client.IterateFiles(ctx, folderID)
+	defer it.Close()
+	next := &FolderSnapshot{FolderID: folderID, Entries: make(map[string]SnapshotEntry)}
+	files := make(map[string]model.File)
+	for it.Next() {
+		item := it.File()
+		entry := SnapshotEntry{
+			ID:       item.ID.String(),
+			Name:     item.FileName,
+			Rev:      item.Rev.String(),
+			Size:     item.FileSize,
+			MD5:      item.MD5,
+			IsFolder: item.IsFolder,
+		}
+		next.Entries[entry.ID] = entry
+		file := item.ToModel()
+		if file.ParentID == "" {
+			file.ParentID = folderID
+		}
+		files[entry.ID] = file
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	next.LastRev = it.LastRev()
+
+	changes := diffSnapshots(prev, next, files)
+	d.mu.Lock()
+	d.snapshots[folderID] = next
+	d.mu.Unlock()
+	return changes, nil
+}
+
+// Forget 丢弃目录基线，下次 Changes 将重新全量返回。
+func (d *ChangeDetector) Forget(folderID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.snapshots, folderID)
+}
+
+// Snapshots 导出当前所有目录基线，供上层持久化。
+func (d *ChangeDetector) Snapshots() []FolderSnapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make([]FolderSnapshot, 0, len(d.snapshots))
+	for _, snap := range d.snapshots {
+		result = append(result, cloneSnapshot(snap))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].FolderID < result[j].FolderID })
+	return result
+}
+
+// LoadSnapshots 导入之前持久化的目录基线。
+func (d *ChangeDetector) LoadSnapshots(snapshots []FolderSnapshot) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range snapshots {
+		snap := cloneSnapshot(&snapshots[i])
+		d.snapshots[snap.FolderID] = &snap
+	}
+}
+
+func (d *ChangeDetector) snapshot(folderID string) *FolderSnapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.snapshots[folderID]
+}
+
+func cloneSnapshot(snap *FolderSnapshot) FolderSnapshot {
+	cp := FolderSnapshot{FolderID: snap.FolderID, LastRev: snap.LastRev, Entries: make(map[string]SnapshotEntry, len(snap.Entries))}
+	for id, entry := range snap.Entries {
+		cp.Entries[id] = entry
+	}
+	return cp
+}
+
+// diffSnapshots 按 ID 比较前后两次快照。
+func diffSnapshots(prev, next *FolderSnapshot, files map[string]model.File) []FolderChange {
+	var changes []FolderChange
+	for id, entry := range next.Entries {
+		file := files[id]
+		if prev == nil {
+			changes = append(changes, FolderChange{Kind: FolderChangeCreated, File: file})
+			continue
+		}
+		old, ok := prev.Entries[id]
+		switch {
+		case !ok:
+			changes = append(changes, FolderChange{Kind: FolderChangeCreated, File: file})
+		case old.Name != entry.Name:
+			changes = append(changes, FolderChange{Kind: FolderChangeRenamed, File: file, OldName: old.Name})
+			if old.Rev != entry.Rev || old.Size != entry.Size || old.MD5 != entry.MD5 {
+				changes = append(changes, FolderChange{Kind: FolderChangeModified, File: file})
+			}
+		case old.Rev != entry.Rev || old.Size != entry.Size || old.MD5 != entry.MD5:
+			changes = append(changes, FolderChange{Kind: FolderChangeModified, File: file})
+		}
+	}
+	if prev != nil {
+		for id, old := range prev.Entries {
+			if _, ok := next.Entries[id]; ok {
+				continue
+			}
+			changes = append(changes, FolderChange{
+				Kind: FolderChangeDeleted,
+				File: model.File{
+					ID:       old.ID,
+					ParentID: next.FolderID,
+					Name:     old.Name,
+					Size:     old.Size,
+					MD5:      old.MD5,
+					Revision: old.Rev,
+					IsFolder: old.IsFolder,
+				},
+			})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].File.Name < changes[j].File.Name
+	})
+	return changes
+}
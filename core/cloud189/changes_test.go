@@ -0,0 +1,91 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// revFolder 模拟带 lastRev 的单目录列表。
+type revFolder struct {
+	mu      sync.Mutex
+	lastRev int
+	files   []map[string]any
+	lists   int
+}
+
+func (f *revFolder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists++
+	files := f.files
+	if r.URL.Query().Get("pageSize") == "1" && len(files) > 1 {
+		files = files[:1]
+	}
+	writeJSON(w, map[string]any{
+		"res_code":   0,
+		"lastRev":    f.lastRev,
+		"fileListAO": map[string]any{"count": len(f.files), "fileList": files},
+	})
+}
+
+// TestChangeDetector_Diff 覆盖新增、修改、重命名、删除与修订号未变的快速路径。
+func TestChangeDetector_Diff(t *testing.T) {
+	folder := &revFolder{lastRev: 1, files: []map[string]any{
+		{"id": "1", "name": "a.txt", "rev": "1", "size": 1},
+		{"id": "2", "name": "b.txt", "rev": "1", "size": 2},
+		{"id": "3", "name": "c.txt", "rev": "1", "size": 3},
+	}}
+	detector := NewChangeDetector(newTestClient(t, folder))
+	ctx := context.Background()
+
+	changes, err := detector.Changes(ctx, "dir")
+	if err != nil || len(changes) != 3 {
+		t.Fatalf("首次调用应全部返回为新增，实际 %v, %v", changes, err)
+	}
+
+	folder.mu.Lock()
+	lists := folder.lists
+	folder.mu.Unlock()
+	changes, err = detector.Changes(ctx, "dir")
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("修订号未变时不应有变化，实际 %v, %v", changes, err)
+	}
+	if folder.lists != lists+1 {
+		t.Fatalf("修订号未变时只应请求一次，实际 %d", folder.lists-lists)
+	}
+
+	folder.mu.Lock()
+	folder.lastRev = 2
+	folder.files = []map[string]any{
+		{"id": "1", "name": "a.txt", "rev": "2", "size": 10},
+		{"id": "2", "name": "b-renamed.txt", "rev": "1", "size": 2},
+		{"id": "4", "name": "d.txt", "rev": "1", "size": 4},
+	}
+	folder.mu.Unlock()
+
+	changes, err = detector.Changes(ctx, "dir")
+	if err != nil {
+		t.Fatalf("检测变化失败: %v", err)
+	}
+	got := map[FolderChangeKind]string{}
+	for _, ch := range changes {
+		got[ch.Kind] = ch.File.ID
+	}
+	if len(changes) != 4 || got[FolderChangeCreated] != "4" || got[FolderChangeModified] != "1" ||
+		got[FolderChangeRenamed] != "2" || got[FolderChangeDeleted] != "3" {
+		t.Fatalf("变化结果异常: %+v", changes)
+	}
+	for _, ch := range changes {
+		if ch.Kind == FolderChangeRenamed && ch.OldName != "b.txt" {
+			t.Fatalf("重命名应记录旧名称，实际 %q", ch.OldName)
+		}
+	}
+
+	restored := NewChangeDetector(newTestClient(t, folder))
+	restored.LoadSnapshots(detector.Snapshots())
+	if changes, err := restored.Changes(ctx, "dir"); err != nil || len(changes) != 0 {
+		t.Fatalf("导入基线后不应有变化，实际 %v, %v", changes, err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/dnslin/cloud189-desktop/core/auth"
 	"github.com/dnslin/cloud189-desktop/core/httpclient"
@@ -18,6 +20,13 @@ type Client struct {
 	appBaseURL  string
 	webBaseURL  string
 	uploadBase  string
+
+	batchPollInterval time.Duration
+	batchTimeout      time.Duration
+
+	mu           sync.RWMutex
+	listeners    []changeSubscriber
+	nextListener uint64
 }
 
 // Option 自定义客户端配置。
@@ -59,6 +68,24 @@ func WithBaseURLs(app, web, upload string) Option {
 	}
 }
 
+// WithBatchPollInterval 设置批量任务状态轮询间隔。
+func WithBatchPollInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		if interval > 0 {
+			c.batchPollInterval = interval
+		}
+	}
+}
+
+// WithBatchTimeout 设置等待批量任务完成的最长时间，超时后返回错误。
+func WithBatchTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.batchTimeout = timeout
+		}
+	}
+}
+
 // NewClient 创建默认客户端。
 func NewClient(authManager *auth.AuthManager, opts ...Option) *Client {
 	cli := &Client{
@@ -68,6 +95,9 @@ func NewClient(authManager *auth.AuthManager, opts ...Option) *Client {
 		appBaseURL:  DefaultAppBaseURL,
 		webBaseURL:  DefaultWebBaseURL,
 		uploadBase:  DefaultUploadBaseURL,
+
+		batchPollInterval: DefaultBatchPollInterval,
+		batchTimeout:      DefaultBatchTimeout,
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -91,6 +121,14 @@ func (c *Client) WithAccount(accountID string) *Client {
 	return c
 }
 
+// HTTPClient 返回底层 http.Client，用于下载直链等无需签名的请求。
+func (c *Client) HTTPClient() *http.Client {
+	if c == nil || c.http == nil || c.http.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.http.HTTP
+}
+
 // AppGet 以 App 签名发送 GET。
 func (c *Client) AppGet(ctx context.Context, path string, params map[string]string, out any) error {
 	signer, err := c.prepareAppSigner(ctx)
@@ -12,5 +12,11 @@ const (
 	UserAgent     = "desktop"
 )
 
+// RootFolderID 个人云根目录 ID。
+const RootFolderID = "-11"
+
 // UploadHost 供签名逻辑判断上传域名。
 const UploadHost = "upload.cloud.189.cn"
+
+// iconOptionAll 请求时附带小/中/大三种缩略图。
+const iconOptionAll = "5"
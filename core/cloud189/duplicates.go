@@ -0,0 +1,245 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// duplicateBatchSize 处理重复文件时单次批量操作的文件数。
+const duplicateBatchSize = 100
+
+// KeepPolicy 重复文件的保留策略。
+type KeepPolicy int
+
+const (
+	// KeepOldest 保留创建时间最早的副本。
+	KeepOldest KeepPolicy = iota
+	// KeepShortestPath 保留路径最短（层级最浅）的副本。
+	KeepShortestPath
+	// KeepPreferredFolder 优先保留位于指定目录下的副本，无命中时回退为 KeepOldest。
+	KeepPreferredFolder
+)
+
+// String 返回策略名称。
+func (p KeepPolicy) String() string {
+	switch p {
+	case KeepOldest:
+		return "oldest"
+	case KeepShortestPath:
+		return "shortest_path"
+	case KeepPreferredFolder:
+		return "preferred_folder"
+	default:
+		return "unknown"
+	}
+}
+
+// ScanRoot 待扫描的子树。
+type ScanRoot struct {
+	ID   string
+	Path string
+}
+
+// DuplicateGroup 一组内容相同（MD5 与大小一致）的文件。
+type DuplicateGroup struct {
+	MD5    string
+	Size   int64
+	Keep   model.File   // 建议保留的副本
+	Extras []model.File // 建议处理的多余副本
+}
+
+// Wasted 返回多余副本占用的空间。
+func (g DuplicateGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Extras))
+}
+
+// DuplicateOption 配置重复文件扫描。
+type DuplicateOption func(*duplicateConfig)
+
+type duplicateConfig struct {
+	policy    KeepPolicy
+	preferred []string
+	minSize   int64
+	walkOpts  []WalkOption
+}
+
+// WithDuplicateKeep 设置保留策略。
+func WithDuplicateKeep(policy KeepPolicy) DuplicateOption {
+	return func(cfg *duplicateConfig) {
+		cfg.policy = policy
+	}
+}
+
+// WithDuplicatePreferredFolders 设置优先保留的目录路径（按顺序优先），并启用 KeepPreferredFolder。
+func WithDuplicatePreferredFolders(paths ...string) DuplicateOption {
+	return func(cfg *duplicateConfig) {
+		cfg.policy = KeepPreferredFolder
+		for _, p := range paths {
+			cfg.preferred = append(cfg.preferred, CleanPath(p))
+		}
+	}
+}
+
+// WithDuplicateMinSize 忽略小于 n 字节的文件，默认忽略空文件。
+func WithDuplicateMinSize(n int64) DuplicateOption {
+	return func(cfg *duplicateConfig) {
+		if n > 0 {
+			cfg.minSize = n
+		}
+	}
+}
+
+// WithDuplicateWalkOptions 透传遍历参数（并发、深度等）。
+func WithDuplicateWalkOptions(opts ...WalkOption) DuplicateOption {
+	return func(cfg *duplicateConfig) {
+		cfg.walkOpts = append(cfg.walkOpts, opts...)
+	}
+}
+
+// FindDuplicates 遍历一个或多个子树，按 MD5 与大小分组找出重复文件。
+// 结果按可释放空间倒序；子树重叠时同一文件只计一次。
+func (c *Client) FindDuplicates(ctx context.Context, roots []ScanRoot, opts ...DuplicateOption) ([]DuplicateGroup, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if len(roots) == 0 {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "扫描目录不能为空", errors.New("cloud189: roots 为空"))
+	}
+	cfg := duplicateConfig{policy: KeepOldest, minSize: 1}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	seen := make(map[string]bool)
+	buckets := make(map[string][]model.File)
+	for _, root := range roots {
+		err := c.Walk(ctx, root.ID, root.Path, func(f model.File) error {
+			if f.IsFolder || f.MD5 == "" || f.Size < cfg.minSize || seen[f.ID] {
+				return nil
+			}
+			seen[f.ID] = true
+			key := strings.ToLower(f.MD5) + ":" + strconv.FormatInt(f.Size, 10)
+			buckets[key] = append(buckets[key], f)
+			return nil
+		}, cfg.walkOpts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var groups []DuplicateGroup
+	for _, files := range buckets {
+		if len(files) < 2 {
+			continue
+		}
+		sortByKeepPolicy(files, cfg)
+		groups = append(groups, DuplicateGroup{
+			MD5:    strings.ToLower(files[0].MD5),
+			Size:   files[0].Size,
+			Keep:   files[0],
+			Extras: files[1:],
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Wasted() != groups[j].Wasted() {
+			return groups[i].Wasted() > groups[j].Wasted()
+		}
+		return groups[i].Keep.Path < groups[j].Keep.Path
+	})
+	return groups, nil
+}
+
+// sortByKeepPolicy 将最应保留的副本排在首位。
+func sortByKeepPolicy(files []model.File, cfg duplicateConfig) {
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		switch cfg.policy {
+		case KeepPreferredFolder:
+			if ra, rb := preferredRank(a.Path, cfg.preferred), preferredRank(b.Path, cfg.preferred); ra != rb {
+				return ra < rb
+			}
+		case KeepShortestPath:
+			if da, db := strings.Count(a.Path, "/"), strings.Count(b.Path, "/"); da != db {
+				return da < db
+			}
+			if len(a.Path) != len(b.Path) {
+				return len(a.Path) < len(b.Path)
+			}
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.Path < b.Path
+	})
+}
+
+// preferredRank 返回文件所在的首选目录序号，不在任何首选目录下时返回 len(preferred)。
+func preferredRank(p string, preferred []string) int {
+	for i, dir := range preferred {
+		if dir == "/" || p == dir || strings.HasPrefix(p, dir+"/") {
+			return i
+		}
+	}
+	return len(preferred)
+}
+
+// DuplicateAction 对多余副本的处理方式。
+type DuplicateAction int
+
+const (
+	// DuplicateDelete 删除（移入回收站）。
+	DuplicateDelete DuplicateAction = iota
+	// DuplicateMove 移动到指定目录。
+	DuplicateMove
+)
+
+// ResolveDuplicates 批量删除或移动各组的多余副本，保留副本不受影响。
+// action 为 DuplicateMove 时 destFolderID 必填；多余副本彼此同名或与目标目录中的文件同名时
+// 由服务端自动重命名，返回的冲突列表记录了这些被重命名的文件。
+func (c *Client) ResolveDuplicates(ctx context.Context, groups []DuplicateGroup, action DuplicateAction, destFolderID string) ([]BatchConflict, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	switch action {
+	case DuplicateDelete:
+	case DuplicateMove:
+		if destFolderID == "" {
+			return nil, WrapCloudError(ErrCodeInvalidRequest, "目标目录不能为空", errors.New("cloud189: destFolderID 为空"))
+		}
+	default:
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "不支持的处理方式", errors.New("cloud189: 未知 DuplicateAction "+strconv.Itoa(int(action))))
+	}
+	var files []FileInfo
+	for _, g := range groups {
+		for _, f := range g.Extras {
+			files = append(files, FileInfoFromModel(f))
+		}
+	}
+	var conflicts []BatchConflict
+	for start := 0; start < len(files); start += duplicateBatchSize {
+		batch := files[start:min(start+duplicateBatchSize, len(files))]
+		if action == DuplicateDelete {
+			if err := c.DeleteFiles(ctx, batch); err != nil {
+				return conflicts, err
+			}
+			continue
+		}
+		result, err := c.BatchMove(ctx, batch, destFolderID, ConflictRename)
+		if err != nil {
+			return conflicts, err
+		}
+		conflicts = append(conflicts, result.Conflicts...)
+		if result.Failed() {
+			return conflicts, WrapCloudError(ErrCodeUnknown, "移动多余副本部分失败", fmt.Errorf("cloud189: %d 个文件移动失败", result.Status.FailedCount))
+		}
+	}
+	return conflicts, nil
+}
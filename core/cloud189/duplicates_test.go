@@ -0,0 +1,156 @@
+package cloud189
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newDuplicateTree() *fakeTree {
+	tree := newFakeTree()
+	tree.children[RootFolderID] = append(tree.children[RootFolderID],
+		map[string]any{"id": "50", "name": "Backup", "isFolder": true},
+		map[string]any{"id": "51", "name": "a.jpg", "size": 7, "md5": "AAA", "createDate": "2024-05-01 00:00:00"},
+		map[string]any{"id": "52", "name": "empty.txt", "size": 0, "md5": "d41d8cd98f00b204e9800998ecf8427e"},
+	)
+	tree.children["50"] = []map[string]any{
+		{"id": "60", "name": "a.jpg", "size": 7, "md5": "aaa", "createDate": "2023-01-01 00:00:00"},
+		{"id": "61", "name": "empty.txt", "size": 0, "md5": "d41d8cd98f00b204e9800998ecf8427e"},
+		{"id": "62", "name": "b.jpg", "size": 7, "md5": "bbb"},
+	}
+	tree.children["20"] = append(tree.children["20"],
+		map[string]any{"id": "70", "name": "a-copy.jpg", "size": 7, "md5": "aaa", "createDate": "2022-01-01 00:00:00"},
+	)
+	return tree
+}
+
+// TestFindDuplicates_KeepPolicies 验证分组与三种保留策略。
+func TestFindDuplicates_KeepPolicies(t *testing.T) {
+	client := newTestClient(t, newDuplicateTree().handler())
+	ctx := context.Background()
+	roots := []ScanRoot{{ID: RootFolderID, Path: "/"}, {ID: "50", Path: "/Backup"}}
+
+	cases := []struct {
+		name string
+		opts []DuplicateOption
+		keep string
+	}{
+		{"oldest", nil, "70"},
+		{"shortest", []DuplicateOption{WithDuplicateKeep(KeepShortestPath)}, "51"},
+		{"preferred", []DuplicateOption{WithDuplicatePreferredFolders("/Backup")}, "60"},
+	}
+	for _, tc := range cases {
+		groups, err := client.FindDuplicates(ctx, roots, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: 扫描失败: %v", tc.name, err)
+		}
+		if len(groups) != 1 {
+			t.Fatalf("%s: 应只有一组重复（空文件忽略），实际 %d", tc.name, len(groups))
+		}
+		g := groups[0]
+		if g.Keep.ID != tc.keep || len(g.Extras) != 2 || g.Wasted() != 14 {
+			t.Fatalf("%s: 保留 %s，多余 %d，可释放 %d", tc.name, g.Keep.ID, len(g.Extras), g.Wasted())
+		}
+	}
+}
+
+// TestResolveDuplicates_Delete 多余副本通过删除批量任务处理。
+func TestResolveDuplicates_Delete(t *testing.T) {
+	tree := newDuplicateTree()
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.Handle("/", tree.handler())
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		var infos []batchTaskInfo
+		if r.Form.Get("type") != BatchTaskDelete || json.Unmarshal([]byte(r.Form.Get("taskInfos")), &infos) != nil {
+			t.Errorf("删除任务参数异常: %v", r.Form)
+		}
+		for _, info := range infos {
+			deleted = append(deleted, info.FileID.String())
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "t1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskStatus": batchTaskStatusDone})
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	groups, err := client.FindDuplicates(ctx, []ScanRoot{{ID: RootFolderID, Path: "/"}})
+	if err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if _, err := client.ResolveDuplicates(ctx, groups, DuplicateDelete, ""); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if strings.Join(deleted, ";") != "60;51" {
+		t.Fatalf("应删除 60;51，实际 %q", deleted)
+	}
+	if _, err := client.ResolveDuplicates(ctx, groups, DuplicateMove, ""); err == nil {
+		t.Fatalf("移动缺少目标目录时应返回错误")
+	}
+}
+
+// TestResolveDuplicates_MoveRenamesConflicts 同名的多余副本移入同一目录时按重命名处理并返回冲突。
+func TestResolveDuplicates_MoveRenamesConflicts(t *testing.T) {
+	tree := newDuplicateTree()
+	var (
+		mu       sync.Mutex
+		resolved bool
+		dealWay  int
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/", tree.handler())
+	mux.HandleFunc("/batch/createBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("type") != BatchTaskMove || r.Form.Get("targetFolderId") != "99" {
+			t.Errorf("移动任务参数异常: %v", r.Form)
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "taskId": "t1"})
+	})
+	mux.HandleFunc("/batch/checkBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !resolved {
+			writeJSON(w, map[string]any{"res_code": 0, "taskStatus": batchTaskStatusConflict})
+			return
+		}
+		writeJSON(w, map[string]any{"res_code": 0, "taskStatus": batchTaskStatusDone, "successedCount": 2})
+	})
+	mux.HandleFunc("/batch/getConflictTaskInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"res_code": 0, "taskInfos": []map[string]any{{"fileId": "51", "fileName": "a.jpg", "isFolder": 0}}})
+	})
+	mux.HandleFunc("/batch/manageBatchTask.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		var infos []batchTaskInfo
+		_ = json.Unmarshal([]byte(r.Form.Get("taskInfos")), &infos)
+		mu.Lock()
+		resolved = true
+		if len(infos) == 1 {
+			dealWay = infos[0].DealWay
+		}
+		mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	groups, err := client.FindDuplicates(ctx, []ScanRoot{{ID: RootFolderID, Path: "/"}})
+	if err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	conflicts, err := client.ResolveDuplicates(ctx, groups, DuplicateMove, "99")
+	if err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	if dealWay != ConflictRename.dealWay() {
+		t.Fatalf("同名副本应重命名，dealWay=%d", dealWay)
+	}
+	if len(conflicts) != 1 || conflicts[0].File.ID != "51" || conflicts[0].Policy != ConflictRename {
+		t.Fatalf("应返回被重命名的副本，实际 %+v", conflicts)
+	}
+}
@@ -98,6 +98,7 @@ func mapErrCode(ec *httpclient.ErrCode) int {
 		strings.Contains(upper, "PERMISSION"):
 		return ErrCodeForbidden
 	case strings.Contains(upper, "NOT_FOUND"),
+		strings.Contains(upper, "NOTFOUND"),
 		strings.Contains(upper, "NOTEXIST"),
 		strings.Contains(upper, "NOT_EXIST"):
 		return ErrCodeFileNotFound
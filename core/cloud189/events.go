@@ -0,0 +1,98 @@
+package cloud189
+
+// ChangeOp 标识客户端发起的写操作类型。
+type ChangeOp int
+
+const (
+	// ChangeCreate 创建文件夹。
+	ChangeCreate ChangeOp = iota
+	// ChangeRename 重命名。
+	ChangeRename
+	// ChangeMove 移动。
+	ChangeMove
+	// ChangeCopy 复制。
+	ChangeCopy
+	// ChangeDelete 删除（移入回收站）。
+	ChangeDelete
+	// ChangeStar 收藏状态变化。
+	ChangeStar
+	// ChangeUpload 上传提交完成。
+	ChangeUpload
+	// ChangeRestore 从回收站还原到原位置。
+	ChangeRestore
+)
+
+// String 返回操作类型的字符串表示。
+func (o ChangeOp) String() string {
+	switch o {
+	case ChangeCreate:
+		return "create"
+	case ChangeRename:
+		return "rename"
+	case ChangeMove:
+		return "move"
+	case ChangeCopy:
+		return "copy"
+	case ChangeDelete:
+		return "delete"
+	case ChangeStar:
+		return "star"
+	case ChangeUpload:
+		return "upload"
+	case ChangeRestore:
+		return "restore"
+	default:
+		return "unknown"
+	}
+}
+
+// ChangeEvent 描述一次成功的写操作，供缓存层失效使用。
+type ChangeEvent struct {
+	Op       ChangeOp
+	FamilyID string   // 家庭云 ID，为空表示个人云
+	FileIDs  []string // 被创建、修改、移动、复制或删除的文件
+	ParentID string   // 新内容出现的目录（创建、上传、复制/移动目标、还原位置）
+	Name     string   // 新名称（创建、重命名、上传）
+}
+
+// ChangeListener 接收写操作事件，回调在请求 goroutine 中同步执行。
+type ChangeListener func(ChangeEvent)
+
+type changeSubscriber struct {
+	id       uint64
+	listener ChangeListener
+}
+
+// SubscribeChanges 订阅本客户端发起的写操作，返回的函数用于取消订阅（可重复调用）。
+func (c *Client) SubscribeChanges(listener ChangeListener) (unsubscribe func()) {
+	if c == nil || listener == nil {
+		return func() {}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextListener++
+	id := c.nextListener
+	c.listeners = append(c.listeners, changeSubscriber{id: id, listener: listener})
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, sub := range c.listeners {
+			if sub.id == id {
+				c.listeners = append(c.listeners[:i:i], c.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notifyChange 通知所有订阅者。
+func (c *Client) notifyChange(evt ChangeEvent) {
+	c.mu.RLock()
+	subs := make([]changeSubscriber, len(c.listeners))
+	copy(subs, c.listeners)
+	c.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.listener(evt)
+	}
+}
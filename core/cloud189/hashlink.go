@@ -0,0 +1,250 @@
+package cloud189
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// HashManifestVersion 当前秒传清单格式版本。
+const HashManifestVersion = 1
+
+// HashLink 秒传所需的文件特征，Path 为相对清单根目录的路径。
+type HashLink struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	MD5      string `json:"md5"`
+	SliceMD5 string `json:"sliceMd5,omitempty"`
+}
+
+// Name 返回文件名。
+func (l HashLink) Name() string {
+	return path.Base(CleanPath(l.Path))
+}
+
+// Validate 校验特征是否足以秒传。
+func (l HashLink) Validate() error {
+	if l.Path == "" || l.Name() == "/" {
+		return WrapCloudError(ErrCodeInvalidRequest, "路径不能为空", errors.New("cloud189: HashLink 路径为空"))
+	}
+	if l.Size < 0 || !isHexMD5(l.MD5) {
+		return WrapCloudError(ErrCodeInvalidRequest, "文件特征无效", errors.New("cloud189: "+l.Path+" 的 size 或 md5 非法"))
+	}
+	if l.sliceMD5() == "" {
+		return WrapCloudError(ErrCodeInvalidRequest, "缺少分片 MD5", errors.New("cloud189: "+l.Path+" 超过分片大小但缺少 sliceMd5"))
+	}
+	return nil
+}
+
+// sliceMD5 返回分片 MD5，单分片文件的分片 MD5 即文件 MD5。
+func (l HashLink) sliceMD5() string {
+	if l.SliceMD5 != "" {
+		return strings.ToLower(l.SliceMD5)
+	}
+	if l.Size <= DefaultSliceSize {
+		return strings.ToLower(l.MD5)
+	}
+	return ""
+}
+
+// HashManifest 秒传清单。
+type HashManifest struct {
+	Version int        `json:"version"`
+	Links   []HashLink `json:"links"`
+}
+
+// WriteHashManifest 以 JSON 输出秒传清单。
+func WriteHashManifest(w io.Writer, links []HashLink) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(HashManifest{Version: HashManifestVersion, Links: links})
+}
+
+// ReadHashManifest 读取秒传清单。
+func ReadHashManifest(r io.Reader) ([]HashLink, error) {
+	var manifest HashManifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "秒传清单格式错误", err)
+	}
+	if manifest.Version > HashManifestVersion {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "不支持的秒传清单版本", errors.New("cloud189: 清单版本 "+strconv.Itoa(manifest.Version)))
+	}
+	return manifest.Links, nil
+}
+
+// ComputeHashLink 读取本地数据计算秒传特征，分片规则与上传一致。
+func ComputeHashLink(name string, r io.Reader) (HashLink, error) {
+	fileHash := md5.New()
+	var parts []string
+	buf := make([]byte, DefaultSliceSize)
+	var size int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			fileHash.Write(buf[:n])
+			sum := md5.Sum(buf[:n])
+			parts = append(parts, strings.ToUpper(hex.EncodeToString(sum[:])))
+			size += int64(n)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return HashLink{}, err
+		}
+	}
+	link := HashLink{Path: name, Size: size, MD5: hex.EncodeToString(fileHash.Sum(nil))}
+	if len(parts) > 1 {
+		sum := md5.Sum([]byte(strings.Join(parts, "\n")))
+		link.SliceMD5 = hex.EncodeToString(sum[:])
+	} else {
+		link.SliceMD5 = link.MD5
+	}
+	return link, nil
+}
+
+// RapidUpload 仅凭文件名、大小、MD5 与分片 MD5 在 parentID 下创建文件，不上传任何数据。
+// 云端没有相同内容时返回 ErrCodeFileNotFound。
+func (c *Client) RapidUpload(ctx context.Context, parentID, filename string, size int64, fileMD5, sliceMD5 string) (*FileInfo, error) {
+	return c.rapidUpload(ctx, "", parentID, filename, size, fileMD5, sliceMD5)
+}
+
+// RapidFamilyUpload 家庭云秒传。
+func (c *Client) RapidFamilyUpload(ctx context.Context, familyID, parentID, filename string, size int64, fileMD5, sliceMD5 string) (*FileInfo, error) {
+	if familyID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "familyID 不能为空", errors.New("cloud189: familyID 为空"))
+	}
+	return c.rapidUpload(ctx, familyID, parentID, filename, size, fileMD5, sliceMD5)
+}
+
+func (c *Client) rapidUpload(ctx context.Context, familyID, parentID, filename string, size int64, fileMD5, sliceMD5 string) (*FileInfo, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	link := HashLink{Path: filename, Size: size, MD5: fileMD5, SliceMD5: sliceMD5}
+	if err := link.Validate(); err != nil {
+		return nil, err
+	}
+	if parentID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "parentID 不能为空", errors.New("cloud189: parentID 为空"))
+	}
+	params := url.Values{}
+	params.Set("parentFolderId", parentID)
+	params.Set("fileName", filename)
+	params.Set("fileSize", strconv.FormatInt(size, 10))
+	params.Set("sliceSize", strconv.Itoa(DefaultSliceSize))
+	params.Set("fileMd5", strings.ToLower(fileMD5))
+	params.Set("sliceMd5", link.sliceMD5())
+	params.Set("extend", `{"opScene":"1","relativepath":"","rootfolderid":""}`)
+	if familyID != "" {
+		params.Set("familyId", familyID)
+	}
+
+	var rsp UploadInitResponse
+	if err := c.AppUpload(ctx, uploadPrefix(familyID)+"/initMultiUpload", params, &rsp); err != nil {
+		return nil, err
+	}
+	if rsp.Data.UploadFileID == "" {
+		return nil, WrapCloudError(ErrCodeUnknown, "获取 uploadFileId 失败", errors.New("cloud189: uploadFileId 缺失"))
+	}
+	if !rsp.Data.Exists() {
+		return nil, NewCloudError(ErrCodeFileNotFound, "云端不存在相同内容，无法秒传")
+	}
+	info, err := c.CommitUpload(ctx, &UploadSession{
+		UploadInitData: rsp.Data,
+		FamilyID:       familyID,
+		ParentID:       parentID,
+		FileName:       filename,
+		FileSize:       size,
+		SliceSize:      DefaultSliceSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if info.FileName == "" {
+		info.FileName = filename
+	}
+	if info.FileSize == 0 {
+		info.FileSize = size
+	}
+	info.ParentID = FlexString(parentID)
+	return info, nil
+}
+
+// ExportHashLinks 遍历 rootID 子树导出秒传特征，路径相对于 rootPath。
+// 远端不提供分片 MD5，超过单分片大小的文件无法生成可导入的特征，
+// 这些文件不写入清单而是通过 skipped 返回，调用方可下载后用 ComputeHashLink 补全。
+func (c *Client) ExportHashLinks(ctx context.Context, rootID, rootPath string, opts ...WalkOption) (links []HashLink, skipped []model.File, err error) {
+	rootPath = CleanPath(rootPath)
+	err = c.Walk(ctx, rootID, rootPath, func(f model.File) error {
+		if f.IsFolder || f.MD5 == "" {
+			return nil
+		}
+		link := HashLink{
+			Path: strings.TrimPrefix(strings.TrimPrefix(f.Path, rootPath), "/"),
+			Size: f.Size,
+			MD5:  strings.ToLower(f.MD5),
+		}
+		if link.SliceMD5 = link.sliceMD5(); link.SliceMD5 == "" {
+			skipped = append(skipped, f)
+			return nil
+		}
+		links = append(links, link)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return links, skipped, nil
+}
+
+// HashImportResult 单条秒传导入结果。
+type HashImportResult struct {
+	Link HashLink
+	File *FileInfo
+	Err  error
+}
+
+// ImportHashLinks 在 destPath 下按清单重建目录树并逐个秒传，单个文件失败不影响其余文件。
+// resolver 用于创建并缓存中间目录，可在多次导入间复用。
+func (c *Client) ImportHashLinks(ctx context.Context, resolver *PathResolver, destPath string, links []HashLink) ([]HashImportResult, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if resolver == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "路径解析器未初始化", errors.New("cloud189: PathResolver 为空"))
+	}
+	results := make([]HashImportResult, 0, len(links))
+	for _, link := range links {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := HashImportResult{Link: link}
+		if result.Err = link.Validate(); result.Err == nil {
+			target := path.Join(CleanPath(destPath), CleanPath(link.Path))
+			var parent *FileInfo
+			if parent, result.Err = resolver.MkdirAll(ctx, path.Dir(target)); result.Err == nil {
+				result.File, result.Err = c.RapidUpload(ctx, parent.ID.String(), path.Base(target), link.Size, link.MD5, link.SliceMD5)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func isHexMD5(s string) bool {
+	if len(s) != md5.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,145 @@
+package cloud189
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dnslin/cloud189-desktop/core/crypto"
+)
+
+// decodeUploadParams 解密 AppUpload 的 params 参数，密钥与 newTestClient 的会话一致。
+func decodeUploadParams(t *testing.T, r *http.Request) url.Values {
+	t.Helper()
+	cipher, err := hex.DecodeString(r.URL.Query().Get("params"))
+	if err != nil {
+		t.Errorf("params 不是十六进制: %v", err)
+		return url.Values{}
+	}
+	plain, err := crypto.DecryptECB([]byte("0123456789abcdef"), cipher)
+	if err != nil {
+		t.Errorf("params 解密失败: %v", err)
+		return url.Values{}
+	}
+	values, err := url.ParseQuery(string(plain))
+	if err != nil {
+		t.Errorf("params 解析失败: %v", err)
+	}
+	return values
+}
+
+// TestImportHashLinks_RapidUpload 已存在内容的条目秒传成功，其余条目单独报错。
+func TestImportHashLinks_RapidUpload(t *testing.T) {
+	known, err := ComputeHashLink("docs/a.txt", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("计算特征失败: %v", err)
+	}
+	tree := newFakeTree()
+	var committed []url.Values
+	mux := http.NewServeMux()
+	mux.Handle("/", tree.handler())
+	mux.HandleFunc("/person/initMultiUpload", func(w http.ResponseWriter, r *http.Request) {
+		params := decodeUploadParams(t, r)
+		exists := 0
+		if params.Get("fileMd5") == known.MD5 && params.Get("sliceMd5") == known.MD5 {
+			exists = 1
+		}
+		writeJSON(w, map[string]any{"code": "SUCCESS", "data": map[string]any{
+			"uploadFileId":   "up-" + params.Get("fileName"),
+			"fileDataExists": exists,
+		}})
+	})
+	mux.HandleFunc("/person/commitMultiUploadFile", func(w http.ResponseWriter, r *http.Request) {
+		params := decodeUploadParams(t, r)
+		committed = append(committed, params)
+		writeJSON(w, map[string]any{"code": "SUCCESS", "file": map[string]any{"userFileId": "900", "file_name": "a.txt"}})
+	})
+	client := newTestClient(t, mux)
+
+	var buf bytes.Buffer
+	links := []HashLink{known, {Path: "b.bin", Size: 3, MD5: strings.Repeat("0", 32)}}
+	if err := WriteHashManifest(&buf, links); err != nil {
+		t.Fatalf("导出清单失败: %v", err)
+	}
+	decoded, err := ReadHashManifest(&buf)
+	if err != nil || len(decoded) != 2 {
+		t.Fatalf("读取清单失败: %v %d", err, len(decoded))
+	}
+
+	results, err := client.ImportHashLinks(context.Background(), NewPathResolver(client), "/Restore", decoded)
+	if err != nil {
+		t.Fatalf("导入失败: %v", err)
+	}
+	if results[0].Err != nil || results[0].File.ID != "900" {
+		t.Fatalf("首个文件应秒传成功: %+v", results[0])
+	}
+	if !isNotFound(results[1].Err) {
+		t.Fatalf("内容不存在时应返回 FileNotFound，实际 %v", results[1].Err)
+	}
+	if len(committed) != 1 || committed[0].Get("uploadFileId") != "up-a.txt" {
+		t.Fatalf("只应提交一次秒传: %v", committed)
+	}
+	if err := (HashLink{Path: "big", Size: DefaultSliceSize + 1, MD5: known.MD5}).Validate(); err == nil {
+		t.Fatalf("大文件缺少分片 MD5 时应校验失败")
+	}
+}
+
+// TestHashLinks_ExportImportRoundTrip 导出的清单可直接导入，缺少分片 MD5 的大文件单独返回。
+func TestHashLinks_ExportImportRoundTrip(t *testing.T) {
+	small, err := ComputeHashLink("a.txt", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("计算特征失败: %v", err)
+	}
+	tree := newFakeTree()
+	tree.children["20"] = append(tree.children["20"],
+		map[string]any{"id": "31", "name": "a.txt", "size": small.Size, "md5": strings.ToUpper(small.MD5)},
+		map[string]any{"id": "32", "name": "big.iso", "size": DefaultSliceSize + 1, "md5": strings.Repeat("a", 32)},
+	)
+	var rapid []string
+	mux := http.NewServeMux()
+	mux.Handle("/", tree.handler())
+	mux.HandleFunc("/person/initMultiUpload", func(w http.ResponseWriter, r *http.Request) {
+		params := decodeUploadParams(t, r)
+		rapid = append(rapid, params.Get("fileName")+":"+params.Get("sliceMd5"))
+		writeJSON(w, map[string]any{"code": "SUCCESS", "data": map[string]any{
+			"uploadFileId":   "up-" + params.Get("fileName"),
+			"fileDataExists": 1,
+		}})
+	})
+	mux.HandleFunc("/person/commitMultiUploadFile", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": "SUCCESS", "file": map[string]any{"userFileId": "900"}})
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	links, skipped, err := client.ExportHashLinks(ctx, "10", "/Documents")
+	if err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0].ID != "32" {
+		t.Fatalf("大文件应单独返回，实际 %+v", skipped)
+	}
+	var buf bytes.Buffer
+	if err := WriteHashManifest(&buf, links); err != nil {
+		t.Fatalf("写出清单失败: %v", err)
+	}
+	decoded, err := ReadHashManifest(&buf)
+	if err != nil {
+		t.Fatalf("读取清单失败: %v", err)
+	}
+	results, err := client.ImportHashLinks(ctx, NewPathResolver(client), "/Restore", decoded)
+	if err != nil {
+		t.Fatalf("导入失败: %v", err)
+	}
+	// report.pdf 没有 md5 不导出，清单中只剩 a.txt。
+	if len(results) != 1 || results[0].Err != nil || results[0].Link.Path != "2024/a.txt" {
+		t.Fatalf("导入结果异常: %+v", results)
+	}
+	if len(rapid) != 1 || rapid[0] != "a.txt:"+small.MD5 {
+		t.Fatalf("秒传请求异常: %v", rapid)
+	}
+}
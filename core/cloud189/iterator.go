@@ -0,0 +1,194 @@
+package cloud189
+
+import (
+	"context"
+	"strconv"
+)
+
+// defaultListPageSize 与 ListFiles 默认分页大小一致。
+const defaultListPageSize = 100
+
+type pageFetcher func(ctx context.Context, pageNum, pageSize int) (*FileListResponse, error)
+
+type pageResult struct {
+	rsp *FileListResponse
+	err error
+}
+
+// FileIterator 自动翻页的文件列表迭代器，非并发安全。
+//
+//	it := client.IterateFiles(ctx, folderID).WithPrefetch()
+//	defer it.Close()
+//	for it.Next() {
+//		file := it.File()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type FileIterator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	fetch  pageFetcher
+
+	pageSize int
+	prefetch bool
+
+	page    int
+	items   []FileInfo
+	idx     int
+	cur     FileInfo
+	err     error
+	done    bool
+	pending chan pageResult
+	total   int
+	lastRev int64
+}
+
+// IterateFiles 返回遍历文件夹全部分页的迭代器，opts 中的分页大小与过滤条件对每页生效。
+func (c *Client) IterateFiles(ctx context.Context, folderID string, opts ...ListOption) *FileIterator {
+	return newFileIterator(ctx, opts, func(ctx context.Context, pageNum, pageSize int) (*FileListResponse, error) {
+		return c.ListFiles(ctx, folderID, append(opts, WithListPagination(pageNum, pageSize))...)
+	})
+}
+
+// IterateFamilyFiles 返回遍历家庭云文件夹全部分页的迭代器。
+func (c *Client) IterateFamilyFiles(ctx context.Context, familyID, folderID string, opts ...ListOption) *FileIterator {
+	return newFileIterator(ctx, opts, func(ctx context.Context, pageNum, pageSize int) (*FileListResponse, error) {
+		return c.ListFamilyFiles(ctx, familyID, folderID, append(opts, WithListPagination(pageNum, pageSize))...)
+	})
+}
+
+// ListAllFiles 拉取文件夹的全部分页并返回所有条目。
+func (c *Client) ListAllFiles(ctx context.Context, folderID string, opts ...ListOption) ([]FileInfo, error) {
+	it := c.IterateFiles(ctx, folderID, opts...)
+	defer it.Close()
+	var items []FileInfo
+	for it.Next() {
+		items = append(items, it.File())
+	}
+	return items, it.Err()
+}
+
+func newFileIterator(ctx context.Context, opts []ListOption, fetch pageFetcher) *FileIterator {
+	params := map[string]string{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	pageSize := defaultListPageSize
+	if n, err := strconv.Atoi(params["pageSize"]); err == nil && n > 0 {
+		pageSize = n
+	}
+	startPage := 0
+	if n, err := strconv.Atoi(params["pageNum"]); err == nil && n > 1 {
+		startPage = n - 1
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &FileIterator{
+		ctx:      ctx,
+		cancel:   cancel,
+		fetch:    fetch,
+		pageSize: pageSize,
+		page:     startPage,
+	}
+}
+
+// WithPrefetch 在消费当前页时后台预取下一页。
+func (it *FileIterator) WithPrefetch() *FileIterator {
+	it.prefetch = true
+	return it
+}
+
+// Next 前进到下一个条目，没有更多条目或出错时返回 false。
+func (it *FileIterator) Next() bool {
+	for {
+		if it.err != nil {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = WrapCloudError(ErrCodeUnknown, "遍历被取消", err)
+			return false
+		}
+		if it.idx < len(it.items) {
+			it.cur = it.items[it.idx]
+			it.idx++
+			return true
+		}
+		if it.done {
+			return false
+		}
+		rsp, err := it.nextPage()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.items = rsp.Items()
+		it.idx = 0
+		if it.lastRev == 0 {
+			it.lastRev = rsp.LastRev
+		}
+		if count := rsp.FileListAO.Count; count > 0 {
+			it.total = count
+		} else if rsp.RecordCount > 0 {
+			it.total = rsp.RecordCount
+		}
+		switch {
+		case len(it.items) == 0:
+			it.done = true
+		case it.total > 0 && it.page*it.pageSize >= it.total:
+			it.done = true
+		case it.total == 0 && len(it.items) < it.pageSize:
+			it.done = true
+		case it.prefetch:
+			it.startPrefetch()
+		}
+	}
+}
+
+// File 返回当前条目。
+func (it *FileIterator) File() FileInfo {
+	return it.cur
+}
+
+// Total 返回服务端报告的条目总数，首页返回前为 0。
+func (it *FileIterator) Total() int {
+	return it.total
+}
+
+// LastRev 返回首页响应中的目录修订号。
+func (it *FileIterator) LastRev() int64 {
+	return it.lastRev
+}
+
+// Err 返回遍历过程中的错误。
+func (it *FileIterator) Err() error {
+	return it.err
+}
+
+// Close 停止遍历并取消未完成的预取。
+func (it *FileIterator) Close() {
+	it.done = true
+	it.cancel()
+}
+
+func (it *FileIterator) nextPage() (*FileListResponse, error) {
+	if it.pending != nil {
+		result := <-it.pending
+		it.pending = nil
+		return result.rsp, result.err
+	}
+	it.page++
+	return it.fetch(it.ctx, it.page, it.pageSize)
+}
+
+func (it *FileIterator) startPrefetch() {
+	it.page++
+	ch := make(chan pageResult, 1)
+	go func(ctx context.Context, page int) {
+		rsp, err := it.fetch(ctx, page, it.pageSize)
+		ch <- pageResult{rsp: rsp, err: err}
+	}(it.ctx, it.page)
+	it.pending = ch
+}
@@ -0,0 +1,77 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// pagedHandler 模拟 total 个文件的分页列表。
+func pagedHandler(total int, requests *int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		q := r.URL.Query()
+		pageNum, _ := strconv.Atoi(q.Get("pageNum"))
+		pageSize, _ := strconv.Atoi(q.Get("pageSize"))
+		var files []map[string]any
+		for i := (pageNum - 1) * pageSize; i < pageNum*pageSize && i < total; i++ {
+			files = append(files, map[string]any{"id": strconv.Itoa(i), "name": "f" + strconv.Itoa(i)})
+		}
+		writeJSON(w, map[string]any{
+			"res_code":   0,
+			"fileListAO": map[string]any{"count": total, "fileList": files},
+		})
+	})
+}
+
+// TestFileIterator_AllPages 验证跨页遍历与预取。
+func TestFileIterator_AllPages(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		var requests int32
+		client := newTestClient(t, pagedHandler(25, &requests))
+		it := client.IterateFiles(context.Background(), RootFolderID, WithListPagination(1, 10))
+		if prefetch {
+			it.WithPrefetch()
+		}
+		count := 0
+		for it.Next() {
+			if it.File().ID.String() != strconv.Itoa(count) {
+				t.Fatalf("第 %d 项顺序异常: %s", count, it.File().ID)
+			}
+			count++
+		}
+		it.Close()
+		if err := it.Err(); err != nil {
+			t.Fatalf("遍历失败: %v", err)
+		}
+		if count != 25 || it.Total() != 25 {
+			t.Fatalf("应遍历 25 项，实际 %d（total=%d）", count, it.Total())
+		}
+		if got := atomic.LoadInt32(&requests); got != 3 {
+			t.Fatalf("prefetch=%v 应请求 3 页，实际 %d", prefetch, got)
+		}
+	}
+}
+
+// TestFileIterator_CancelStops 取消上下文后迭代应停止并返回错误。
+func TestFileIterator_CancelStops(t *testing.T) {
+	var requests int32
+	client := newTestClient(t, pagedHandler(100, &requests))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := client.IterateFiles(ctx, RootFolderID, WithListPagination(1, 10))
+	defer it.Close()
+
+	seen := 0
+	for it.Next() {
+		seen++
+		if seen == 5 {
+			cancel()
+		}
+	}
+	if seen != 5 || it.Err() == nil {
+		t.Fatalf("取消后应立即停止并返回错误，实际 seen=%d err=%v", seen, it.Err())
+	}
+}
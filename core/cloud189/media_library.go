@@ -0,0 +1,186 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// MediaKind 媒体库分类，取值与服务端 mediaType 参数一致。
+type MediaKind int
+
+const (
+	// MediaPhoto 照片。
+	MediaPhoto MediaKind = 1
+	// MediaMusic 音乐。
+	MediaMusic MediaKind = 2
+	// MediaVideo 视频。
+	MediaVideo MediaKind = 3
+	// MediaDocument 文档。
+	MediaDocument MediaKind = 4
+)
+
+// String 返回分类名称。
+func (k MediaKind) String() string {
+	switch k {
+	case MediaPhoto:
+		return "photo"
+	case MediaMusic:
+		return "music"
+	case MediaVideo:
+		return "video"
+	case MediaDocument:
+		return "document"
+	default:
+		return "unknown"
+	}
+}
+
+// MediaGroup 同一月份的媒体文件，Month 为该月第一天零点。
+type MediaGroup struct {
+	Month time.Time
+	Files []model.File
+}
+
+// MediaPage 媒体库的一页结果，页内按时间倒序并按月份分组。
+// 分页沿用服务端的修改时间倒序，而分组使用拍摄/创建时间，同一月份可能分散在多页，
+// 后续页也可能出现更晚的月份；展示时间线时应使用 MergeMediaGroups 累积各页结果。
+type MediaPage struct {
+	Kind     MediaKind
+	PageNum  int
+	PageSize int
+	Total    int
+	Groups   []MediaGroup
+}
+
+// HasMore 判断是否还有下一页。
+func (p *MediaPage) HasMore() bool {
+	if p == nil || p.PageSize <= 0 {
+		return false
+	}
+	return p.PageNum*p.PageSize < p.Total
+}
+
+// ListMedia 跨目录列出指定分类的媒体文件，分类依据 mediaType，缺失时参考 fileCata。
+// 默认范围为整个个人云，可用 WithSearchFolder 限定子树，用 WithSearchPagination 翻页；
+// 页与页之间的顺序见 MediaPage。
+func (c *Client) ListMedia(ctx context.Context, kind MediaKind, opts ...SearchOption) (*MediaPage, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if kind < MediaPhoto || kind > MediaDocument {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "不支持的媒体分类", errors.New("cloud189: 媒体分类非法 "+strconv.Itoa(int(kind))))
+	}
+	params := map[string]string{
+		"folderId":   RootFolderID,
+		"filename":   "",
+		"fileType":   "1",
+		"mediaType":  strconv.Itoa(int(kind)),
+		"mediaAttr":  "0",
+		"recursive":  "1",
+		"iconOption": iconOptionAll,
+		"orderBy":    "lastOpTime",
+		"descending": "true",
+		"pageNum":    "1",
+		"pageSize":   "100",
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	var rsp SearchResponse
+	if err := c.AppGet(ctx, "/searchFiles.action", params, &rsp); err != nil {
+		return nil, err
+	}
+	files := make([]model.File, 0, len(rsp.Files))
+	for _, info := range rsp.Files {
+		// 服务端未返回任何分类信息时信任过滤参数
+		if got := mediaKindOf(info.MediaType, info.FileCategory); info.IsFolder || (got != 0 && got != kind) {
+			continue
+		}
+		files = append(files, info.ToModel())
+	}
+	pageNum, _ := strconv.Atoi(params["pageNum"])
+	pageSize, _ := strconv.Atoi(params["pageSize"])
+	return &MediaPage{
+		Kind:     kind,
+		PageNum:  pageNum,
+		PageSize: pageSize,
+		Total:    rsp.Count,
+		Groups:   GroupMediaByMonth(files),
+	}, nil
+}
+
+// mediaKindOf 返回文件的媒体分类：优先 mediaType，为空时使用取值相同的 fileCata，均未知时返回 0。
+func mediaKindOf(mediaType, category int) MediaKind {
+	for _, v := range []int{mediaType, category} {
+		if kind := MediaKind(v); kind >= MediaPhoto && kind <= MediaDocument {
+			return kind
+		}
+	}
+	return 0
+}
+
+// MediaTime 返回媒体归档使用的时间：优先拍摄时间，其次创建时间，最后修改时间。
+func MediaTime(f model.File) time.Time {
+	switch {
+	case !f.TakenAt.IsZero():
+		return f.TakenAt
+	case !f.CreatedAt.IsZero():
+		return f.CreatedAt
+	default:
+		return f.UpdatedAt
+	}
+}
+
+// GroupMediaByMonth 按 MediaTime 所在月份分组，组间与组内均按时间倒序。
+func GroupMediaByMonth(files []model.File) []MediaGroup {
+	index := make(map[time.Time]int)
+	var groups []MediaGroup
+	for _, f := range files {
+		month := monthOf(MediaTime(f))
+		i, ok := index[month]
+		if !ok {
+			i = len(groups)
+			index[month] = i
+			groups = append(groups, MediaGroup{Month: month})
+		}
+		groups[i].Files = append(groups[i].Files, f)
+	}
+	sortMediaGroups(groups)
+	return groups
+}
+
+// MergeMediaGroups 将下一页的分组追加到已有分组，跨页的同一月份会合并，
+// 合并后整体重新按时间倒序排列。
+func MergeMediaGroups(groups, next []MediaGroup) []MediaGroup {
+	var files []model.File
+	for _, g := range groups {
+		files = append(files, g.Files...)
+	}
+	for _, g := range next {
+		files = append(files, g.Files...)
+	}
+	return GroupMediaByMonth(files)
+}
+
+func monthOf(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+}
+
+func sortMediaGroups(groups []MediaGroup) {
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Month.After(groups[j].Month) })
+	for _, g := range groups {
+		files := g.Files
+		sort.SliceStable(files, func(i, j int) bool { return MediaTime(files[i]).After(MediaTime(files[j])) })
+	}
+}
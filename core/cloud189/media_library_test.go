@@ -0,0 +1,80 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// TestListMedia_GroupsByMonth 媒体文件按拍摄/创建月份倒序分组。
+func TestListMedia_GroupsByMonth(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/searchFiles.action", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("mediaType") != "1" || q.Get("recursive") != "1" || q.Get("pageNum") != "2" {
+			t.Errorf("查询参数不符: %s", r.URL.RawQuery)
+		}
+		writeJSON(w, map[string]any{
+			"res_code": 0,
+			"count":    250,
+			"fileList": []map[string]any{
+				{"id": 1, "name": "a.jpg", "mediaType": 1, "createDate": "2024-03-02 10:00:00", "shootTime": "2023-12-25 08:00:00"},
+				{"id": 2, "name": "b.jpg", "mediaType": 1, "createDate": "2024-03-05 10:00:00"},
+				{"id": 3, "name": "c.mp4", "mediaType": 3, "createDate": "2024-03-06 10:00:00"},
+				{"id": 4, "name": "d.jpg", "mediaType": 1, "createDate": "2024-03-03 12:00:00"},
+				{"id": 5, "name": "e.mov", "fileCata": 3, "createDate": "2024-03-07 10:00:00"},
+				{"id": 6, "name": "f.heic", "fileCata": 1, "createDate": "2024-03-04 10:00:00"},
+			},
+		})
+	})
+	client := newTestClient(t, mux)
+
+	page, err := client.ListMedia(context.Background(), MediaPhoto, WithSearchPagination(2, 100))
+	if err != nil {
+		t.Fatalf("列出媒体失败: %v", err)
+	}
+	if !page.HasMore() {
+		t.Fatalf("第 2 页之后应还有数据")
+	}
+	if len(page.Groups) != 2 {
+		t.Fatalf("应分为 2 个月份，实际 %d", len(page.Groups))
+	}
+	march := page.Groups[0]
+	if march.Month.Month() != time.March || len(march.Files) != 3 {
+		t.Fatalf("第一组应为 3 月三张照片（含仅有 fileCata 的条目），实际 %v %d", march.Month, len(march.Files))
+	}
+	if march.Files[0].ID != "2" || march.Files[1].ID != "6" || march.Files[2].ID != "4" {
+		t.Fatalf("组内应按时间倒序，实际 %s,%s,%s", march.Files[0].ID, march.Files[1].ID, march.Files[2].ID)
+	}
+	if dec := page.Groups[1]; dec.Month.Year() != 2023 || dec.Files[0].ID != "1" {
+		t.Fatalf("拍摄时间应优先于创建时间，实际 %v", dec.Month)
+	}
+
+	merged := MergeMediaGroups(page.Groups, page.Groups[:1])
+	if len(merged) != 2 || len(merged[0].Files) != 6 {
+		t.Fatalf("跨页同月应合并，实际 %d 组", len(merged))
+	}
+}
+
+// TestMergeMediaGroups_ReordersAcrossPages 分页按修改时间排序，后续页出现的更晚月份合并后应排在前面。
+func TestMergeMediaGroups_ReordersAcrossPages(t *testing.T) {
+	at := func(month time.Month) time.Time { return time.Date(2024, month, 10, 0, 0, 0, 0, time.Local) }
+	first := GroupMediaByMonth([]model.File{{ID: "1", TakenAt: at(time.March)}, {ID: "2", TakenAt: at(time.January)}})
+	next := GroupMediaByMonth([]model.File{{ID: "3", TakenAt: at(time.May)}, {ID: "4", TakenAt: at(time.January).Add(time.Hour)}})
+
+	merged := MergeMediaGroups(first, next)
+	var got []string
+	for _, g := range merged {
+		for _, f := range g.Files {
+			got = append(got, g.Month.Month().String()+":"+f.ID)
+		}
+	}
+	want := []string{"May:3", "March:1", "January:4", "January:2"}
+	if len(merged) != 3 || strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("合并结果异常: %v", got)
+	}
+}
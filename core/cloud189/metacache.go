@@ -0,0 +1,413 @@
+package cloud189
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+// DefaultMetaCacheTTL 元数据缓存默认有效期。
+const DefaultMetaCacheTTL = 5 * time.Minute
+
+// MetaCache 个人云 ListFiles 与 GetFileInfo 的元数据缓存。
+// 有效期内的请求直接返回缓存；本客户端的创建、重命名、移动、复制、删除与上传提交会失效相关条目。
+// 持久化由注入的 store.MetaCacheStore 负责，冷启动时可通过 CachedList 立即展示上次的目录树。
+type MetaCache struct {
+	client      *Client
+	store       store.MetaCacheStore
+	ttl         time.Duration
+	now         func() time.Time
+	unsubscribe func()
+
+	mu      sync.Mutex
+	gen     uint64            // 每次失效递增，避免失效前发出的请求回写旧数据
+	parents map[string]string // 文件 ID 到父目录 ID，用于重命名、删除时定位父目录列表
+}
+
+// MetaCacheOption 配置 MetaCache。
+type MetaCacheOption func(*MetaCache)
+
+// WithMetaCacheTTL 设置缓存有效期。
+func WithMetaCacheTTL(ttl time.Duration) MetaCacheOption {
+	return func(m *MetaCache) {
+		if ttl > 0 {
+			m.ttl = ttl
+		}
+	}
+}
+
+// listRecord 一个目录的全部列表缓存，按查询参数区分分页与排序。
+type listRecord struct {
+	Pages map[string]cachedList `json:"pages"`
+}
+
+type cachedList struct {
+	FetchedAt time.Time        `json:"fetchedAt"`
+	Response  FileListResponse `json:"response"`
+}
+
+type cachedInfo struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	Info      FileInfo  `json:"info"`
+}
+
+// NewMetaCache 创建元数据缓存，不再使用时调用 Close 取消变更订阅。
+func NewMetaCache(client *Client, st store.MetaCacheStore, opts ...MetaCacheOption) *MetaCache {
+	m := &MetaCache{
+		client:  client,
+		store:   st,
+		ttl:     DefaultMetaCacheTTL,
+		now:     time.Now,
+		parents: make(map[string]string),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(m)
+		}
+	}
+	m.unsubscribe = client.SubscribeChanges(m.handleChange)
+	return m
+}
+
+// Close 取消对客户端写操作的订阅，不会清除已持久化的缓存。
+func (m *MetaCache) Close() error {
+	if m != nil && m.unsubscribe != nil {
+		m.unsubscribe()
+	}
+	return nil
+}
+
+// ListFiles 返回目录列表，缓存有效时不访问网络。
+func (m *MetaCache) ListFiles(ctx context.Context, folderID string, opts ...ListOption) (*FileListResponse, error) {
+	if m == nil || m.client == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "元数据缓存未初始化", errors.New("cloud189: MetaCache 未初始化"))
+	}
+	if folderID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "folderID 不能为空", errors.New("cloud189: folderID 为空"))
+	}
+	variant := listVariant(opts)
+	rec, err := m.loadList(folderID)
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := rec.Pages[variant]; ok && m.fresh(entry.FetchedAt) {
+		m.rememberParents(folderID, entry.Response.Items())
+		return &entry.Response, nil
+	}
+
+	gen := m.generation()
+	rsp, err := m.client.ListFiles(ctx, folderID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.rememberParents(folderID, rsp.Items())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.gen != gen {
+		return rsp, nil
+	}
+	// 重新读取，避免覆盖并发写入的其他参数组合。
+	if rec, err = m.loadList(folderID); err != nil {
+		return nil, err
+	}
+	rec.Pages[variant] = cachedList{FetchedAt: m.now(), Response: *rsp}
+	if err := m.save(listKey(folderID), rec); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// GetFileInfo 返回文件信息，缓存有效时不访问网络。
+func (m *MetaCache) GetFileInfo(ctx context.Context, fileID string) (*FileInfo, error) {
+	if m == nil || m.client == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "元数据缓存未初始化", errors.New("cloud189: MetaCache 未初始化"))
+	}
+	if fileID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "fileID 不能为空", errors.New("cloud189: fileID 为空"))
+	}
+	entry, ok, err := m.loadInfo(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if ok && m.fresh(entry.FetchedAt) {
+		m.rememberParent(fileID, entry.Info.ParentID.String())
+		return &entry.Info, nil
+	}
+
+	gen := m.generation()
+	info, err := m.client.GetFileInfo(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	m.rememberParent(fileID, info.ParentID.String())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.gen != gen {
+		return info, nil
+	}
+	if err := m.save(infoKey(fileID), cachedInfo{FetchedAt: m.now(), Info: *info}); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// CachedList 返回缓存中的目录列表及其获取时间，忽略有效期，不访问网络。
+func (m *MetaCache) CachedList(folderID string, opts ...ListOption) (*FileListResponse, time.Time, bool) {
+	if m == nil || m.store == nil {
+		return nil, time.Time{}, false
+	}
+	rec, err := m.loadList(folderID)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	entry, ok := rec.Pages[listVariant(opts)]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	m.rememberParents(folderID, entry.Response.Items())
+	return &entry.Response, entry.FetchedAt, true
+}
+
+// CachedFileInfo 返回缓存中的文件信息及其获取时间，忽略有效期，不访问网络。
+func (m *MetaCache) CachedFileInfo(fileID string) (*FileInfo, time.Time, bool) {
+	if m == nil || m.store == nil {
+		return nil, time.Time{}, false
+	}
+	entry, ok, err := m.loadInfo(fileID)
+	if err != nil || !ok {
+		return nil, time.Time{}, false
+	}
+	return &entry.Info, entry.FetchedAt, true
+}
+
+// InvalidateFolder 删除目录的列表缓存。
+func (m *MetaCache) InvalidateFolder(folderID string) error {
+	if m == nil || m.store == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gen++
+	return m.store.DeleteMeta(listKey(folderID))
+}
+
+// InvalidateFile 删除文件信息缓存及其所在目录的列表缓存。
+func (m *MetaCache) InvalidateFile(fileID string) error {
+	if m == nil || m.store == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gen++
+	return m.dropFileLocked(fileID)
+}
+
+// Clear 清空全部缓存。
+func (m *MetaCache) Clear() error {
+	if m == nil || m.store == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gen++
+	m.parents = make(map[string]string)
+	return m.store.ClearMeta()
+}
+
+// handleChange 根据客户端写操作失效缓存，家庭云操作不影响个人云缓存。
+func (m *MetaCache) handleChange(evt ChangeEvent) {
+	if evt.FamilyID != "" || m.store == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gen++
+	var errs []error
+	switch evt.Op {
+	case ChangeRename, ChangeMove, ChangeDelete, ChangeStar:
+		// 原父目录列表中的条目已变化。
+		for _, id := range evt.FileIDs {
+			errs = append(errs, m.dropFileLocked(id))
+			if evt.Op == ChangeDelete {
+				errs = append(errs, m.dropTreeLocked(id))
+			}
+		}
+	default:
+		// 创建、复制、上传产生的是新条目，只需失效自身与目标目录。
+		for _, id := range evt.FileIDs {
+			errs = append(errs, m.store.DeleteMeta(infoKey(id)))
+		}
+	}
+	if evt.ParentID != "" {
+		errs = append(errs, m.store.DeleteMeta(listKey(evt.ParentID)))
+	}
+	// 存储本身出错时无法确认哪些条目已失效，宁可清空也不展示过期数据。
+	if errors.Join(errs...) != nil {
+		m.parents = make(map[string]string)
+		_ = m.store.ClearMeta()
+	}
+}
+
+// dropFileLocked 删除文件信息及父目录列表缓存。父目录未知时（如冷启动后尚未列过该目录）
+// 只删除文件信息，原父目录列表在有效期结束后自然刷新。
+func (m *MetaCache) dropFileLocked(fileID string) error {
+	parent, ok := m.parents[fileID]
+	if !ok {
+		if entry, found, err := m.loadInfo(fileID); err == nil && found && entry.Info.ParentID != "" {
+			parent, ok = entry.Info.ParentID.String(), true
+		}
+	}
+	if err := m.store.DeleteMeta(infoKey(fileID)); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	delete(m.parents, fileID)
+	return m.store.DeleteMeta(listKey(parent))
+}
+
+// dropTreeLocked 删除已删除文件夹自身及其全部已缓存后代的列表与文件信息。
+// 子项来自内存中的父目录映射与持久化的列表，两者都没有记录的后代不在缓存中，无需处理。
+func (m *MetaCache) dropTreeLocked(folderID string) error {
+	children := make(map[string][]string)
+	for id, parent := range m.parents {
+		children[parent] = append(children[parent], id)
+	}
+	var errs []error
+	seen := map[string]bool{folderID: true}
+	queue := []string{folderID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		kids := children[id]
+		rec, err := m.loadList(id)
+		errs = append(errs, err)
+		for _, page := range rec.Pages {
+			for _, item := range page.Response.Items() {
+				kids = append(kids, item.ID.String())
+			}
+		}
+		errs = append(errs, m.store.DeleteMeta(listKey(id)))
+		for _, kid := range kids {
+			if kid == "" || seen[kid] {
+				continue
+			}
+			seen[kid] = true
+			delete(m.parents, kid)
+			errs = append(errs, m.store.DeleteMeta(infoKey(kid)))
+			queue = append(queue, kid)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MetaCache) fresh(fetchedAt time.Time) bool {
+	return m.now().Sub(fetchedAt) < m.ttl
+}
+
+func (m *MetaCache) generation() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gen
+}
+
+func (m *MetaCache) rememberParents(folderID string, items []FileInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, item := range items {
+		if id := item.ID.String(); id != "" {
+			m.parents[id] = folderID
+		}
+	}
+}
+
+func (m *MetaCache) rememberParent(fileID, parentID string) {
+	if parentID == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parents[fileID] = parentID
+}
+
+// loadList 读取目录缓存，未命中或内容损坏时返回空记录。
+func (m *MetaCache) loadList(folderID string) (listRecord, error) {
+	rec := listRecord{Pages: make(map[string]cachedList)}
+	if m.store == nil {
+		return rec, nil
+	}
+	data, err := m.store.LoadMeta(listKey(folderID))
+	if err != nil || data == nil {
+		return rec, err
+	}
+	if json.Unmarshal(data, &rec) != nil || rec.Pages == nil {
+		return listRecord{Pages: make(map[string]cachedList)}, nil
+	}
+	return rec, nil
+}
+
+func (m *MetaCache) loadInfo(fileID string) (cachedInfo, bool, error) {
+	var entry cachedInfo
+	if m.store == nil {
+		return entry, false, nil
+	}
+	data, err := m.store.LoadMeta(infoKey(fileID))
+	if err != nil || data == nil {
+		return entry, false, err
+	}
+	if json.Unmarshal(data, &entry) != nil {
+		return cachedInfo{}, false, nil
+	}
+	return entry, true, nil
+}
+
+func (m *MetaCache) save(key string, v any) error {
+	if m.store == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return WrapCloudError(ErrCodeUnknown, "序列化元数据缓存失败", err)
+	}
+	return m.store.SaveMeta(key, data)
+}
+
+// listVariant 将列表参数规范化为缓存子键，默认参数为空字符串。
+func listVariant(opts []ListOption) string {
+	params := map[string]string{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(params)
+		}
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(k + "=" + params[k])
+	}
+	return b.String()
+}
+
+func listKey(folderID string) string {
+	return "list_" + sanitizeKeyPart(folderID)
+}
+
+func infoKey(fileID string) string {
+	return "info_" + sanitizeKeyPart(fileID)
+}
@@ -0,0 +1,189 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryMetaStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryMetaStore) LoadMeta(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *memoryMetaStore) SaveMeta(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		s.data = make(map[string][]byte)
+	}
+	s.data[key] = data
+	return nil
+}
+
+func (s *memoryMetaStore) DeleteMeta(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memoryMetaStore) ClearMeta() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = nil
+	return nil
+}
+
+// newMetaCacheTest 在 fakeTree 基础上补充 getFileInfo 与上传提交接口。
+func newMetaCacheTest(t *testing.T, st *memoryMetaStore) (*Client, *MetaCache, *fakeTree, *int) {
+	t.Helper()
+	tree := newFakeTree()
+	infos := new(int)
+	mux := http.NewServeMux()
+	mux.Handle("/", tree.handler())
+	mux.HandleFunc("/getFileInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		tree.mu.Lock()
+		*infos++
+		tree.mu.Unlock()
+		writeJSON(w, map[string]any{"res_code": 0, "id": "30", "name": "report.pdf", "parentId": "20", "size": 42,
+			"lastOpTime": "2024-05-01 10:00:00"})
+	})
+	mux.HandleFunc("/person/commitMultiUploadFile", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": "SUCCESS", "file": map[string]any{"userFileId": "31", "file_name": "new.pdf"}})
+	})
+	client := newTestClient(t, mux)
+	return client, NewMetaCache(client, st), tree, infos
+}
+
+func listNames(t *testing.T, cache *MetaCache, folderID string) []string {
+	t.Helper()
+	rsp, err := cache.ListFiles(context.Background(), folderID)
+	if err != nil {
+		t.Fatalf("列出 %s 失败: %v", folderID, err)
+	}
+	var names []string
+	for _, item := range rsp.Items() {
+		names = append(names, item.FileName)
+	}
+	return names
+}
+
+// TestMetaCache_HitAndInvalidate 缓存命中不访问网络，写操作与过期后重新拉取。
+func TestMetaCache_HitAndInvalidate(t *testing.T) {
+	ctx := context.Background()
+	client, cache, tree, infos := newMetaCacheTest(t, &memoryMetaStore{})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	listNames(t, cache, RootFolderID)
+	if names := listNames(t, cache, RootFolderID); tree.lists != 1 || names[0] != "Documents" {
+		t.Fatalf("第二次列出应命中缓存: lists=%d names=%v", tree.lists, names)
+	}
+	if err := client.RenameFile(ctx, "10", "Docs"); err != nil {
+		t.Fatalf("重命名失败: %v", err)
+	}
+	if names := listNames(t, cache, RootFolderID); tree.lists != 2 || names[0] != "Docs" {
+		t.Fatalf("重命名后应重新拉取父目录: lists=%d names=%v", tree.lists, names)
+	}
+
+	listNames(t, cache, "20")
+	session := &UploadSession{ParentID: "20", FileName: "new.pdf"}
+	session.UploadFileID = "up-1"
+	if _, err := client.CommitUpload(ctx, session); err != nil {
+		t.Fatalf("提交上传失败: %v", err)
+	}
+	listNames(t, cache, "20")
+	if tree.lists != 4 {
+		t.Fatalf("上传完成后应重新拉取目标目录: lists=%d", tree.lists)
+	}
+
+	for i := 0; i < 2; i++ {
+		info, err := cache.GetFileInfo(ctx, "30")
+		if err != nil || info.FileName != "report.pdf" || info.LastOpTime.IsZero() {
+			t.Fatalf("获取文件信息失败: %v %+v", err, info)
+		}
+	}
+	if *infos != 1 {
+		t.Fatalf("文件信息应命中缓存: %d", *infos)
+	}
+
+	now = now.Add(DefaultMetaCacheTTL)
+	listNames(t, cache, RootFolderID)
+	if tree.lists != 5 {
+		t.Fatalf("过期后应重新拉取: lists=%d", tree.lists)
+	}
+}
+
+// TestMetaCache_ColdStart 新实例可直接读取持久化的目录树，未知父目录的写操作只失效受影响的条目。
+func TestMetaCache_ColdStart(t *testing.T) {
+	st := &memoryMetaStore{}
+	_, warm, _, _ := newMetaCacheTest(t, st)
+	listNames(t, warm, RootFolderID)
+	listNames(t, warm, "10")
+
+	client, cold, tree, _ := newMetaCacheTest(t, st)
+	rsp, fetched, ok := cold.CachedList("10")
+	if !ok || fetched.IsZero() || len(rsp.Items()) != 1 || rsp.Items()[0].FileName != "2024" || !rsp.Items()[0].IsFolder {
+		t.Fatalf("冷启动应读取到上次的列表: %v %+v", ok, rsp)
+	}
+	if names := listNames(t, cold, "10"); tree.lists != 0 || names[0] != "2024" {
+		t.Fatalf("有效期内冷启动不应访问网络: lists=%d", tree.lists)
+	}
+
+	// 新实例未见过 30 的父目录，只失效其文件信息，其余目录缓存保留。
+	if err := st.SaveMeta(infoKey("30"), []byte("{}")); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if err := client.RenameFile(context.Background(), "30", "final.pdf"); err != nil {
+		t.Fatalf("重命名失败: %v", err)
+	}
+	if data, _ := st.LoadMeta(infoKey("30")); data != nil {
+		t.Fatalf("文件信息缓存应失效")
+	}
+	if _, _, ok := cold.CachedList(RootFolderID); !ok {
+		t.Fatalf("父目录未知时不应清空无关目录的缓存")
+	}
+	// 事件携带的目录仍会失效。
+	client.notifyChange(ChangeEvent{Op: ChangeMove, FileIDs: []string{"31"}, ParentID: "10"})
+	if _, _, ok := cold.CachedList("10"); ok {
+		t.Fatalf("事件中的目标目录应失效")
+	}
+}
+
+// TestMetaCache_DeleteDropsDescendants 删除文件夹时失效其下全部已缓存的列表与文件信息，冷启动实例依据持久化的列表同样生效。
+func TestMetaCache_DeleteDropsDescendants(t *testing.T) {
+	ctx := context.Background()
+	st := &memoryMetaStore{}
+	for _, fresh := range []bool{false, true} {
+		client, cache, _, _ := newMetaCacheTest(t, st)
+		listNames(t, cache, RootFolderID)
+		listNames(t, cache, "10")
+		listNames(t, cache, "20")
+		if _, err := cache.GetFileInfo(ctx, "30"); err != nil {
+			t.Fatalf("获取文件信息失败: %v", err)
+		}
+		if fresh {
+			// 新实例没有内存中的父目录映射，只能依据持久化的列表定位后代。
+			client, _, _, _ = newMetaCacheTest(t, st)
+		}
+		client.notifyChange(ChangeEvent{Op: ChangeDelete, FileIDs: []string{"10"}})
+		keys := []string{listKey("10"), listKey("20"), infoKey("30")}
+		if !fresh {
+			keys = append(keys, listKey(RootFolderID))
+		}
+		for _, key := range keys {
+			if data, _ := st.LoadMeta(key); data != nil {
+				t.Fatalf("删除文件夹后 %s 应失效（冷启动=%v）", key, fresh)
+			}
+		}
+	}
+}
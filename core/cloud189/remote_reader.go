@@ -0,0 +1,353 @@
+package cloud189
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+const (
+	// DefaultReaderBlockSize 随机读取的默认块大小（1MB）。
+	DefaultReaderBlockSize = 1 << 20
+	// DefaultReaderCacheBlocks 默认缓存的块数量。
+	DefaultReaderCacheBlocks = 16
+	// DefaultReaderReadAhead 顺序读取时默认额外预读的块数量。
+	DefaultReaderReadAhead = 2
+)
+
+var (
+	_ io.ReadSeeker = (*RemoteReader)(nil)
+	_ io.ReaderAt   = (*RemoteReader)(nil)
+	_ io.Closer     = (*RemoteReader)(nil)
+)
+
+// RemoteReader 基于 HTTP Range 的远端文件随机读取器，按块缓存并在顺序读取时预读。
+// 下载链接过期（401/403/410）时自动重新获取。ReadAt 可并发调用，HTTP 请求期间不持有锁，
+// 同一块的并发读取只发起一次请求；Read/Seek 共享同一游标并彼此串行。
+type RemoteReader struct {
+	client    *Client
+	ctx       context.Context
+	fileID    string
+	size      int64
+	blockSize int64
+	maxBlocks int
+	readAhead int
+
+	cursorMu sync.Mutex // 串行化 Read/Seek，保证游标更新的原子性
+	offset   int64
+
+	mu        sync.Mutex
+	url       string
+	lastBlock int64
+	lru       *list.List
+	blocks    map[int64]*list.Element
+	inflight  map[int64]*blockFetch
+	closed    bool
+}
+
+type cachedBlock struct {
+	index int64
+	data  []byte
+}
+
+// blockFetch 一次进行中的 Range 请求，覆盖从 index 起的 count 个块。
+type blockFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// ReaderOption 配置 RemoteReader。
+type ReaderOption func(*RemoteReader)
+
+// WithReaderBlockSize 设置单次 Range 请求的块大小。
+func WithReaderBlockSize(size int64) ReaderOption {
+	return func(r *RemoteReader) {
+		if size > 0 {
+			r.blockSize = size
+		}
+	}
+}
+
+// WithReaderCacheBlocks 设置缓存块数量上限，内存占用约为块大小乘以该值。
+func WithReaderCacheBlocks(n int) ReaderOption {
+	return func(r *RemoteReader) {
+		if n > 0 {
+			r.maxBlocks = n
+		}
+	}
+}
+
+// WithReaderReadAhead 设置顺序读取时额外预读的块数量，0 表示关闭预读。
+func WithReaderReadAhead(n int) ReaderOption {
+	return func(r *RemoteReader) {
+		if n >= 0 {
+			r.readAhead = n
+		}
+	}
+}
+
+// OpenRemoteReader 打开远端文件的随机读取器，size < 0 时通过 GetFileInfo 获取文件大小。
+// ctx 作用于读取器生命周期内的全部请求。
+func (c *Client) OpenRemoteReader(ctx context.Context, fileID string, size int64, opts ...ReaderOption) (*RemoteReader, error) {
+	if c == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if fileID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "fileID 不能为空", errors.New("cloud189: fileID 为空"))
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if size < 0 {
+		info, err := c.GetFileInfo(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsFolder {
+			return nil, WrapCloudError(ErrCodeInvalidRequest, "不能读取文件夹", errors.New("cloud189: "+fileID+" 是文件夹"))
+		}
+		size = info.FileSize
+	}
+	r := &RemoteReader{
+		client:    c,
+		ctx:       ctx,
+		fileID:    fileID,
+		size:      size,
+		blockSize: DefaultReaderBlockSize,
+		maxBlocks: DefaultReaderCacheBlocks,
+		readAhead: DefaultReaderReadAhead,
+		lastBlock: -2,
+		lru:       list.New(),
+		blocks:    make(map[int64]*list.Element),
+		inflight:  make(map[int64]*blockFetch),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(r)
+		}
+	}
+	// 预读块需能留在缓存中，否则会被同一次请求挤出。
+	r.readAhead = min(r.readAhead, r.maxBlocks-1)
+	return r, nil
+}
+
+// Size 返回文件大小。
+func (r *RemoteReader) Size() int64 {
+	return r.size
+}
+
+// Read 从当前游标读取。
+func (r *RemoteReader) Read(p []byte) (int, error) {
+	r.cursorMu.Lock()
+	defer r.cursorMu.Unlock()
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if n > 0 && errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek 设置游标位置。
+func (r *RemoteReader) Seek(offset int64, whence int) (int64, error) {
+	r.cursorMu.Lock()
+	defer r.cursorMu.Unlock()
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, WrapCloudError(ErrCodeInvalidRequest, "无效的 whence", errors.New("cloud189: whence="+strconv.Itoa(whence)))
+	}
+	if offset < 0 {
+		return 0, WrapCloudError(ErrCodeInvalidRequest, "偏移量不能为负", errors.New("cloud189: 偏移量为负"))
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// ReadAt 读取 off 处的数据，遵循 io.ReaderAt 语义。
+func (r *RemoteReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, WrapCloudError(ErrCodeInvalidRequest, "偏移量不能为负", errors.New("cloud189: 偏移量为负"))
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	var n int
+	for n < len(p) && off < r.size {
+		index := off / r.blockSize
+		data, err := r.block(index)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], data[off-index*r.blockSize:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close 释放缓存，之后的读取返回错误。
+func (r *RemoteReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	r.lru.Init()
+	r.blocks = make(map[int64]*list.Element)
+	return nil
+}
+
+// block 返回指定块，未命中时连同预读块一次请求拉取；请求期间释放锁，
+// 其他协程读取同一块时等待该请求而不重复拉取。
+func (r *RemoteReader) block(index int64) ([]byte, error) {
+	r.mu.Lock()
+	sequential := index == r.lastBlock+1
+	r.lastBlock = index
+	for {
+		if r.closed {
+			r.mu.Unlock()
+			return nil, WrapCloudError(ErrCodeInvalidRequest, "读取器已关闭", errors.New("cloud189: RemoteReader 已关闭"))
+		}
+		if elem, ok := r.blocks[index]; ok {
+			r.lru.MoveToFront(elem)
+			r.mu.Unlock()
+			return elem.Value.(*cachedBlock).data, nil
+		}
+		pending, ok := r.inflight[index]
+		if !ok {
+			break
+		}
+		r.mu.Unlock()
+		select {
+		case <-pending.done:
+		case <-r.ctx.Done():
+			return nil, r.ctx.Err()
+		}
+		if pending.err != nil {
+			return nil, pending.err
+		}
+		r.mu.Lock()
+	}
+	count := int64(1)
+	if sequential {
+		lastIndex := (r.size - 1) / r.blockSize
+		for count <= int64(r.readAhead) && index+count <= lastIndex {
+			if _, ok := r.blocks[index+count]; ok {
+				break
+			}
+			if _, ok := r.inflight[index+count]; ok {
+				break
+			}
+			count++
+		}
+	}
+	pending := &blockFetch{done: make(chan struct{})}
+	for i := int64(0); i < count; i++ {
+		r.inflight[index+i] = pending
+	}
+	r.mu.Unlock()
+
+	data, err := r.fetch(index*r.blockSize, min((index+count)*r.blockSize, r.size))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := int64(0); i < count; i++ {
+		delete(r.inflight, index+i)
+	}
+	pending.err = err
+	close(pending.done)
+	if err != nil {
+		return nil, err
+	}
+	if !r.closed {
+		for i := count - 1; i >= 0; i-- {
+			start := i * r.blockSize
+			r.store(index+i, data[start:min(start+r.blockSize, int64(len(data)))])
+		}
+	}
+	return data[:min(r.blockSize, int64(len(data)))], nil
+}
+
+func (r *RemoteReader) store(index int64, data []byte) {
+	r.blocks[index] = r.lru.PushFront(&cachedBlock{index: index, data: data})
+	for r.lru.Len() > r.maxBlocks {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.blocks, oldest.Value.(*cachedBlock).index)
+	}
+}
+
+// fetch 读取 [start, end) 区间，链接过期时刷新一次后重试，调用方不持有 r.mu。
+func (r *RemoteReader) fetch(start, end int64) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		r.mu.Lock()
+		link := r.url
+		r.mu.Unlock()
+		if link == "" {
+			var err error
+			if link, err = r.client.GetDownloadURL(r.ctx, r.fileID); err != nil {
+				return nil, err
+			}
+			r.mu.Lock()
+			r.url = link
+			r.mu.Unlock()
+		}
+		data, status, err := r.rangeGet(link, start, end)
+		if err == nil {
+			return data, nil
+		}
+		if attempt == 0 && isExpiredStatus(status) {
+			r.mu.Lock()
+			if r.url == link {
+				r.url = ""
+			}
+			r.mu.Unlock()
+			continue
+		}
+		return nil, err
+	}
+}
+
+func (r *RemoteReader) rangeGet(link string, start, end int64) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, 0, WrapCloudError(ErrCodeInvalidRequest, "下载链接无效", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+	resp, err := r.client.HTTPClient().Do(req)
+	if err != nil {
+		return nil, 0, WrapCloudError(ErrCodeUnknown, "下载请求失败", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// 服务端忽略 Range 时从完整内容中跳过前缀。
+		if _, err := io.CopyN(io.Discard, resp.Body, start); err != nil {
+			return nil, resp.StatusCode, WrapCloudError(ErrCodeServer, "读取下载内容失败", err)
+		}
+	default:
+		return nil, resp.StatusCode, WrapCloudError(ErrCodeServer, fmt.Sprintf("下载失败，状态码=%d", resp.StatusCode), errors.New(resp.Status))
+	}
+	data := make([]byte, end-start)
+	if _, err := io.ReadFull(resp.Body, data); err != nil {
+		return nil, resp.StatusCode, WrapCloudError(ErrCodeServer, "读取下载内容失败", err)
+	}
+	return data, resp.StatusCode, nil
+}
+
+// isExpiredStatus 判断状态码是否表示下载链接失效。
+func isExpiredStatus(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusGone
+}
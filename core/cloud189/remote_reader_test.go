@@ -0,0 +1,189 @@
+package cloud189
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// fakeDownload 模拟下载直链：token 与当前版本不一致时返回 403。
+type fakeDownload struct {
+	mu     sync.Mutex
+	data   []byte
+	token  int
+	urls   int
+	ranges []string
+	// stall 非空时，Range 与之相同的请求在 release 关闭前阻塞。
+	stall   string
+	release chan struct{}
+}
+
+func (f *fakeDownload) handler(srvURL func() string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/getFileDownloadUrl.action", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.urls++
+		writeJSON(w, map[string]any{"res_code": 0, "fileDownloadUrl": srvURL() + "/dl?token=" + strconv.Itoa(f.token)})
+	})
+	mux.HandleFunc("/dl", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		expired := r.URL.Query().Get("token") != strconv.Itoa(f.token)
+		f.ranges = append(f.ranges, r.Header.Get("Range"))
+		stall := f.stall != "" && r.Header.Get("Range") == f.stall
+		f.mu.Unlock()
+		if stall {
+			<-f.release
+		}
+		if expired {
+			http.Error(w, "expired", http.StatusForbidden)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(f.data))
+	})
+	return mux
+}
+
+func newReaderTest(t *testing.T, data []byte) (*Client, *fakeDownload) {
+	t.Helper()
+	fake := &fakeDownload{data: data}
+	var base string
+	client := newTestClient(t, fake.handler(func() string { return base }))
+	base = client.appBaseURL
+	return client, fake
+}
+
+// TestRemoteReader_RandomAccess 满足 io.ReaderAt/ReadSeeker 语义，缓存命中时不再请求。
+func TestRemoteReader_RandomAccess(t *testing.T) {
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	client, fake := newReaderTest(t, data)
+	r, err := client.OpenRemoteReader(context.Background(), "1", int64(len(data)),
+		WithReaderBlockSize(4), WithReaderCacheBlocks(4), WithReaderReadAhead(0))
+	if err != nil {
+		t.Fatalf("打开失败: %v", err)
+	}
+	defer r.Close()
+	if err := iotest.TestReader(r, data); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 6)
+	if n, err := r.ReadAt(buf, 30); n != 6 || err != nil || string(buf) != "uvwxyz" {
+		t.Fatalf("末尾读取异常: %d %v %q", n, err, buf)
+	}
+	if n, err := r.ReadAt(buf, 33); n != 3 || err != io.EOF {
+		t.Fatalf("越界读取应返回 io.EOF: %d %v", n, err)
+	}
+	before := len(fake.ranges)
+	if _, err := r.ReadAt(buf[:2], 31); err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if len(fake.ranges) != before {
+		t.Fatalf("缓存命中时不应发起请求: %v", fake.ranges[before:])
+	}
+}
+
+// TestRemoteReader_ReadAheadAndRefresh 顺序读取合并预读请求，链接过期后自动刷新。
+func TestRemoteReader_ReadAheadAndRefresh(t *testing.T) {
+	data := bytes.Repeat([]byte("abcd"), 8)
+	client, fake := newReaderTest(t, data)
+	r, err := client.OpenRemoteReader(context.Background(), "1", int64(len(data)),
+		WithReaderBlockSize(4), WithReaderCacheBlocks(8), WithReaderReadAhead(3))
+	if err != nil {
+		t.Fatalf("打开失败: %v", err)
+	}
+	buf := make([]byte, 4)
+	for i := 0; i < 3; i++ {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			t.Fatalf("顺序读取失败: %v", err)
+		}
+	}
+	if len(fake.ranges) != 2 || fake.ranges[1] != "bytes=4-19" {
+		t.Fatalf("第二块起应合并预读: %v", fake.ranges)
+	}
+
+	fake.mu.Lock()
+	fake.token++
+	fake.mu.Unlock()
+	if _, err := r.Seek(-4, io.SeekEnd); err != nil {
+		t.Fatalf("Seek 失败: %v", err)
+	}
+	if _, err := io.ReadFull(r, buf); err != nil || string(buf) != "abcd" {
+		t.Fatalf("过期后应刷新链接继续读取: %v %q", err, buf)
+	}
+	if fake.urls != 2 {
+		t.Fatalf("应重新获取一次下载链接，实际 %d 次", fake.urls)
+	}
+}
+
+// TestRemoteReader_ConcurrentBlocks 请求期间不持有锁：慢请求不阻塞其他块，同一块的并发读取只请求一次。
+func TestRemoteReader_ConcurrentBlocks(t *testing.T) {
+	data := []byte("0123456789abcdef")
+	client, fake := newReaderTest(t, data)
+	fake.stall, fake.release = "bytes=0-3", make(chan struct{})
+	r, err := client.OpenRemoteReader(context.Background(), "1", int64(len(data)),
+		WithReaderBlockSize(4), WithReaderCacheBlocks(4), WithReaderReadAhead(0))
+	if err != nil {
+		t.Fatalf("打开失败: %v", err)
+	}
+	defer r.Close()
+
+	var wg sync.WaitGroup
+	results := make([]string, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			buf := make([]byte, 4)
+			if _, err := r.ReadAt(buf, 0); err != nil {
+				t.Errorf("读取首块失败: %v", err)
+			}
+			results[i] = string(buf)
+		}(i)
+	}
+	for {
+		fake.mu.Lock()
+		started := len(fake.ranges) > 0
+		fake.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 4)
+		_, err := r.ReadAt(buf, 8)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("读取其他块失败: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("慢请求不应阻塞其他块的读取")
+	}
+	close(fake.release)
+	wg.Wait()
+
+	if results[0] != "0123" || results[1] != "0123" {
+		t.Fatalf("并发读取结果异常: %q", results)
+	}
+	zeroBlocks := 0
+	for _, rng := range fake.ranges {
+		if rng == "bytes=0-3" {
+			zeroBlocks++
+		}
+	}
+	if zeroBlocks != 1 {
+		t.Fatalf("同一块的并发读取应只请求一次: %v", fake.ranges)
+	}
+}
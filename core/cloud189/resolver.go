@@ -0,0 +1,248 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+)
+
+// resolverPageSize 路径解析时单页拉取的条目数。
+const resolverPageSize = 100
+
+// PathResolver 将 /a/b/c 形式的路径解析为文件信息，并缓存路径到 ID 的映射。
+// 本客户端成功执行创建、重命名、移动、复制、删除后会自动失效相关缓存。
+type PathResolver struct {
+	client      *Client
+	rootID      string
+	unsubscribe func()
+
+	mu     sync.RWMutex
+	byPath map[string]FileInfo
+	byID   map[string]string
+}
+
+// NewPathResolver 创建个人云路径解析器，不再使用时调用 Close 取消变更订阅。
+func NewPathResolver(client *Client) *PathResolver {
+	r := &PathResolver{
+		client: client,
+		rootID: RootFolderID,
+		byPath: make(map[string]FileInfo),
+		byID:   make(map[string]string),
+	}
+	r.unsubscribe = client.SubscribeChanges(r.handleChange)
+	return r
+}
+
+// Close 取消对客户端写操作的订阅，之后缓存不再自动失效。
+func (r *PathResolver) Close() error {
+	if r != nil && r.unsubscribe != nil {
+		r.unsubscribe()
+	}
+	return nil
+}
+
+// CleanPath 规范化远端路径，返回以 / 开头的绝对路径。
+func CleanPath(p string) string {
+	return path.Clean("/" + strings.TrimSpace(p))
+}
+
+// Resolve 返回路径对应的文件 ID。
+func (r *PathResolver) Resolve(ctx context.Context, p string) (string, error) {
+	info, err := r.Stat(ctx, p)
+	if err != nil {
+		return "", err
+	}
+	return info.ID.String(), nil
+}
+
+// Stat 返回路径对应的文件信息，不存在时返回 ErrCodeFileNotFound。
+func (r *PathResolver) Stat(ctx context.Context, p string) (*FileInfo, error) {
+	if r == nil || r.client == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "路径解析器未初始化", errors.New("cloud189: PathResolver 未初始化"))
+	}
+	p = CleanPath(p)
+	if p == "/" {
+		return r.root(), nil
+	}
+	if info, ok := r.cached(p); ok {
+		return &info, nil
+	}
+	parent, err := r.Stat(ctx, path.Dir(p))
+	if err != nil {
+		return nil, err
+	}
+	if !parent.IsFolder {
+		return nil, WrapCloudError(ErrCodeFileNotFound, "上级路径不是文件夹", errors.New("cloud189: "+path.Dir(p)+" 不是文件夹"))
+	}
+	info, err := r.lookupChild(ctx, path.Dir(p), parent.ID.String(), path.Base(p))
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// MkdirAll 逐级创建路径中缺失的文件夹，返回最末级文件夹信息。
+func (r *PathResolver) MkdirAll(ctx context.Context, p string) (*FileInfo, error) {
+	if r == nil || r.client == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "路径解析器未初始化", errors.New("cloud189: PathResolver 未初始化"))
+	}
+	p = CleanPath(p)
+	current := r.root()
+	currentPath := "/"
+	if p == "/" {
+		return current, nil
+	}
+	for _, name := range strings.Split(strings.TrimPrefix(p, "/"), "/") {
+		childPath := path.Join(currentPath, name)
+		info, err := r.Stat(ctx, childPath)
+		switch {
+		case err == nil:
+			if !info.IsFolder {
+				return nil, WrapCloudError(ErrCodeInvalidRequest, "路径已存在同名文件", errors.New("cloud189: "+childPath+" 不是文件夹"))
+			}
+		case isNotFound(err):
+			info, err = r.client.CreateFolder(ctx, current.ID.String(), name)
+			if err != nil {
+				return nil, err
+			}
+			info.IsFolder = true
+			if info.FileName == "" {
+				info.FileName = name
+			}
+			if info.ParentID == "" {
+				info.ParentID = current.ID
+			}
+			r.store(childPath, *info)
+		default:
+			return nil, err
+		}
+		current = info
+		currentPath = childPath
+	}
+	return current, nil
+}
+
+// Invalidate 清除路径及其子路径的缓存。
+func (r *PathResolver) Invalidate(p string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropLocked(CleanPath(p), true)
+}
+
+// Reset 清空全部缓存。
+func (r *PathResolver) Reset() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byPath = make(map[string]FileInfo)
+	r.byID = make(map[string]string)
+}
+
+// PathOf 返回缓存中 ID 对应的路径。
+func (r *PathResolver) PathOf(fileID string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	if fileID == r.rootID {
+		return "/", true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.byID[fileID]
+	return p, ok
+}
+
+func (r *PathResolver) root() *FileInfo {
+	return &FileInfo{ID: FlexString(r.rootID), IsFolder: true}
+}
+
+// lookupChild 分页列出父目录查找子项，顺带缓存同页的兄弟节点。
+func (r *PathResolver) lookupChild(ctx context.Context, parentPath, parentID, name string) (*FileInfo, error) {
+	for page := 1; ; page++ {
+		rsp, err := r.client.ListFiles(ctx, parentID, WithListPagination(page, resolverPageSize))
+		if err != nil {
+			return nil, err
+		}
+		items := rsp.Items()
+		var found *FileInfo
+		for i := range items {
+			item := items[i]
+			r.store(path.Join(parentPath, item.FileName), item)
+			if item.FileName == name && found == nil {
+				found = &item
+			}
+		}
+		if found != nil {
+			return found, nil
+		}
+		if len(items) == 0 || page*resolverPageSize >= rsp.FileListAO.Count {
+			break
+		}
+	}
+	return nil, WrapCloudError(ErrCodeFileNotFound, "路径不存在", errors.New("cloud189: "+path.Join(parentPath, name)+" 不存在"))
+}
+
+func (r *PathResolver) cached(p string) (FileInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.byPath[p]
+	return info, ok
+}
+
+func (r *PathResolver) store(p string, info FileInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.byPath[p]; ok {
+		delete(r.byID, old.ID.String())
+	}
+	r.byPath[p] = info
+	if id := info.ID.String(); id != "" {
+		r.byID[id] = p
+	}
+}
+
+// dropLocked 删除路径的子路径缓存，self 为 true 时连同自身一起删除。
+func (r *PathResolver) dropLocked(p string, self bool) {
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	for key, info := range r.byPath {
+		if (self && key == p) || strings.HasPrefix(key, prefix) {
+			delete(r.byPath, key)
+			delete(r.byID, info.ID.String())
+		}
+	}
+}
+
+// handleChange 根据客户端写操作失效缓存。
+func (r *PathResolver) handleChange(evt ChangeEvent) {
+	if evt.FamilyID != "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range evt.FileIDs {
+		if p, ok := r.byID[id]; ok {
+			r.dropLocked(p, true)
+		}
+	}
+	// 复制/移动/上传可能覆盖目标目录下的同名文件，清理其子路径缓存。
+	if (evt.Op != ChangeCopy && evt.Op != ChangeMove && evt.Op != ChangeUpload) || evt.ParentID == "" {
+		return
+	}
+	if evt.ParentID == r.rootID {
+		r.dropLocked("/", false)
+	} else if p, ok := r.byID[evt.ParentID]; ok {
+		r.dropLocked(p, false)
+	}
+}
+
+func isNotFound(err error) bool {
+	var ce *CloudError
+	return errors.As(err, &ce) && ce.Code == ErrCodeFileNotFound
+}
@@ -0,0 +1,183 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeTree 以 folderId 组织的内存目录树，用于模拟 listFiles/createFolder/renameFile。
+type fakeTree struct {
+	mu       sync.Mutex
+	children map[string][]map[string]any
+	lists    int
+	nextID   int
+}
+
+func (f *fakeTree) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/listFiles.action", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.lists++
+		var files, folders []map[string]any
+		for _, item := range f.children[r.URL.Query().Get("folderId")] {
+			if item["isFolder"] == true {
+				folders = append(folders, item)
+			} else {
+				files = append(files, item)
+			}
+		}
+		writeJSON(w, map[string]any{
+			"res_code": 0,
+			"fileListAO": map[string]any{
+				"count":      len(files) + len(folders),
+				"fileList":   files,
+				"folderList": folders,
+			},
+		})
+	})
+	mux.HandleFunc("/createFolder.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.nextID++
+		id := "new-" + strconv.Itoa(f.nextID)
+		parent := r.Form.Get("parentFolderId")
+		item := map[string]any{"id": id, "name": r.Form.Get("folderName"), "parentId": parent, "isFolder": true}
+		f.children[parent] = append(f.children[parent], item)
+		writeJSON(w, map[string]any{"res_code": 0, "id": id, "name": item["name"], "parentId": parent})
+	})
+	mux.HandleFunc("/renameFile.action", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, items := range f.children {
+			for _, item := range items {
+				if item["id"] == r.Form.Get("fileId") {
+					item["name"] = r.Form.Get("destFileName")
+				}
+			}
+		}
+		writeJSON(w, map[string]any{"res_code": 0})
+	})
+	return mux
+}
+
+func newFakeTree() *fakeTree {
+	return &fakeTree{children: map[string][]map[string]any{
+		RootFolderID: {{"id": "10", "name": "Documents", "isFolder": true}},
+		"10":         {{"id": "20", "name": "2024", "isFolder": true}},
+		"20":         {{"id": "30", "name": "report.pdf", "size": 42}},
+	}}
+}
+
+// TestPathResolver_StatAndCache 验证路径解析与缓存命中。
+func TestPathResolver_StatAndCache(t *testing.T) {
+	tree := newFakeTree()
+	resolver := NewPathResolver(newTestClient(t, tree.handler()))
+	ctx := context.Background()
+
+	info, err := resolver.Stat(ctx, "/Documents/2024/report.pdf")
+	if err != nil {
+		t.Fatalf("解析路径失败: %v", err)
+	}
+	if info.ID != "30" || info.FileSize != 42 {
+		t.Fatalf("解析结果异常: %+v", info)
+	}
+	lists := tree.lists
+	if _, err := resolver.Stat(ctx, "Documents//2024/./report.pdf"); err != nil {
+		t.Fatalf("再次解析失败: %v", err)
+	}
+	if tree.lists != lists {
+		t.Fatalf("命中缓存时不应再次请求 listFiles")
+	}
+
+	if _, err := resolver.Stat(ctx, "/Documents/missing"); !isNotFound(err) {
+		t.Fatalf("不存在路径应返回 FileNotFound，实际: %v", err)
+	}
+}
+
+// TestPathResolver_InvalidateOnRename 重命名后旧路径缓存应失效。
+func TestPathResolver_InvalidateOnRename(t *testing.T) {
+	tree := newFakeTree()
+	client := newTestClient(t, tree.handler())
+	resolver := NewPathResolver(client)
+	ctx := context.Background()
+
+	if _, err := resolver.Stat(ctx, "/Documents/2024/report.pdf"); err != nil {
+		t.Fatalf("解析路径失败: %v", err)
+	}
+	if err := client.RenameFile(ctx, "20", "2025"); err != nil {
+		t.Fatalf("重命名失败: %v", err)
+	}
+	if _, ok := resolver.PathOf("30"); ok {
+		t.Fatalf("重命名目录后其子路径缓存应失效")
+	}
+	info, err := resolver.Stat(ctx, "/Documents/2025/report.pdf")
+	if err != nil || info.ID != "30" {
+		t.Fatalf("新路径应可解析，实际 %+v, %v", info, err)
+	}
+}
+
+// TestPathResolver_Close 关闭后应从客户端移除订阅，其他订阅者不受影响。
+func TestPathResolver_Close(t *testing.T) {
+	tree := newFakeTree()
+	client := newTestClient(t, tree.handler())
+	var events int
+	unsubscribe := client.SubscribeChanges(func(ChangeEvent) { events++ })
+	for i := 0; i < 3; i++ {
+		if err := NewPathResolver(client).Close(); err != nil {
+			t.Fatalf("关闭解析器失败: %v", err)
+		}
+	}
+	resolver := NewPathResolver(client)
+	if n := len(client.listeners); n != 2 {
+		t.Fatalf("关闭后的解析器不应保留订阅，实际 %d 个订阅者", n)
+	}
+	if _, err := resolver.Stat(context.Background(), "/Documents/2024"); err != nil {
+		t.Fatalf("解析路径失败: %v", err)
+	}
+	_ = resolver.Close()
+	_ = resolver.Close()
+	if err := client.RenameFile(context.Background(), "20", "2025"); err != nil {
+		t.Fatalf("重命名失败: %v", err)
+	}
+	if _, ok := resolver.PathOf("20"); !ok {
+		t.Fatalf("关闭后缓存不应再被事件失效")
+	}
+	if events != 1 {
+		t.Fatalf("其余订阅者应收到事件，实际 %d 次", events)
+	}
+	unsubscribe()
+	if n := len(client.listeners); n != 0 {
+		t.Fatalf("全部取消后不应残留订阅者，实际 %d 个", n)
+	}
+}
+
+// TestPathResolver_MkdirAll 验证逐级创建缺失目录。
+func TestPathResolver_MkdirAll(t *testing.T) {
+	tree := newFakeTree()
+	resolver := NewPathResolver(newTestClient(t, tree.handler()))
+	ctx := context.Background()
+
+	info, err := resolver.MkdirAll(ctx, "/Documents/2024/q1/invoices")
+	if err != nil {
+		t.Fatalf("MkdirAll 失败: %v", err)
+	}
+	if !info.IsFolder || info.FileName != "invoices" {
+		t.Fatalf("返回结果异常: %+v", info)
+	}
+	if len(tree.children["20"]) != 2 {
+		t.Fatalf("应在 2024 下新建 q1，实际子项 %d", len(tree.children["20"]))
+	}
+	again, err := resolver.MkdirAll(ctx, "/Documents/2024/q1/invoices")
+	if err != nil || again.ID != info.ID {
+		t.Fatalf("重复 MkdirAll 应返回已有目录，实际 %+v, %v", again, err)
+	}
+	if _, err := resolver.MkdirAll(ctx, "/Documents/2024/report.pdf/x"); err == nil {
+		t.Fatalf("路径中存在同名文件时应报错")
+	}
+}
@@ -0,0 +1,163 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/dnslin/cloud189-desktop/core/httpclient"
+	"github.com/dnslin/cloud189-desktop/core/model"
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+// maxThumbnailBytes 单张缩略图的读取上限，防止异常响应占满内存。
+const maxThumbnailBytes = 8 << 20
+
+// ThumbnailCache 缩略图读取缓存，命中时不访问网络，并发请求同一张图只下载一次。
+// 持久化由注入的 store.ThumbnailStore 负责，缓存键包含文件版本，文件更新后自然失效。
+type ThumbnailCache struct {
+	client   *Client
+	store    store.ThumbnailStore
+	maxBytes int64
+
+	mu       sync.Mutex
+	inflight map[string]*thumbnailCall
+}
+
+type thumbnailCall struct {
+	done     chan struct{}
+	data     []byte
+	err      error
+	canceled bool // 发起方的 ctx 已取消，失败不应传递给其他等待者
+}
+
+// NewThumbnailCache 创建缩略图缓存。
+func NewThumbnailCache(client *Client, st store.ThumbnailStore) *ThumbnailCache {
+	return &ThumbnailCache{
+		client:   client,
+		store:    st,
+		maxBytes: maxThumbnailBytes,
+		inflight: make(map[string]*thumbnailCall),
+	}
+}
+
+// Get 返回文件指定尺寸的缩略图内容。
+// file 已携带缩略图地址时直接使用，否则按 ID 重新查询。
+func (tc *ThumbnailCache) Get(ctx context.Context, file model.File, size ThumbnailSize) ([]byte, error) {
+	if tc == nil || tc.client == nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "缩略图缓存未初始化", errors.New("cloud189: ThumbnailCache 未初始化"))
+	}
+	if file.ID == "" {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "fileID 不能为空", errors.New("cloud189: fileID 为空"))
+	}
+	key := thumbnailKey(file, size)
+	for {
+		if tc.store != nil {
+			data, err := tc.store.LoadThumbnail(key)
+			if err != nil {
+				return nil, err
+			}
+			if data != nil {
+				return data, nil
+			}
+		}
+
+		tc.mu.Lock()
+		if call, ok := tc.inflight[key]; ok {
+			tc.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			// 发起方被取消导致的失败与本次请求无关，重新获取。
+			if call.canceled && ctx.Err() == nil {
+				continue
+			}
+			return call.data, call.err
+		}
+		call := &thumbnailCall{done: make(chan struct{})}
+		tc.inflight[key] = call
+		tc.mu.Unlock()
+
+		call.data, call.err = tc.fetch(ctx, file, size)
+		if call.err == nil && tc.store != nil {
+			call.err = tc.store.SaveThumbnail(key, call.data)
+		}
+		call.canceled = call.err != nil && ctx.Err() != nil
+		tc.mu.Lock()
+		delete(tc.inflight, key)
+		tc.mu.Unlock()
+		close(call.done)
+		return call.data, call.err
+	}
+}
+
+// Invalidate 删除文件所有尺寸的缓存。
+func (tc *ThumbnailCache) Invalidate(file model.File) error {
+	if tc == nil || tc.store == nil {
+		return nil
+	}
+	var errs []error
+	for _, size := range []ThumbnailSize{ThumbnailSmall, ThumbnailMedium, ThumbnailLarge} {
+		if err := tc.store.DeleteThumbnail(thumbnailKey(file, size)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (tc *ThumbnailCache) fetch(ctx context.Context, file model.File, size ThumbnailSize) ([]byte, error) {
+	u := ThumbnailURL(file.Thumbnails, size)
+	if u == "" {
+		var err error
+		if u, err = tc.client.GetThumbnailURL(ctx, file.ID, size); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, WrapCloudError(ErrCodeInvalidRequest, "构建缩略图请求失败", err)
+	}
+	resp, err := tc.client.HTTPClient().Do(req)
+	if err != nil {
+		return nil, WrapCloudError(ErrCodeUnknown, "下载缩略图失败", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		ec := &httpclient.ErrCode{Status: resp.StatusCode, Message: resp.Status}
+		return nil, WrapCloudError(mapErrCode(ec), "下载缩略图失败", ec)
+	}
+	// 多读一个字节以区分恰好达到上限与超出上限，超出时不返回截断的图片。
+	data, err := io.ReadAll(io.LimitReader(resp.Body, tc.maxBytes+1))
+	if err != nil {
+		return nil, WrapCloudError(ErrCodeUnknown, "读取缩略图失败", err)
+	}
+	if int64(len(data)) > tc.maxBytes {
+		return nil, WrapCloudError(ErrCodeUnknown, "缩略图过大", fmt.Errorf("cloud189: 缩略图超过 %d 字节", tc.maxBytes))
+	}
+	return data, nil
+}
+
+// thumbnailKey 生成可直接作为文件名的缓存键。
+func thumbnailKey(file model.File, size ThumbnailSize) string {
+	rev := file.Revision
+	if rev == "" {
+		rev = file.MD5
+	}
+	return fmt.Sprintf("%s_%s_%s", sanitizeKeyPart(file.ID), sanitizeKeyPart(rev), size)
+}
+
+func sanitizeKeyPart(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '-' {
+			out = append(out, ch)
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,158 @@
+package cloud189
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+type memoryThumbnailStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryThumbnailStore) LoadThumbnail(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *memoryThumbnailStore) SaveThumbnail(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		s.data = make(map[string][]byte)
+	}
+	s.data[key] = data
+	return nil
+}
+
+func (s *memoryThumbnailStore) DeleteThumbnail(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+// TestThumbnailCache_FetchOnce 首次按 ID 查询地址并下载，之后命中缓存不再访问网络。
+func TestThumbnailCache_FetchOnce(t *testing.T) {
+	var infoHits, thumbHits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/getFileInfo.action", func(w http.ResponseWriter, r *http.Request) {
+		infoHits.Add(1)
+		if got := r.URL.Query().Get("iconOption"); got != iconOptionAll {
+			t.Errorf("iconOption 期望 %s，实际 %s", iconOptionAll, got)
+		}
+		writeJSON(w, map[string]any{
+			"res_code":  0,
+			"id":        30,
+			"name":      "a.jpg",
+			"mediumUrl": "http://" + r.Host + "/thumb/medium",
+		})
+	})
+	mux.HandleFunc("/thumb/medium", func(w http.ResponseWriter, r *http.Request) {
+		thumbHits.Add(1)
+		_, _ = w.Write([]byte("jpeg-bytes"))
+	})
+	client := newTestClient(t, mux)
+	cache := NewThumbnailCache(client, &memoryThumbnailStore{})
+	file := model.File{ID: "30", Revision: "r1"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := cache.Get(context.Background(), file, ThumbnailLarge)
+			if err != nil {
+				t.Errorf("获取缩略图失败: %v", err)
+				return
+			}
+			if string(data) != "jpeg-bytes" {
+				t.Errorf("缩略图内容不符: %q", data)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := cache.Get(context.Background(), file, ThumbnailLarge); err != nil {
+		t.Fatalf("再次获取缩略图失败: %v", err)
+	}
+	if got := thumbHits.Load(); got != 1 {
+		t.Fatalf("缩略图应只下载一次，实际 %d 次", got)
+	}
+	if got := infoHits.Load(); got != 1 {
+		t.Fatalf("文件信息应只查询一次，实际 %d 次", got)
+	}
+}
+
+// TestThumbnailCache_RejectsOversized 超过上限的响应返回错误且不写入缓存，恰好等于上限时正常返回。
+func TestThumbnailCache_RejectsOversized(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/thumb/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path[len("/thumb/"):]))
+	})
+	client := newTestClient(t, mux)
+	st := &memoryThumbnailStore{}
+	cache := NewThumbnailCache(client, st)
+	cache.maxBytes = 8
+	base := client.appBaseURL + "/thumb/"
+
+	big := model.File{ID: "1", Revision: "r1", Thumbnails: model.Thumbnails{Small: base + "123456789"}}
+	if data, err := cache.Get(context.Background(), big, ThumbnailSmall); err == nil {
+		t.Fatalf("超出上限应返回错误，实际得到 %q", data)
+	}
+	if len(st.data) != 0 {
+		t.Fatalf("超出上限的缩略图不应缓存: %v", st.data)
+	}
+	exact := model.File{ID: "2", Revision: "r1", Thumbnails: model.Thumbnails{Small: base + "12345678"}}
+	if data, err := cache.Get(context.Background(), exact, ThumbnailSmall); err != nil || string(data) != "12345678" {
+		t.Fatalf("恰好达到上限应正常返回: %q %v", data, err)
+	}
+}
+
+// TestThumbnailCache_WaiterIgnoresOriginatorCancel 发起方取消后，等待同一张图的请求应自行重新获取。
+func TestThumbnailCache_WaiterIgnoresOriginatorCancel(t *testing.T) {
+	var hits atomic.Int32
+	started := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/thumb/a", func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			close(started)
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("jpeg"))
+	})
+	client := newTestClient(t, mux)
+	cache := NewThumbnailCache(client, &memoryThumbnailStore{})
+	file := model.File{ID: "1", Revision: "r1", Thumbnails: model.Thumbnails{Small: client.appBaseURL + "/thumb/a"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	origin := make(chan error, 1)
+	go func() {
+		_, err := cache.Get(ctx, file, ThumbnailSmall)
+		origin <- err
+	}()
+	<-started
+	waiter := make(chan []byte, 1)
+	go func() {
+		data, err := cache.Get(context.Background(), file, ThumbnailSmall)
+		if err != nil {
+			t.Errorf("等待者不应继承发起方的取消: %v", err)
+		}
+		waiter <- data
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-origin; err == nil {
+		t.Fatalf("发起方被取消时应返回错误")
+	}
+	if data := <-waiter; string(data) != "jpeg" {
+		t.Fatalf("等待者应拿到缩略图，实际 %q", data)
+	}
+}
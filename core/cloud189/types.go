@@ -28,7 +28,10 @@ func (t *CloudTime) UnmarshalJSON(data []byte) error {
 	}
 	parsed, err := time.Parse("2006-01-02 15:04:05", raw)
 	if err != nil {
-		return err
+		// 本地缓存经 encoding/json 序列化后为 RFC3339 格式。
+		if parsed, err = time.Parse(time.RFC3339Nano, raw); err != nil {
+			return err
+		}
 	}
 	t.Time = parsed
 	return nil
@@ -48,6 +51,23 @@ type UserInfo struct {
 	BackupSpace uint64 `json:"backupCapacity,omitempty"`
 }
 
+// FamilyInfo 描述账号加入的家庭云。
+type FamilyInfo struct {
+	FamilyID   FlexString `json:"familyId,omitempty"`
+	RemarkName string     `json:"remarkName,omitempty"`
+	Type       int        `json:"type,omitempty"`
+	UserRole   int        `json:"userRole,omitempty"`
+	Count      int        `json:"count,omitempty"`
+	CreateTime CloudTime  `json:"createTime,omitempty"`
+	ExpireTime CloudTime  `json:"expireTime,omitempty"`
+}
+
+// FamilyListResponse 家庭云列表响应。
+type FamilyListResponse struct {
+	CodeResponse
+	Families []FamilyInfo `json:"familyInfoResp,omitempty"`
+}
+
 // FileInfo 统一 App/Web 文件或文件夹描述。
 type FileInfo struct {
 	ID            FlexString `json:"id,omitempty"`
@@ -62,6 +82,7 @@ type FileInfo struct {
 	StarLabel     int        `json:"starLabel,omitempty"`
 	LastOpTime    CloudTime  `json:"lastOpTime,omitempty"`
 	CreateDate    CloudTime  `json:"createDate,omitempty"`
+	ShootTime     CloudTime  `json:"shootTime,omitempty"`
 	IsFolder      bool       `json:"isFolder,omitempty"`
 	FileCount     int        `json:"fileCount,omitempty"`
 	FileListSize  int        `json:"fileListSize,omitempty"`
@@ -130,6 +151,168 @@ func (r SearchResponse) Items() []FileInfo {
 	return items
 }
 
+// RecycleBinResponse 兼容回收站列表的两种返回结构。
+type RecycleBinResponse struct {
+	CodeResponse
+	FileListAO FileListResult `json:"fileListAO,omitempty"`
+	Count      int            `json:"count,omitempty"`
+	Files      []FileInfo     `json:"fileList,omitempty"`
+	Folders    []FileInfo     `json:"folderList,omitempty"`
+}
+
+// Items 返回回收站中的文件与文件夹。
+func (r RecycleBinResponse) Items() []FileInfo {
+	items := r.FileListAO.Items()
+	items = append(items, FileListResult{Files: r.Files, Folders: r.Folders}.Items()...)
+	return items
+}
+
+// Total 返回回收站条目总数。
+func (r RecycleBinResponse) Total() int {
+	if r.FileListAO.Count > 0 {
+		return r.FileListAO.Count
+	}
+	return r.Count
+}
+
+// OfflineTask 描述一个云端离线下载任务。
+type OfflineTask struct {
+	TaskID     FlexString        `json:"taskId,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	FileName   string            `json:"fileName,omitempty"`
+	FolderID   FlexString        `json:"folderId,omitempty"`
+	FileID     FlexString        `json:"fileId,omitempty"`
+	Status     OfflineTaskStatus `json:"status,omitempty"`
+	TotalSize  int64             `json:"totalSize,omitempty"`
+	Downloaded int64             `json:"downloadSize,omitempty"`
+	ErrMsg     string            `json:"errMsg,omitempty"`
+	CreateTime CloudTime         `json:"createTime,omitempty"`
+	FinishTime CloudTime         `json:"finishTime,omitempty"`
+}
+
+// Percent 返回下载完成百分比（0-100）。
+func (t OfflineTask) Percent() float64 {
+	if t.Status == OfflineStatusCompleted {
+		return 100
+	}
+	if t.TotalSize <= 0 {
+		return 0
+	}
+	return float64(t.Downloaded) / float64(t.TotalSize) * 100
+}
+
+// OfflineTaskListResponse 离线下载任务列表响应。
+type OfflineTaskListResponse struct {
+	CodeResponse
+	Count int           `json:"count,omitempty"`
+	Tasks []OfflineTask `json:"taskList,omitempty"`
+}
+
+// ShareInfo 描述一个分享链接。
+type ShareInfo struct {
+	ShareID       FlexString `json:"shareId,omitempty"`
+	FileID        FlexString `json:"fileId,omitempty"`
+	FileName      string     `json:"fileName,omitempty"`
+	IsFolder      bool       `json:"isFolder,omitempty"`
+	ShareType     int        `json:"shareType,omitempty"`
+	AccessCode    string     `json:"accessCode,omitempty"`
+	AccessURL     string     `json:"accessURL,omitempty"`
+	ShortShareURL string     `json:"shortShareUrl,omitempty"`
+	ExpireDays    int        `json:"expireType,omitempty"`
+	ExpireTime    CloudTime  `json:"expireTime,omitempty"`
+	ShareDate     CloudTime  `json:"shareDate,omitempty"`
+	PreviewCount  int        `json:"previewCount,omitempty"`
+	DownloadCount int        `json:"downloadCount,omitempty"`
+	SaveCount     int        `json:"saveCount,omitempty"`
+}
+
+// URL 返回可访问的分享地址，优先使用短链接。
+func (s ShareInfo) URL() string {
+	if s.ShortShareURL != "" {
+		return s.ShortShareURL
+	}
+	return s.AccessURL
+}
+
+type shareLink struct {
+	ShareID    FlexString `json:"shareId,omitempty"`
+	FileID     FlexString `json:"fileId,omitempty"`
+	URL        string     `json:"url,omitempty"`
+	AccessCode string     `json:"accessCode,omitempty"`
+}
+
+// ShareCreateResponse 创建分享响应，兼容顶层字段与 shareLinkList 两种结构。
+type ShareCreateResponse struct {
+	CodeResponse
+	ShareInfo
+	ShareLinkList []shareLink `json:"shareLinkList,omitempty"`
+}
+
+// Share 合并响应中的分享信息。
+func (r ShareCreateResponse) Share() ShareInfo {
+	info := r.ShareInfo
+	if len(r.ShareLinkList) > 0 {
+		link := r.ShareLinkList[0]
+		if info.ShareID == "" {
+			info.ShareID = link.ShareID
+		}
+		if info.FileID == "" {
+			info.FileID = link.FileID
+		}
+		if info.AccessURL == "" {
+			info.AccessURL = link.URL
+		}
+		if info.AccessCode == "" {
+			info.AccessCode = link.AccessCode
+		}
+	}
+	return info
+}
+
+// ShareListResponse 分享列表响应。
+type ShareListResponse struct {
+	CodeResponse
+	Data        []ShareInfo `json:"data,omitempty"`
+	RecordCount int         `json:"recordCount,omitempty"`
+}
+
+// PublicShare 描述他人分享的公开链接（已校验访问码）。
+type PublicShare struct {
+	CodeResponse
+	ShareCode      string     `json:"-"`
+	AccessCode     string     `json:"-"`
+	ShareID        FlexString `json:"shareId,omitempty"`
+	ShareMode      FlexString `json:"shareMode,omitempty"`
+	FileID         FlexString `json:"fileId,omitempty"`
+	FileName       string     `json:"fileName,omitempty"`
+	FileSize       int64      `json:"fileSize,omitempty"`
+	MD5            string     `json:"md5,omitempty"`
+	IsFolder       bool       `json:"isFolder,omitempty"`
+	NeedAccessCode int        `json:"needAccessCode,omitempty"`
+	ExpireTime     CloudTime  `json:"expireTime,omitempty"`
+	CreateDate     CloudTime  `json:"createDate,omitempty"`
+}
+
+// File 返回分享根节点对应的文件描述。
+func (s PublicShare) File() FileInfo {
+	return FileInfo{
+		ID:         s.FileID,
+		FileName:   s.FileName,
+		FileSize:   s.FileSize,
+		MD5:        s.MD5,
+		IsFolder:   s.IsFolder,
+		CreateDate: s.CreateDate,
+	}
+}
+
+// ShareSaveResult 记录单个文件的转存结果，Err 为 nil 且 Unknown 为 false 表示成功。
+type ShareSaveResult struct {
+	File FileInfo
+	Err  error
+	// Unknown 服务端只返回了成功/失败计数而没有逐项结果，无法确认该文件是否转存成功。
+	Unknown bool
+}
+
 // CapacityInfo 描述用户空间容量。
 type CapacityInfo struct {
 	CodeResponse
@@ -212,8 +395,46 @@ func (f FileInfo) ToModel() model.File {
 		ChildCount:  f.FileCount,
 		ParentPath:  f.ParentPath,
 		DownloadURL: f.DownloadURL,
-		UpdatedAt:   f.LastOpTime.Time,
-		CreatedAt:   f.CreateDate.Time,
+		Thumbnails: model.Thumbnails{
+			Small:  f.IconSmallURL,
+			Medium: f.IconMediumURL,
+			Large:  f.IconLargeURL,
+		},
+		UpdatedAt: f.LastOpTime.Time,
+		CreatedAt: f.CreateDate.Time,
+		TakenAt:   f.ShootTime.Time,
+	}
+}
+
+// FileInfoFromModel 由领域模型还原批量任务所需的文件信息（ID、文件名、是否文件夹等）。
+func FileInfoFromModel(f model.File) FileInfo {
+	return FileInfo{
+		ID:           FlexString(f.ID),
+		ParentID:     FlexString(f.ParentID),
+		FileName:     f.Name,
+		FileSize:     f.Size,
+		MD5:          f.MD5,
+		IsFolder:     f.IsFolder,
+		MediaType:    f.MediaType,
+		FileCategory: f.Category,
+	}
+}
+
+// ToModel 将分享信息转换为领域模型。
+func (s ShareInfo) ToModel() model.Share {
+	return model.Share{
+		ID:            s.ShareID.String(),
+		FileID:        s.FileID.String(),
+		FileName:      s.FileName,
+		IsFolder:      s.IsFolder,
+		URL:           s.URL(),
+		AccessCode:    s.AccessCode,
+		Permanent:     s.ExpireDays == ShareExpirePermanent,
+		ExpiresAt:     s.ExpireTime.Time,
+		CreatedAt:     s.ShareDate.Time,
+		ViewCount:     s.PreviewCount,
+		DownloadCount: s.DownloadCount,
+		SaveCount:     s.SaveCount,
 	}
 }
 
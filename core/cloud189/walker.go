@@ -0,0 +1,151 @@
+package cloud189
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// defaultWalkConcurrency 默认同时列目录的数量。
+const defaultWalkConcurrency = 4
+
+// WalkFunc 遍历回调，在单个 goroutine 中串行调用。
+// 对文件夹返回 fs.SkipDir 跳过其子树，对文件返回 fs.SkipDir 跳过同目录剩余条目；
+// 返回 fs.SkipAll 结束遍历且 Walk 返回 nil；其他错误会中止遍历并原样返回。
+type WalkFunc func(file model.File) error
+
+// WalkOption 配置遍历参数。
+type WalkOption func(*walkConfig)
+
+type walkConfig struct {
+	concurrency int
+	maxDepth    int
+	listOpts    []ListOption
+}
+
+// WithWalkConcurrency 设置同时列目录的最大数量。
+func WithWalkConcurrency(n int) WalkOption {
+	return func(cfg *walkConfig) {
+		if n > 0 {
+			cfg.concurrency = n
+		}
+	}
+}
+
+// WithWalkMaxDepth 限制遍历深度，根目录的直接子项深度为 1，n<=0 表示不限制。
+func WithWalkMaxDepth(n int) WalkOption {
+	return func(cfg *walkConfig) {
+		cfg.maxDepth = n
+	}
+}
+
+// WithWalkListOptions 为每次列目录追加 ListOption（如排序、分页大小）。
+func WithWalkListOptions(opts ...ListOption) WalkOption {
+	return func(cfg *walkConfig) {
+		cfg.listOpts = append(cfg.listOpts, opts...)
+	}
+}
+
+type walkJob struct {
+	id    string
+	path  string
+	depth int
+}
+
+type walkResult struct {
+	job   walkJob
+	items []FileInfo
+	err   error
+}
+
+// Walk 以有限并发递归遍历 rootID 下的所有文件与文件夹（不含根目录本身），
+// rootPath 为根目录的远端路径，用于拼接 model.File.Path。
+// 所有列目录请求都经过 Client 的 httpclient，因此受其 RateLimiter 约束。
+func (c *Client) Walk(ctx context.Context, rootID, rootPath string, fn WalkFunc, opts ...WalkOption) error {
+	if c == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "客户端未初始化", errors.New("cloud189: Client 未初始化"))
+	}
+	if rootID == "" || fn == nil {
+		return WrapCloudError(ErrCodeInvalidRequest, "参数缺失", errors.New("cloud189: rootID 或 WalkFunc 为空"))
+	}
+	cfg := walkConfig{concurrency: defaultWalkConcurrency}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan walkResult)
+	queue := []walkJob{{id: rootID, path: CleanPath(rootPath)}}
+	running := 0
+	var walkErr error
+
+	for {
+		for walkErr == nil && running < cfg.concurrency && len(queue) > 0 {
+			job := queue[0]
+			queue = queue[1:]
+			running++
+			go func(job walkJob) {
+				items, err := c.ListAllFiles(ctx, job.id, cfg.listOpts...)
+				results <- walkResult{job: job, items: items, err: err}
+			}(job)
+		}
+		if running == 0 {
+			break
+		}
+		res := <-results
+		running--
+		if walkErr != nil {
+			continue
+		}
+		if res.err != nil {
+			walkErr = res.err
+			cancel()
+			continue
+		}
+		queue = append(queue, visitWalkResult(res, fn, cfg, &walkErr)...)
+		if walkErr != nil {
+			cancel()
+		}
+	}
+	if errors.Is(walkErr, fs.SkipAll) {
+		return nil
+	}
+	return walkErr
+}
+
+// visitWalkResult 依次回调目录条目，返回需要继续展开的子目录。
+func visitWalkResult(res walkResult, fn WalkFunc, cfg walkConfig, walkErr *error) []walkJob {
+	var next []walkJob
+	depth := res.job.depth + 1
+	for _, item := range res.items {
+		file := item.ToModel()
+		if file.ParentID == "" {
+			file.ParentID = res.job.id
+		}
+		file.ParentPath = res.job.path
+		file.Path = path.Join(res.job.path, file.Name)
+
+		err := fn(file)
+		switch {
+		case err == nil:
+		case errors.Is(err, fs.SkipDir):
+			if file.IsFolder {
+				continue
+			}
+			return next
+		default:
+			*walkErr = err
+			return next
+		}
+		if file.IsFolder && (cfg.maxDepth <= 0 || depth < cfg.maxDepth) {
+			next = append(next, walkJob{id: file.ID, path: file.Path, depth: depth})
+		}
+	}
+	return next
+}
@@ -0,0 +1,109 @@
+package cloud189
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dnslin/cloud189-desktop/core/httpclient"
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+type countingLimiter struct{ calls int32 }
+
+func (l *countingLimiter) Wait(ctx context.Context, req *http.Request) error {
+	atomic.AddInt32(&l.calls, 1)
+	return nil
+}
+
+func newWalkTree() *fakeTree {
+	tree := newFakeTree()
+	tree.children[RootFolderID] = append(tree.children[RootFolderID],
+		map[string]any{"id": "11", "name": "Music", "isFolder": true},
+		map[string]any{"id": "12", "name": "readme.txt", "size": 1},
+	)
+	tree.children["11"] = []map[string]any{{"id": "40", "name": "song.mp3", "size": 5}}
+	return tree
+}
+
+func collectWalk(t *testing.T, client *Client, fn WalkFunc, opts ...WalkOption) []string {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	err := client.Walk(context.Background(), RootFolderID, "/", func(f model.File) error {
+		mu.Lock()
+		paths = append(paths, f.Path)
+		mu.Unlock()
+		if fn != nil {
+			return fn(f)
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		t.Fatalf("遍历失败: %v", err)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// TestWalk_FullTree 验证完整遍历、路径拼接与限流器生效。
+func TestWalk_FullTree(t *testing.T) {
+	limiter := &countingLimiter{}
+	httpCli := httpclient.NewClient(httpclient.WithRateLimiter(limiter))
+	client := newTestClient(t, newWalkTree().handler(), WithHTTPClient(httpCli))
+
+	paths := collectWalk(t, client, nil, WithWalkConcurrency(2))
+	want := []string{"/Documents", "/Documents/2024", "/Documents/2024/report.pdf", "/Music", "/Music/song.mp3", "/readme.txt"}
+	if len(paths) != len(want) {
+		t.Fatalf("期望 %v，实际 %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("期望 %v，实际 %v", want, paths)
+		}
+	}
+	if atomic.LoadInt32(&limiter.calls) != 4 {
+		t.Fatalf("4 次列目录都应经过限流器，实际 %d", limiter.calls)
+	}
+}
+
+// TestWalk_SkipDirAndDepth 验证跳过子树与深度限制。
+func TestWalk_SkipDirAndDepth(t *testing.T) {
+	client := newTestClient(t, newWalkTree().handler())
+
+	paths := collectWalk(t, client, func(f model.File) error {
+		if f.Name == "Documents" {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	for _, p := range paths {
+		if p == "/Documents/2024" {
+			t.Fatalf("跳过的子树不应被遍历: %v", paths)
+		}
+	}
+
+	paths = collectWalk(t, client, nil, WithWalkMaxDepth(1))
+	if len(paths) != 3 {
+		t.Fatalf("深度 1 只应返回根目录子项，实际 %v", paths)
+	}
+}
+
+// TestWalk_SkipAll 返回 fs.SkipAll 时应停止且不报错。
+func TestWalk_SkipAll(t *testing.T) {
+	client := newTestClient(t, newWalkTree().handler())
+	visited := 0
+	err := client.Walk(context.Background(), RootFolderID, "/", func(f model.File) error {
+		visited++
+		return fs.SkipAll
+	})
+	if err != nil || visited != 1 {
+		t.Fatalf("SkipAll 应立即结束，visited=%d err=%v", visited, err)
+	}
+}
@@ -0,0 +1,494 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	coreerrors "github.com/dnslin/cloud189-desktop/core/errors"
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// Mode 上传使用的接口模式。
+type Mode int
+
+const (
+	// ModeApp 使用 App 签名接口。
+	ModeApp Mode = iota
+	// ModeWeb 使用 Web 签名接口。
+	ModeWeb
+)
+
+// emptyMD5 空文件的 MD5。
+var emptyMD5 = func() string {
+	sum := md5.Sum(nil)
+	return hex.EncodeToString(sum[:])
+}()
+
+var _ RandomAccessDrive = (*Cloud189Drive)(nil)
+
+// Cloud189Drive 基于 cloud189.Client 的个人云 Drive 实现。
+type Cloud189Drive struct {
+	client       *cloud189.Client
+	resolver     *cloud189.PathResolver
+	ownsResolver bool // 解析器由本 Drive 创建，Close 时一并关闭
+	mode         Mode
+	readOpts     []cloud189.ReaderOption
+
+	mu     sync.Mutex
+	rsaKey *cloud189.WebRSA
+}
+
+// Option 配置 Cloud189Drive。
+type Option func(*Cloud189Drive)
+
+// WithMode 设置上传接口模式，默认 ModeApp。
+func WithMode(mode Mode) Option {
+	return func(d *Cloud189Drive) {
+		d.mode = mode
+	}
+}
+
+// WithResolver 复用已有的路径解析器，便于与其他组件共享路径缓存。
+func WithResolver(resolver *cloud189.PathResolver) Option {
+	return func(d *Cloud189Drive) {
+		if resolver != nil {
+			d.resolver = resolver
+		}
+	}
+}
+
+// WithReaderOptions 设置 OpenRandom 使用的块大小、缓存与预读参数。
+func WithReaderOptions(opts ...cloud189.ReaderOption) Option {
+	return func(d *Cloud189Drive) {
+		d.readOpts = append(d.readOpts, opts...)
+	}
+}
+
+// NewCloud189Drive 创建 Drive 实现，不再使用时调用 Close 释放路径解析器的订阅。
+func NewCloud189Drive(client *cloud189.Client, opts ...Option) *Cloud189Drive {
+	d := &Cloud189Drive{client: client}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(d)
+		}
+	}
+	if d.resolver == nil {
+		d.resolver = cloud189.NewPathResolver(client)
+		d.ownsResolver = true
+	}
+	return d
+}
+
+// Close 关闭自行创建的路径解析器，经 WithResolver 共享的解析器由调用方关闭。
+func (d *Cloud189Drive) Close() error {
+	if d.ownsResolver {
+		return d.resolver.Close()
+	}
+	return nil
+}
+
+// Client 返回底层客户端。
+func (d *Cloud189Drive) Client() *cloud189.Client {
+	return d.client
+}
+
+// Resolver 返回路径解析器。
+func (d *Cloud189Drive) Resolver() *cloud189.PathResolver {
+	return d.resolver
+}
+
+// Stat 返回路径对应的文件或文件夹。
+func (d *Cloud189Drive) Stat(ctx context.Context, name string) (model.File, error) {
+	name = cloud189.CleanPath(name)
+	info, err := d.resolver.Stat(ctx, name)
+	if err != nil {
+		return model.File{}, wrapErr(name, err)
+	}
+	return toFile(*info, name), nil
+}
+
+// List 列出文件夹内的全部条目。
+func (d *Cloud189Drive) List(ctx context.Context, dir string) ([]model.File, error) {
+	dirInfo, err := d.statDir(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	items, err := d.client.ListAllFiles(ctx, dirInfo.ID)
+	if err != nil {
+		return nil, wrapErr(dirInfo.Path, err)
+	}
+	files := make([]model.File, 0, len(items))
+	for _, item := range items {
+		files = append(files, toFile(item, path.Join(dirInfo.Path, item.FileName)))
+	}
+	return files, nil
+}
+
+// Mkdir 逐级创建文件夹，已存在时直接返回。
+func (d *Cloud189Drive) Mkdir(ctx context.Context, dir string) (model.File, error) {
+	dir = cloud189.CleanPath(dir)
+	existing, err := d.Stat(ctx, dir)
+	switch {
+	case err == nil && existing.IsFolder:
+		return existing, nil
+	case err == nil:
+		return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeAlreadyExists, "drive: "+dir+" 已存在同名文件", ErrExist)
+	case !errors.Is(err, ErrNotFound):
+		return model.File{}, err
+	}
+	info, err := d.resolver.MkdirAll(ctx, dir)
+	if err != nil {
+		return model.File{}, wrapErr(dir, err)
+	}
+	return toFile(*info, dir), nil
+}
+
+// Move 将文件或文件夹移动到 dstDir 下，名称不变。
+func (d *Cloud189Drive) Move(ctx context.Context, name, dstDir string) (model.File, error) {
+	src, dst, target, err := d.prepareTransfer(ctx, name, dstDir)
+	if err != nil {
+		return model.File{}, err
+	}
+	if src.ParentPath == dst.Path {
+		return src, nil
+	}
+	if err := d.client.MoveFiles(ctx, []cloud189.FileInfo{cloud189.FileInfoFromModel(src)}, dst.ID); err != nil {
+		return model.File{}, wrapErr(name, err)
+	}
+	d.resolver.Invalidate(src.Path)
+	return d.Stat(ctx, target)
+}
+
+// Copy 将文件或文件夹复制到 dstDir 下，名称不变。
+func (d *Cloud189Drive) Copy(ctx context.Context, name, dstDir string) (model.File, error) {
+	src, dst, target, err := d.prepareTransfer(ctx, name, dstDir)
+	if err != nil {
+		return model.File{}, err
+	}
+	if src.ParentPath == dst.Path {
+		return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeAlreadyExists, "drive: "+target+" 已存在", ErrExist)
+	}
+	if err := d.client.CopyFiles(ctx, []cloud189.FileInfo{cloud189.FileInfoFromModel(src)}, dst.ID); err != nil {
+		return model.File{}, wrapErr(name, err)
+	}
+	return d.Stat(ctx, target)
+}
+
+// Remove 删除文件或文件夹（移入回收站）。
+func (d *Cloud189Drive) Remove(ctx context.Context, name string) error {
+	file, err := d.Stat(ctx, name)
+	if err != nil {
+		return err
+	}
+	if file.Path == "/" {
+		return coreerrors.New(coreerrors.ErrCodeInvalidArgument, "drive: 不能删除根目录")
+	}
+	if err := d.client.DeleteFiles(ctx, []cloud189.FileInfo{cloud189.FileInfoFromModel(file)}); err != nil {
+		return wrapErr(file.Path, err)
+	}
+	d.resolver.Invalidate(file.Path)
+	return nil
+}
+
+// Rename 在原目录内重命名。
+func (d *Cloud189Drive) Rename(ctx context.Context, name, newName string) (model.File, error) {
+	if newName == "" || newName != path.Base(newName) || newName == "." || newName == ".." {
+		return model.File{}, coreerrors.New(coreerrors.ErrCodeInvalidArgument, "drive: 新名称非法 "+newName)
+	}
+	file, err := d.Stat(ctx, name)
+	if err != nil {
+		return model.File{}, err
+	}
+	if file.Name == newName {
+		return file, nil
+	}
+	target := path.Join(file.ParentPath, newName)
+	if err := d.ensureAbsent(ctx, target); err != nil {
+		return model.File{}, err
+	}
+	if err := d.client.RenameFile(ctx, file.ID, newName); err != nil {
+		return model.File{}, wrapErr(file.Path, err)
+	}
+	d.resolver.Invalidate(file.Path)
+	return d.Stat(ctx, target)
+}
+
+// Open 打开文件内容流，调用方负责关闭。
+func (d *Cloud189Drive) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	file, err := d.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsFolder {
+		return nil, coreerrors.Wrap(coreerrors.ErrCodeInvalidArgument, "drive: "+file.Path+" 是文件夹", ErrIsDir)
+	}
+	downloadURL, err := d.client.GetDownloadURL(ctx, file.ID)
+	if err != nil {
+		return nil, wrapErr(file.Path, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, coreerrors.Wrap(coreerrors.ErrCodeInvalidState, "drive: 下载链接无效", err)
+	}
+	resp, err := d.client.HTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, coreerrors.New(coreerrors.ErrCodeInvalidState, fmt.Sprintf("drive: 下载 %s 失败，状态码=%d", file.Path, resp.StatusCode))
+	}
+	return resp.Body, nil
+}
+
+// OpenRandom 打开支持 Range 读取的文件内容，调用方负责关闭。
+func (d *Cloud189Drive) OpenRandom(ctx context.Context, name string) (RandomReader, error) {
+	file, err := d.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsFolder {
+		return nil, coreerrors.Wrap(coreerrors.ErrCodeInvalidArgument, "drive: "+file.Path+" 是文件夹", ErrIsDir)
+	}
+	r, err := d.client.OpenRemoteReader(ctx, file.ID, file.Size, d.readOpts...)
+	if err != nil {
+		return nil, wrapErr(file.Path, err)
+	}
+	return r, nil
+}
+
+// Create 上传文件，已存在同名文件时覆盖；size < 0 表示大小未知，此时先暂存到临时文件以确定大小。
+func (d *Cloud189Drive) Create(ctx context.Context, name string, r io.Reader, size int64) (model.File, error) {
+	name = cloud189.CleanPath(name)
+	if name == "/" {
+		return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeInvalidArgument, "drive: 根目录不能作为文件", ErrIsDir)
+	}
+	if r == nil {
+		r = bytes.NewReader(nil)
+	}
+	parent, err := d.statDir(ctx, path.Dir(name))
+	if err != nil {
+		return model.File{}, err
+	}
+	if existing, err := d.Stat(ctx, name); err == nil && existing.IsFolder {
+		return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeInvalidArgument, "drive: "+name+" 是文件夹", ErrIsDir)
+	}
+	if size < 0 {
+		tmp, n, err := spool(r)
+		if err != nil {
+			return model.File{}, wrapErr(name, err)
+		}
+		defer closeSpool(tmp)
+		r, size = tmp, n
+	}
+	info, written, err := d.upload(ctx, parent.ID, path.Base(name), r, size)
+	if err != nil {
+		return model.File{}, wrapErr(name, err)
+	}
+	d.resolver.Invalidate(name)
+	file := toFile(*info, name)
+	file.ParentID = parent.ID
+	file.Size = written
+	return file, nil
+}
+
+// spool 将长度未知的数据写入临时文件并回到开头，返回文件与总字节数。
+func spool(r io.Reader) (*os.File, int64, error) {
+	tmp, err := os.CreateTemp("", "cloud189-upload-*")
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		closeSpool(tmp)
+		return nil, 0, err
+	}
+	return tmp, n, nil
+}
+
+// closeSpool 关闭并删除 spool 创建的临时文件。
+func closeSpool(f *os.File) {
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
+
+// upload 按固定分片顺序上传，返回文件信息与实际写入字节数，size 必须是数据的准确长度。
+func (d *Cloud189Drive) upload(ctx context.Context, parentID, filename string, r io.Reader, size int64) (*cloud189.FileInfo, int64, error) {
+	var (
+		session *cloud189.UploadSession
+		rsaKey  *cloud189.WebRSA
+		err     error
+	)
+	if d.mode == ModeWeb {
+		if rsaKey, err = d.webRSA(ctx); err != nil {
+			return nil, 0, err
+		}
+		session, err = d.client.WebInitUpload(ctx, parentID, filename, size, rsaKey)
+	} else {
+		session, err = d.client.InitUpload(ctx, parentID, filename, size)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	session.Overwrite = true
+
+	var written int64
+	r = io.LimitReader(r, size)
+	buf := make([]byte, cloud189.DefaultSliceSize)
+	for part := 1; ; part++ {
+		n, readErr := fillSlice(r, buf)
+		if readErr != nil && readErr != io.EOF {
+			return nil, written, readErr
+		}
+		if n > 0 {
+			var err error
+			if d.mode == ModeWeb {
+				err = d.client.WebUploadPart(ctx, session, part, bytes.NewReader(buf[:n]), rsaKey)
+			} else {
+				err = d.client.UploadPart(ctx, session, part, bytes.NewReader(buf[:n]))
+			}
+			if err != nil {
+				return nil, written, err
+			}
+			written += int64(n)
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if written != size {
+		// 数据源提前结束时不能提交，否则残缺内容会覆盖已有文件
+		return nil, written, coreerrors.Wrap(coreerrors.ErrCodeInvalidArgument,
+			fmt.Sprintf("drive: %s 上传数据不完整，期望 %d 字节，实际 %d 字节", filename, size, written), io.ErrUnexpectedEOF)
+	}
+	if written == 0 {
+		session.FileMD5 = emptyMD5
+		session.SliceMD5 = emptyMD5
+	}
+	var info *cloud189.FileInfo
+	if d.mode == ModeWeb {
+		info, err = d.client.WebCommitUpload(ctx, session, rsaKey)
+	} else {
+		info, err = d.client.CommitUpload(ctx, session)
+	}
+	if err != nil {
+		return nil, written, err
+	}
+	return info, written, nil
+}
+
+// fillSlice 读满 buf 或读到数据末尾；数据读尽时返回 io.EOF，数据源的其他错误原样返回。
+func fillSlice(r io.Reader, buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		m, err := r.Read(buf[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// webRSA 获取并缓存 Web 上传公钥，临近过期时重新获取。
+func (d *Cloud189Drive) webRSA(ctx context.Context) (*cloud189.WebRSA, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.rsaKey != nil && (d.rsaKey.Expire == 0 || time.Now().Add(time.Minute).UnixMilli() < d.rsaKey.Expire) {
+		return d.rsaKey, nil
+	}
+	key, err := d.client.FetchWebRSA(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.rsaKey = key
+	return key, nil
+}
+
+func (d *Cloud189Drive) statDir(ctx context.Context, dir string) (model.File, error) {
+	file, err := d.Stat(ctx, dir)
+	if err != nil {
+		return model.File{}, err
+	}
+	if !file.IsFolder {
+		return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeInvalidArgument, "drive: "+file.Path+" 不是文件夹", ErrNotDir)
+	}
+	return file, nil
+}
+
+// prepareTransfer 校验移动/复制的源与目标，返回目标完整路径。
+func (d *Cloud189Drive) prepareTransfer(ctx context.Context, name, dstDir string) (model.File, model.File, string, error) {
+	src, err := d.Stat(ctx, name)
+	if err != nil {
+		return model.File{}, model.File{}, "", err
+	}
+	if src.Path == "/" {
+		return model.File{}, model.File{}, "", coreerrors.New(coreerrors.ErrCodeInvalidArgument, "drive: 不能移动或复制根目录")
+	}
+	dst, err := d.statDir(ctx, dstDir)
+	if err != nil {
+		return model.File{}, model.File{}, "", err
+	}
+	if dst.Path == src.Path || (src.IsFolder && len(dst.Path) > len(src.Path) && dst.Path[:len(src.Path)+1] == src.Path+"/") {
+		return model.File{}, model.File{}, "", coreerrors.New(coreerrors.ErrCodeInvalidArgument, "drive: 不能移动或复制到自身子目录")
+	}
+	target := path.Join(dst.Path, src.Name)
+	if src.ParentPath != dst.Path {
+		if err := d.ensureAbsent(ctx, target); err != nil {
+			return model.File{}, model.File{}, "", err
+		}
+	}
+	return src, dst, target, nil
+}
+
+func (d *Cloud189Drive) ensureAbsent(ctx context.Context, name string) error {
+	_, err := d.Stat(ctx, name)
+	switch {
+	case err == nil:
+		return coreerrors.Wrap(coreerrors.ErrCodeAlreadyExists, "drive: "+name+" 已存在", ErrExist)
+	case errors.Is(err, ErrNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+// toFile 转换为领域模型并补全路径。
+func toFile(info cloud189.FileInfo, name string) model.File {
+	file := info.ToModel()
+	file.Path = name
+	if name == "/" {
+		file.Name = "/"
+		file.ParentPath = ""
+		file.IsFolder = true
+		return file
+	}
+	if file.Name == "" {
+		file.Name = path.Base(name)
+	}
+	file.ParentPath = path.Dir(name)
+	return file
+}
+
+// wrapErr 将云端“不存在”映射为 ErrNotFound，其余错误原样返回。
+func wrapErr(name string, err error) error {
+	var ce *cloud189.CloudError
+	if errors.As(err, &ce) && ce.Code == cloud189.ErrCodeFileNotFound {
+		return coreerrors.Wrap(coreerrors.ErrCodeNotFound, "drive: "+name+" 不存在", err)
+	}
+	return err
+}
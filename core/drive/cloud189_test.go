@@ -0,0 +1,153 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dnslin/cloud189-desktop/core/internal/fakecloud"
+)
+
+func newTestDrive(t *testing.T) (*Cloud189Drive, *fakecloud.Server) {
+	t.Helper()
+	srv := fakecloud.New(t)
+	docs := srv.AddFolder(fakecloud.RootID, "Documents")
+	srv.AddFile(docs, "report.pdf", []byte("report"))
+	srv.AddFolder(fakecloud.RootID, "Archive")
+	return NewCloud189Drive(srv.Client(t)), srv
+}
+
+// TestCloud189Drive_CreateOpenRoundTrip 上传后可按路径读取，重复上传覆盖原文件。
+func TestCloud189Drive_CreateOpenRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	d, srv := newTestDrive(t)
+
+	file, err := d.Create(ctx, "/Documents/note.txt", strings.NewReader("hello"), 5)
+	if err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	if file.Path != "/Documents/note.txt" || file.ParentPath != "/Documents" || file.Size != 5 || file.ID == "" {
+		t.Fatalf("返回文件信息异常: %+v", file)
+	}
+	if _, err := d.Create(ctx, "/Documents/note.txt", strings.NewReader("hello, world"), -1); err != nil {
+		t.Fatalf("覆盖上传失败: %v", err)
+	}
+	rc, err := d.Open(ctx, "/Documents/note.txt")
+	if err != nil {
+		t.Fatalf("打开失败: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "hello, world" {
+		t.Fatalf("内容不符: %q", data)
+	}
+	files, err := d.List(ctx, "/Documents")
+	if err != nil || len(files) != 2 {
+		t.Fatalf("覆盖后应只有两个文件: %v %+v", err, files)
+	}
+
+	empty, err := d.Create(ctx, "/empty.txt", bytes.NewReader(nil), 0)
+	if err != nil || empty.Size != 0 {
+		t.Fatalf("空文件上传失败: %v %+v", err, empty)
+	}
+	if node, ok := srv.Lookup("/empty.txt"); !ok || len(node.Data) != 0 {
+		t.Fatalf("云端应存在空文件")
+	}
+	if _, err := d.Open(ctx, "/Documents"); !errors.Is(err, ErrIsDir) {
+		t.Fatalf("打开文件夹应返回 ErrIsDir，实际 %v", err)
+	}
+}
+
+// truncatedReader 返回部分数据后以 io.ErrUnexpectedEOF 结束，模拟中途断开的响应体。
+type truncatedReader struct {
+	data []byte
+}
+
+func (r *truncatedReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// TestCloud189Drive_CreateTruncated 数据源提前断开或不足声明大小时不提交，保留原文件。
+func TestCloud189Drive_CreateTruncated(t *testing.T) {
+	ctx := context.Background()
+	d, srv := newTestDrive(t)
+
+	if _, err := d.Create(ctx, "/Documents/report.pdf", &truncatedReader{data: []byte("rep")}, 6); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("数据源断开应返回 io.ErrUnexpectedEOF，实际 %v", err)
+	}
+	if _, err := d.Create(ctx, "/Documents/report.pdf", strings.NewReader("rep"), 6); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("数据不足应返回 io.ErrUnexpectedEOF，实际 %v", err)
+	}
+	if hits := srv.Hits("/person/commitMultiUploadFile"); hits != 0 {
+		t.Fatalf("残缺数据不应提交，实际提交 %d 次", hits)
+	}
+	node, ok := srv.Lookup("/Documents/report.pdf")
+	if !ok || string(node.Data) != "report" {
+		t.Fatalf("原文件应保持不变: %v %+v", ok, node)
+	}
+}
+
+// TestCloud189Drive_Operations 覆盖 Mkdir/Rename/Move/Copy/Remove 与错误匹配。
+func TestCloud189Drive_Operations(t *testing.T) {
+	ctx := context.Background()
+	d, srv := newTestDrive(t)
+
+	dir, err := d.Mkdir(ctx, "/Archive/2024/Q1")
+	if err != nil || !dir.IsFolder || dir.Path != "/Archive/2024/Q1" {
+		t.Fatalf("逐级创建失败: %v %+v", err, dir)
+	}
+	if again, err := d.Mkdir(ctx, "/Archive/2024/Q1"); err != nil || again.ID != dir.ID {
+		t.Fatalf("已存在时应直接返回: %v %+v", err, again)
+	}
+	if _, err := d.Mkdir(ctx, "/Documents/report.pdf"); !errors.Is(err, ErrExist) {
+		t.Fatalf("同名文件应返回 ErrExist，实际 %v", err)
+	}
+
+	renamed, err := d.Rename(ctx, "/Documents/report.pdf", "summary.pdf")
+	if err != nil || renamed.Path != "/Documents/summary.pdf" {
+		t.Fatalf("重命名失败: %v %+v", err, renamed)
+	}
+	if _, err := d.Stat(ctx, "/Documents/report.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("旧路径应返回 ErrNotFound，实际 %v", err)
+	}
+
+	copied, err := d.Copy(ctx, "/Documents/summary.pdf", "/Archive/2024")
+	if err != nil || copied.Path != "/Archive/2024/summary.pdf" || copied.ID == renamed.ID {
+		t.Fatalf("复制失败: %v %+v", err, copied)
+	}
+	if _, err := d.Copy(ctx, "/Documents/summary.pdf", "/Archive/2024"); !errors.Is(err, ErrExist) {
+		t.Fatalf("目标已存在时应返回 ErrExist，实际 %v", err)
+	}
+	if _, err := d.Move(ctx, "/Archive", "/Archive/2024"); err == nil {
+		t.Fatalf("移动到自身子目录应失败")
+	}
+
+	moved, err := d.Move(ctx, "/Documents/summary.pdf", "/Archive")
+	if err != nil || moved.Path != "/Archive/summary.pdf" || moved.ID != renamed.ID {
+		t.Fatalf("移动失败: %v %+v", err, moved)
+	}
+	if _, ok := srv.Lookup("/Documents/summary.pdf"); ok {
+		t.Fatalf("移动后原位置不应存在")
+	}
+
+	if err := d.Remove(ctx, "/Archive/2024"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if _, err := d.Stat(ctx, "/Archive/2024/Q1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("删除后子路径应返回 ErrNotFound，实际 %v", err)
+	}
+	if _, err := d.List(ctx, "/Archive/summary.pdf"); !errors.Is(err, ErrNotDir) {
+		t.Fatalf("列出文件应返回 ErrNotDir，实际 %v", err)
+	}
+	if err := d.Remove(ctx, "/"); err == nil {
+		t.Fatalf("不应允许删除根目录")
+	}
+}
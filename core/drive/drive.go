@@ -0,0 +1,60 @@
+// Package drive 定义面向 TUI/GUI 的统一云盘接口，屏蔽 App/Web 接口差异。
+package drive
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	coreerrors "github.com/dnslin/cloud189-desktop/core/errors"
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// 错误定义，可通过 errors.Is 按错误码匹配。
+var (
+	ErrNotFound = coreerrors.New(coreerrors.ErrCodeNotFound, "drive: 文件不存在")
+	ErrExist    = coreerrors.New(coreerrors.ErrCodeAlreadyExists, "drive: 文件已存在")
+)
+
+// 类型不符的错误以 ErrCodeInvalidArgument 包装返回，需按实例匹配以区分二者。
+var (
+	ErrNotDir = errors.New("drive: 不是文件夹")
+	ErrIsDir  = errors.New("drive: 是文件夹")
+)
+
+// Drive 以路径操作云盘的统一接口，路径均为以 / 开头的远端绝对路径。
+// 返回的 model.File 会填充 Path 与 ParentPath。
+type Drive interface {
+	// Stat 返回路径对应的文件或文件夹。
+	Stat(ctx context.Context, name string) (model.File, error)
+	// List 列出文件夹内的全部条目。
+	List(ctx context.Context, dir string) ([]model.File, error)
+	// Mkdir 逐级创建文件夹，已存在时直接返回。
+	Mkdir(ctx context.Context, dir string) (model.File, error)
+	// Move 将文件或文件夹移动到 dstDir 下，名称不变。
+	Move(ctx context.Context, name, dstDir string) (model.File, error)
+	// Copy 将文件或文件夹复制到 dstDir 下，名称不变。
+	Copy(ctx context.Context, name, dstDir string) (model.File, error)
+	// Remove 删除文件或文件夹（移入回收站）。
+	Remove(ctx context.Context, name string) error
+	// Rename 在原目录内重命名。
+	Rename(ctx context.Context, name, newName string) (model.File, error)
+	// Open 打开文件内容流，调用方负责关闭。
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Create 上传文件，已存在同名文件时覆盖；size < 0 表示大小未知。
+	Create(ctx context.Context, name string, r io.Reader, size int64) (model.File, error)
+}
+
+// RandomReader 可随机读取的文件内容。
+type RandomReader interface {
+	io.ReadSeeker
+	io.ReaderAt
+	io.Closer
+}
+
+// RandomAccessDrive 支持随机读取的 Drive，WebDAV、流媒体等需要 Range 的场景优先使用。
+type RandomAccessDrive interface {
+	Drive
+	// OpenRandom 打开可随机读取的文件内容，调用方负责关闭。
+	OpenRandom(ctx context.Context, name string) (RandomReader, error)
+}
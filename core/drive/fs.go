@@ -0,0 +1,331 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+var (
+	_ fs.FS          = (*FS)(nil)
+	_ fs.ReadDirFS   = (*FS)(nil)
+	_ fs.StatFS      = (*FS)(nil)
+	_ fs.ReadDirFile = (*fsDir)(nil)
+	_ io.ReadSeeker  = (*fsFile)(nil)
+	_ io.ReaderAt    = (*fsFile)(nil)
+)
+
+// FS 将 Drive 适配为只读的 io/fs 文件系统，便于复用 fs.WalkDir、http.FS 等标准库工具。
+// 路径遵循 io/fs 约定：不以 / 开头，"." 表示云盘根目录。文件内容在首次读取时才开始下载，
+// 打开的文件实现 io.Seeker 与 io.ReaderAt，可直接用于 http.FileServer 的 Range 请求。
+type FS struct {
+	ctx   context.Context
+	drive Drive
+}
+
+// NewFS 创建文件系统，ctx 作用于其后全部远端请求。
+func NewFS(ctx context.Context, d Drive) *FS {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &FS{ctx: ctx, drive: d}
+}
+
+// Open 打开文件或目录。
+func (f *FS) Open(name string) (fs.File, error) {
+	file, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsFolder {
+		return &fsDir{fsys: f, name: name, info: fileInfo{file: file, name: name}}, nil
+	}
+	return &fsFile{fsys: f, name: name, info: fileInfo{file: file, name: name}}, nil
+}
+
+// Stat 返回文件信息。
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	file, err := f.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo{file: file, name: name}, nil
+}
+
+// ReadDir 读取目录并按文件名排序。
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	files, err := f.drive.List(f.ctx, remotePath(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fsErr(err)}
+	}
+	entries := make([]fs.DirEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, fs.FileInfoToDirEntry(fileInfo{file: file, name: file.Name}))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (f *FS) stat(op, name string) (model.File, error) {
+	if !fs.ValidPath(name) {
+		return model.File{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	file, err := f.drive.Stat(f.ctx, remotePath(name))
+	if err != nil {
+		return model.File{}, &fs.PathError{Op: op, Path: name, Err: fsErr(err)}
+	}
+	return file, nil
+}
+
+// remotePath 将 io/fs 路径转换为远端绝对路径。
+func remotePath(name string) string {
+	if name == "." {
+		return "/"
+	}
+	return "/" + name
+}
+
+// fsErr 将 Drive 错误映射为 io/fs 约定的错误，便于 errors.Is(err, fs.ErrNotExist)。
+func fsErr(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return errors.Join(fs.ErrNotExist, err)
+	case errors.Is(err, ErrExist):
+		return errors.Join(fs.ErrExist, err)
+	default:
+		return err
+	}
+}
+
+// fileInfo 实现 fs.FileInfo，Sys 返回 model.File。
+type fileInfo struct {
+	file model.File
+	name string
+}
+
+func (i fileInfo) Name() string {
+	return path.Base(i.name)
+}
+
+func (i fileInfo) Size() int64 {
+	if i.file.IsFolder {
+		return 0
+	}
+	return i.file.Size
+}
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.file.IsFolder {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (i fileInfo) ModTime() time.Time {
+	return i.file.UpdatedAt
+}
+
+func (i fileInfo) IsDir() bool {
+	return i.file.IsFolder
+}
+
+func (i fileInfo) Sys() any {
+	return i.file
+}
+
+// fsFile 远端文件，首次读取或定位时才打开内容。
+// Drive 实现 RandomAccessDrive 时读取、定位与 ReadAt 均委托给 OpenRandom 的读取器；
+// 否则以顺序下载流模拟定位：向后定位跳过数据，向前定位重新打开下载流。
+type fsFile struct {
+	fsys   *FS
+	name   string
+	info   fileInfo
+	random RandomReader
+	body   io.ReadCloser
+	pos    int64 // body 已读取到的位置
+	offset int64 // 非随机读取时的当前偏移
+	closed bool
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	if f.closed {
+		return nil, &fs.PathError{Op: "stat", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.info, nil
+}
+
+// openRandom 在 Drive 支持随机读取时打开读取器，不支持时返回 false。
+func (f *fsFile) openRandom(op string) (bool, error) {
+	if f.random != nil {
+		return true, nil
+	}
+	d, ok := f.fsys.drive.(RandomAccessDrive)
+	if !ok {
+		return false, nil
+	}
+	r, err := d.OpenRandom(f.fsys.ctx, f.info.file.Path)
+	if err != nil {
+		return true, &fs.PathError{Op: op, Path: f.name, Err: fsErr(err)}
+	}
+	f.random = r
+	return true, nil
+}
+
+func (f *fsFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	if ok, err := f.openRandom("read"); ok {
+		if err != nil {
+			return 0, err
+		}
+		return f.random.Read(p)
+	}
+	if f.offset >= f.info.Size() {
+		return 0, io.EOF
+	}
+	if f.body != nil && f.pos > f.offset {
+		_ = f.body.Close()
+		f.body = nil
+	}
+	if f.body == nil {
+		body, err := f.fsys.drive.Open(f.fsys.ctx, f.info.file.Path)
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.name, Err: fsErr(err)}
+		}
+		f.body, f.pos = body, 0
+	}
+	if f.pos < f.offset {
+		n, err := io.CopyN(io.Discard, f.body, f.offset-f.pos)
+		f.pos += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.body.Read(p)
+	f.pos += int64(n)
+	f.offset = f.pos
+	return n, err
+}
+
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrClosed}
+	}
+	if ok, err := f.openRandom("seek"); ok {
+		if err != nil {
+			return 0, err
+		}
+		return f.random.Seek(offset, whence)
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.Size()
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// ReadAt 仅在 Drive 支持随机读取时可用。
+func (f *fsFile) ReadAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	ok, err := f.openRandom("read")
+	if !ok {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: errors.ErrUnsupported}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return f.random.ReadAt(p, off)
+}
+
+func (f *fsFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	var err error
+	if f.random != nil {
+		err = f.random.Close()
+	}
+	if f.body != nil {
+		err = errors.Join(err, f.body.Close())
+	}
+	return err
+}
+
+// fsDir 远端目录，首次 ReadDir 时拉取全部条目。
+type fsDir struct {
+	fsys    *FS
+	name    string
+	info    fileInfo
+	entries []fs.DirEntry
+	loaded  bool
+	offset  int
+	closed  bool
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "stat", Path: d.name, Err: fs.ErrClosed}
+	}
+	return d.info, nil
+}
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: ErrIsDir}
+}
+
+// ReadDir 遵循 fs.ReadDirFile 语义：n > 0 时分批返回，读尽后返回 io.EOF。
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: fs.ErrClosed}
+	}
+	if !d.loaded {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.loaded = entries, true
+	}
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
+
+func (d *fsDir) Close() error {
+	if d.closed {
+		return &fs.PathError{Op: "close", Path: d.name, Err: fs.ErrClosed}
+	}
+	d.closed = true
+	return nil
+}
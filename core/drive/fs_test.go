@@ -0,0 +1,92 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dnslin/cloud189-desktop/core/internal/fakecloud"
+)
+
+// TestFS_StdlibConformance 远端目录树应通过标准库 fstest 一致性校验。
+func TestFS_StdlibConformance(t *testing.T) {
+	srv := fakecloud.New(t)
+	docs := srv.AddFolder(fakecloud.RootID, "Documents")
+	year := srv.AddFolder(docs, "2024")
+	srv.AddFile(year, "report.pdf", []byte("quarterly report"))
+	srv.AddFile(docs, "notes.txt", []byte("hello"))
+	srv.AddFile(fakecloud.RootID, "empty.txt", nil)
+	srv.AddFolder(fakecloud.RootID, "Empty")
+
+	fsys := NewFS(context.Background(), NewCloud189Drive(srv.Client(t)))
+	if err := fstest.TestFS(fsys, "Documents/2024/report.pdf", "Documents/notes.txt", "empty.txt", "Empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFile(fsys, "Documents/notes.txt")
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("读取内容不符: %v %q", err, data)
+	}
+	if _, err := fsys.Stat("Documents/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("不存在的路径应匹配 fs.ErrNotExist，实际 %v", err)
+	}
+	info, err := fsys.Stat("Documents")
+	if err != nil || !info.IsDir() || info.Mode()&fs.ModeDir == 0 {
+		t.Fatalf("目录信息异常: %v %v", err, info)
+	}
+}
+
+// TestFS_FileServerRange http.FileServer 可对无扩展名文件嗅探类型并响应 Range 请求，
+// 仅支持顺序读取的 Drive 同样可用。
+func TestFS_FileServerRange(t *testing.T) {
+	srv := fakecloud.New(t)
+	content := strings.Repeat("0123456789", 100)
+	srv.AddFile(fakecloud.RootID, "README", []byte(content))
+	d := NewCloud189Drive(srv.Client(t))
+
+	cases := []struct {
+		name  string
+		drive Drive
+	}{
+		{"random", d},
+		{"sequential", struct{ Drive }{d}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.FileServer(http.FS(NewFS(context.Background(), tc.drive))))
+			defer ts.Close()
+			get := func(rng string) (*http.Response, string) {
+				req, _ := http.NewRequest(http.MethodGet, ts.URL+"/README", nil)
+				if rng != "" {
+					req.Header.Set("Range", rng)
+				}
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatalf("请求失败: %v", err)
+				}
+				defer resp.Body.Close()
+				body, _ := io.ReadAll(resp.Body)
+				return resp, string(body)
+			}
+
+			resp, body := get("")
+			if resp.StatusCode != http.StatusOK || body != content || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+				t.Fatalf("完整读取异常: %d %q %q", resp.StatusCode, resp.Header.Get("Content-Type"), body[:min(len(body), 20)])
+			}
+			resp, body = get("bytes=512-521")
+			if resp.StatusCode != http.StatusPartialContent || body != content[512:522] {
+				t.Fatalf("Range 读取异常: %d %q", resp.StatusCode, body)
+			}
+			resp, body = get("bytes=-5")
+			if resp.StatusCode != http.StatusPartialContent || body != content[len(content)-5:] {
+				t.Fatalf("后缀 Range 读取异常: %d %q", resp.StatusCode, body)
+			}
+		})
+	}
+}
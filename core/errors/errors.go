@@ -10,6 +10,8 @@ const (
 	ErrCodeUnknown Code = "UNKNOWN"
 	// ErrCodeNotFound 表示目标不存在。
 	ErrCodeNotFound Code = "NOT_FOUND"
+	// ErrCodeAlreadyExists 表示目标已存在。
+	ErrCodeAlreadyExists Code = "ALREADY_EXISTS"
 	// ErrCodeInvalidArgument 表示输入参数非法或缺失。
 	ErrCodeInvalidArgument Code = "INVALID_ARGUMENT"
 	// ErrCodeInvalidConfig 表示依赖未配置或状态异常。
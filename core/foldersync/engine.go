@@ -0,0 +1,369 @@
+// Package foldersync 实现本地文件夹与云端文件夹的双向同步。
+//
+// 每次 Run 扫描两侧目录树，与状态库中上次同步完成时的记录比较：本地按大小、修改时间
+// 与 MD5 判断变化，云端按修订号、大小与 MD5 判断变化。只有一侧变化时同步到另一侧，
+// 两侧都变化且内容不同即为冲突，按 ConflictPolicy 处理。上传与下载通过 task.Manager 执行。
+package foldersync
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	"github.com/dnslin/cloud189-desktop/core/drive"
+	coreerrors "github.com/dnslin/cloud189-desktop/core/errors"
+	"github.com/dnslin/cloud189-desktop/core/model"
+	"github.com/dnslin/cloud189-desktop/core/store"
+	"github.com/dnslin/cloud189-desktop/core/task"
+)
+
+// tempSuffix 下载中的临时文件后缀，扫描本地目录时忽略。
+const tempSuffix = ".c189sync-tmp"
+
+// ConflictPolicy 两侧都修改同一文件时的处理策略。
+type ConflictPolicy int
+
+const (
+	// KeepBoth 保留双方：本地版本改名为冲突副本并上传，云端版本下载到原路径。
+	KeepBoth ConflictPolicy = iota
+	// NewerWins 修改时间较新的一侧覆盖另一侧，时间相同时以云端为准。
+	NewerWins
+	// LocalWins 本地版本覆盖云端。
+	LocalWins
+	// RemoteWins 云端版本覆盖本地。
+	RemoteWins
+)
+
+// String 返回策略的字符串表示。
+func (p ConflictPolicy) String() string {
+	switch p {
+	case KeepBoth:
+		return "keep-both"
+	case NewerWins:
+		return "newer-wins"
+	case LocalWins:
+		return "local-wins"
+	case RemoteWins:
+		return "remote-wins"
+	default:
+		return "unknown"
+	}
+}
+
+// Pair 一组同步目录。
+type Pair struct {
+	ID        string // 状态库中区分目录对的标识
+	LocalDir  string // 本地目录，首次同步时不存在则自动创建
+	RemoteDir string // 云端目录，以 / 开头，首次同步时不存在则自动创建
+}
+
+// ItemError 单个条目同步失败，不影响其他条目。
+type ItemError struct {
+	Path string
+	Err  error
+}
+
+func (e ItemError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Report 一次同步的结果，路径均相对同步根目录。
+type Report struct {
+	Uploaded      []string
+	Downloaded    []string
+	DeletedLocal  []string
+	DeletedRemote []string
+	Conflicts     []string
+	Failed        []ItemError
+}
+
+// Engine 双向同步引擎，同一实例的 Run 串行执行。
+type Engine struct {
+	client  *cloud189.Client
+	drive   *drive.Cloud189Drive
+	manager *task.Manager
+	state   store.SyncStateStore
+	pair    Pair
+	policy  ConflictPolicy
+	exclude []string
+	now     func() time.Time
+
+	unsubscribe func()
+
+	runMu   sync.Mutex
+	mu      sync.Mutex
+	changed chan struct{} // 任务有进度更新时关闭并替换，用于唤醒等待者
+}
+
+// Option 配置 Engine。
+type Option func(*Engine)
+
+// WithConflictPolicy 设置冲突处理策略，默认 KeepBoth。
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(e *Engine) {
+		e.policy = policy
+	}
+}
+
+// WithExclude 按名称排除文件或文件夹，语法同 path.Match，如 ".DS_Store"、"*.tmp"。
+func WithExclude(patterns ...string) Option {
+	return func(e *Engine) {
+		e.exclude = append(e.exclude, patterns...)
+	}
+}
+
+// NewEngine 创建同步引擎，不再使用时调用 Close 取消对 manager 的订阅。
+func NewEngine(client *cloud189.Client, manager *task.Manager, st store.SyncStateStore, pair Pair, opts ...Option) *Engine {
+	e := &Engine{
+		client:  client,
+		drive:   drive.NewCloud189Drive(client),
+		manager: manager,
+		state:   st,
+		pair:    pair,
+		now:     time.Now,
+		changed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	if manager != nil {
+		e.unsubscribe = manager.Subscribe(e.onTaskUpdate)
+	}
+	return e
+}
+
+// Close 取消对 manager 的进度订阅并关闭内部的 Drive，不影响 manager 中的其他任务。
+func (e *Engine) Close() error {
+	if e.unsubscribe != nil {
+		e.unsubscribe()
+	}
+	return e.drive.Close()
+}
+
+// Run 执行一次双向同步。扫描失败时返回错误，单个条目的失败记录在 Report.Failed 中。
+func (e *Engine) Run(ctx context.Context) (*Report, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	records, err := e.state.LoadRecords(e.pair.ID)
+	if err != nil {
+		return nil, err
+	}
+	// 云端可能被其他客户端修改，每次同步都重新解析路径。
+	e.drive.Resolver().Reset()
+	remoteRoot := cloud189.CleanPath(e.pair.RemoteDir)
+	root, err := e.prepareRoots(ctx, remoteRoot, len(records) > 0)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := e.scanLocal()
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := e.scanRemote(ctx, root.ID, remoteRoot)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*store.SyncRecord, len(records))
+	for i := range records {
+		byPath[records[i].Path] = &records[i]
+	}
+
+	p := e.plan(locals, remotes, byPath)
+	report := e.execute(ctx, p)
+	if err := e.state.SaveRecords(e.pair.ID, p.records); err != nil {
+		return report, err
+	}
+	if err := e.state.DeleteRecords(e.pair.ID, p.drops); err != nil {
+		return report, err
+	}
+	return report, ctx.Err()
+}
+
+// prepareRoots 首次同步时创建两侧根目录；已有同步记录时根目录缺失视为错误，
+// 避免把整个目录被删除或未挂载误判为删除全部条目并传播到另一侧。
+func (e *Engine) prepareRoots(ctx context.Context, remoteRoot string, synced bool) (model.File, error) {
+	if !synced {
+		root, err := e.drive.Mkdir(ctx, remoteRoot)
+		if err != nil {
+			return model.File{}, err
+		}
+		if err := os.MkdirAll(e.pair.LocalDir, 0o755); err != nil {
+			return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeUnknown, "foldersync: 创建本地目录失败", err)
+		}
+		return root, nil
+	}
+	root, err := e.drive.Stat(ctx, remoteRoot)
+	if errors.Is(err, drive.ErrNotFound) {
+		return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeNotFound, "foldersync: 云端同步目录 "+remoteRoot+" 不存在", err)
+	}
+	if err != nil {
+		return model.File{}, err
+	}
+	if !root.IsFolder {
+		return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeInvalidState, "foldersync: 云端同步目录 "+remoteRoot+" 不是文件夹", drive.ErrNotDir)
+	}
+	info, err := os.Stat(e.pair.LocalDir)
+	if err != nil {
+		return model.File{}, coreerrors.Wrap(coreerrors.ErrCodeNotFound, "foldersync: 本地同步目录 "+e.pair.LocalDir+" 不可用", err)
+	}
+	if !info.IsDir() {
+		return model.File{}, coreerrors.New(coreerrors.ErrCodeInvalidState, "foldersync: 本地同步目录 "+e.pair.LocalDir+" 不是文件夹")
+	}
+	return root, nil
+}
+
+func (e *Engine) validate() error {
+	switch {
+	case e.client == nil || e.manager == nil || e.state == nil:
+		return coreerrors.New(coreerrors.ErrCodeInvalidConfig, "foldersync: client、manager 与状态库均不能为空")
+	case e.pair.ID == "" || e.pair.LocalDir == "" || e.pair.RemoteDir == "":
+		return coreerrors.New(coreerrors.ErrCodeInvalidArgument, "foldersync: 同步目录对配置不完整")
+	}
+	return nil
+}
+
+// localEntry 本地条目，md5 在需要时才计算。
+type localEntry struct {
+	isDir bool
+	size  int64
+	mtime int64
+	md5   string
+}
+
+// scanLocal 遍历本地目录，忽略符号链接、特殊文件、排除项与下载临时文件。
+func (e *Engine) scanLocal() (map[string]*localEntry, error) {
+	entries := make(map[string]*localEntry)
+	err := filepath.WalkDir(e.pair.LocalDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == e.pair.LocalDir {
+			return nil
+		}
+		if e.excluded(d.Name()) || strings.HasSuffix(d.Name(), tempSuffix) || !(d.IsDir() || d.Type().IsRegular()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(e.pair.LocalDir, p)
+		if err != nil {
+			return err
+		}
+		entry := &localEntry{isDir: d.IsDir(), mtime: info.ModTime().UnixNano()}
+		if !entry.isDir {
+			entry.size = info.Size()
+		}
+		entries[filepath.ToSlash(rel)] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, coreerrors.Wrap(coreerrors.ErrCodeUnknown, "foldersync: 扫描本地目录失败", err)
+	}
+	return entries, nil
+}
+
+// scanRemote 遍历云端目录，返回相对路径到文件的映射。
+func (e *Engine) scanRemote(ctx context.Context, rootID, remoteRoot string) (map[string]model.File, error) {
+	files := make(map[string]model.File)
+	prefix := strings.TrimSuffix(remoteRoot, "/") + "/"
+	err := e.client.Walk(ctx, rootID, remoteRoot, func(f model.File) error {
+		if e.excluded(f.Name) {
+			if f.IsFolder {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		files[strings.TrimPrefix(f.Path, prefix)] = f
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (e *Engine) excluded(name string) bool {
+	for _, pattern := range e.exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) localPath(rel string) string {
+	return filepath.Join(e.pair.LocalDir, filepath.FromSlash(rel))
+}
+
+func (e *Engine) remotePath(rel string) string {
+	return path.Join(cloud189.CleanPath(e.pair.RemoteDir), rel)
+}
+
+// onTaskUpdate 唤醒所有等待任务结束的 goroutine。
+func (e *Engine) onTaskUpdate(*task.Task) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// wait 等待任务进入终态并从 manager 中移除，ctx 取消时同时取消任务。
+func (e *Engine) wait(ctx context.Context, taskID string) error {
+	for {
+		e.mu.Lock()
+		changed := e.changed
+		e.mu.Unlock()
+		t, err := e.manager.GetTask(taskID)
+		if err != nil {
+			return err
+		}
+		switch t.GetStatus() {
+		case task.TaskStatusCompleted:
+			err = nil
+		case task.TaskStatusFailed:
+			err = t.GetError()
+		case task.TaskStatusCanceled:
+			err = task.ErrTaskCanceled
+		default:
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				_ = e.manager.Cancel(taskID)
+				// 任务尚未退出时移除会失败，留给 manager 的使用方清理。
+				_ = e.manager.RemoveTask(taskID)
+				return ctx.Err()
+			}
+			continue
+		}
+		_ = e.manager.RemoveTask(taskID)
+		return err
+	}
+}
+
+func sortReport(r *Report) {
+	for _, list := range [][]string{r.Uploaded, r.Downloaded, r.DeletedLocal, r.DeletedRemote, r.Conflicts} {
+		sort.Strings(list)
+	}
+	sort.Slice(r.Failed, func(i, j int) bool { return r.Failed[i].Path < r.Failed[j].Path })
+}
+
+var errTypeMismatch = errors.New("foldersync: 本地与云端类型不一致（文件/文件夹）")
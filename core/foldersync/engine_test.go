@@ -0,0 +1,323 @@
+package foldersync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/drive"
+	"github.com/dnslin/cloud189-desktop/core/internal/fakecloud"
+	"github.com/dnslin/cloud189-desktop/core/store"
+	"github.com/dnslin/cloud189-desktop/core/task"
+)
+
+type memoryState struct {
+	mu      sync.Mutex
+	records map[string]store.SyncRecord
+}
+
+func (s *memoryState) LoadRecords(string) ([]store.SyncRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []store.SyncRecord
+	for _, rec := range s.records {
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+func (s *memoryState) SaveRecords(_ string, records []store.SyncRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records == nil {
+		s.records = make(map[string]store.SyncRecord)
+	}
+	for _, rec := range records {
+		s.records[rec.Path] = rec
+	}
+	return nil
+}
+
+func (s *memoryState) DeleteRecords(_ string, paths []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range paths {
+		delete(s.records, p)
+	}
+	return nil
+}
+
+type env struct {
+	api    *fakecloud.Server
+	local  string
+	other  *drive.Cloud189Drive // 模拟另一台设备对云端的修改
+	engine *Engine
+}
+
+func newEnv(t *testing.T, opts ...Option) *env {
+	t.Helper()
+	api := fakecloud.New(t)
+	local := filepath.Join(t.TempDir(), "local")
+	pair := Pair{ID: "test", LocalDir: local, RemoteDir: "/Sync"}
+	engine := NewEngine(api.Client(t), task.NewManager(), &memoryState{}, pair, opts...)
+	engine.now = func() time.Time { return time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local) }
+	return &env{api: api, local: local, other: drive.NewCloud189Drive(api.Client(t)), engine: engine}
+}
+
+func (e *env) run(t *testing.T) *Report {
+	t.Helper()
+	report, err := e.engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("同步失败: %v", err)
+	}
+	if len(report.Failed) > 0 {
+		t.Fatalf("同步存在失败条目: %v", report.Failed)
+	}
+	return report
+}
+
+func (e *env) writeLocal(t *testing.T, rel, content string) {
+	t.Helper()
+	p := filepath.Join(e.local, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (e *env) readLocal(t *testing.T, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(e.local, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatalf("读取本地 %s 失败: %v", rel, err)
+	}
+	return string(data)
+}
+
+func (e *env) writeRemote(t *testing.T, rel, content string) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := e.other.Mkdir(ctx, "/Sync/"+filepathDir(rel)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.other.Create(ctx, "/Sync/"+rel, strings.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("写入云端 %s 失败: %v", rel, err)
+	}
+}
+
+func (e *env) readRemote(t *testing.T, rel string) string {
+	t.Helper()
+	node, ok := e.api.Lookup("/Sync/" + rel)
+	if !ok {
+		t.Fatalf("云端缺少 %s", rel)
+	}
+	return string(node.Data)
+}
+
+func filepathDir(rel string) string {
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
+
+func assertList(t *testing.T, name string, got []string, want ...string) {
+	t.Helper()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s: 得到 %q，期望 %q", name, got, want)
+	}
+}
+
+func assertIdle(t *testing.T, r *Report) {
+	t.Helper()
+	if n := len(r.Uploaded) + len(r.Downloaded) + len(r.DeletedLocal) + len(r.DeletedRemote) + len(r.Conflicts); n != 0 {
+		t.Fatalf("无变化时不应有操作: %+v", r)
+	}
+}
+
+// TestEngine_TwoWaySync 覆盖首次合并、双向修改、删除传播与空文件夹清理。
+func TestEngine_TwoWaySync(t *testing.T) {
+	e := newEnv(t, WithExclude(".DS_Store"))
+	e.writeRemote(t, "remote.txt", "r")
+	e.writeRemote(t, "rdir/nested.txt", "n")
+	e.writeRemote(t, "same.txt", "same")
+	e.writeLocal(t, "same.txt", "same")
+	e.writeLocal(t, "local.txt", "l")
+	e.writeLocal(t, "ldir/deep.txt", "d")
+	e.writeLocal(t, "ldir/.DS_Store", "x")
+	e.writeLocal(t, "empty.txt", "")
+	if err := os.MkdirAll(filepath.Join(e.local, "emptydir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	r := e.run(t)
+	assertList(t, "首次上传", r.Uploaded, "empty.txt", "ldir/deep.txt", "local.txt")
+	assertList(t, "首次下载", r.Downloaded, "rdir/nested.txt", "remote.txt")
+	if e.readLocal(t, "rdir/nested.txt") != "n" || e.readRemote(t, "ldir/deep.txt") != "d" || e.readRemote(t, "empty.txt") != "" {
+		t.Fatalf("首次同步内容不符")
+	}
+	if _, ok := e.api.Lookup("/Sync/emptydir"); !ok {
+		t.Fatalf("空文件夹应同步到云端")
+	}
+	if _, ok := e.api.Lookup("/Sync/ldir/.DS_Store"); ok {
+		t.Fatalf("排除项不应上传")
+	}
+	assertIdle(t, e.run(t))
+
+	// 仅修改时间变化而内容不变，不应触发上传。
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(e.local, "same.txt"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	assertIdle(t, e.run(t))
+
+	e.writeLocal(t, "local.txt", "local v2")
+	e.writeRemote(t, "remote.txt", "remote v2")
+	if err := os.Remove(filepath.Join(e.local, "ldir", "deep.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.other.Remove(context.Background(), "/Sync/rdir/nested.txt"); err != nil {
+		t.Fatal(err)
+	}
+	r = e.run(t)
+	assertList(t, "修改后上传", r.Uploaded, "local.txt")
+	assertList(t, "修改后下载", r.Downloaded, "remote.txt")
+	assertList(t, "删除本地", r.DeletedLocal, "rdir/nested.txt")
+	assertList(t, "删除云端", r.DeletedRemote, "ldir/deep.txt")
+	if e.readRemote(t, "local.txt") != "local v2" || e.readLocal(t, "remote.txt") != "remote v2" {
+		t.Fatalf("修改未同步")
+	}
+	if _, err := os.Stat(filepath.Join(e.local, "rdir", "nested.txt")); !os.IsNotExist(err) {
+		t.Fatalf("云端删除应传播到本地: %v", err)
+	}
+	assertIdle(t, e.run(t))
+
+	// 整个文件夹在一侧删除后，另一侧的文件夹及内容一并删除。
+	if err := os.RemoveAll(filepath.Join(e.local, "ldir")); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.other.Remove(context.Background(), "/Sync/rdir"); err != nil {
+		t.Fatal(err)
+	}
+	r = e.run(t)
+	assertList(t, "删除本地文件夹", r.DeletedLocal, "rdir")
+	assertList(t, "删除云端文件夹", r.DeletedRemote, "ldir")
+	if _, ok := e.api.Lookup("/Sync/ldir"); ok {
+		t.Fatalf("云端文件夹应被删除")
+	}
+	assertIdle(t, e.run(t))
+}
+
+// TestEngine_MissingRootAfterSync 已同步过的根目录缺失时报错，不把整棵树当作已删除传播到另一侧；
+// 完成的任务从 manager 中移除。
+func TestEngine_MissingRootAfterSync(t *testing.T) {
+	e := newEnv(t)
+	e.writeLocal(t, "a.txt", "a")
+	e.writeRemote(t, "b.txt", "b")
+	e.run(t)
+	if tasks := e.engine.manager.ListTasks(); len(tasks) != 0 {
+		t.Fatalf("同步完成后不应残留任务: %d", len(tasks))
+	}
+
+	if err := e.other.Remove(context.Background(), "/Sync"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.engine.Run(context.Background()); err == nil {
+		t.Fatalf("云端根目录缺失时应返回错误")
+	}
+	if e.readLocal(t, "a.txt") != "a" || e.readLocal(t, "b.txt") != "b" {
+		t.Fatalf("云端根目录缺失时不应删除本地文件")
+	}
+	if _, ok := e.api.Lookup("/Sync"); ok {
+		t.Fatalf("已有同步记录时不应重建云端根目录")
+	}
+
+	other := newEnv(t)
+	other.writeLocal(t, "a.txt", "a")
+	other.run(t)
+	if err := os.RemoveAll(other.local); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.engine.Run(context.Background()); err == nil {
+		t.Fatalf("本地根目录缺失时应返回错误")
+	}
+	if other.readRemote(t, "a.txt") != "a" {
+		t.Fatalf("本地根目录缺失时不应删除云端文件")
+	}
+	if err := other.engine.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+}
+
+// TestEngine_ConflictPolicies 两侧同时修改同一文件时按策略处理，处理后不再重复冲突。
+func TestEngine_ConflictPolicies(t *testing.T) {
+	const copyName = "doc (冲突副本 20240102-150405).txt"
+	cases := []struct {
+		policy     ConflictPolicy
+		localMTime time.Time
+		wantLocal  string
+		wantRemote string
+	}{
+		{policy: KeepBoth, wantLocal: "remote edit", wantRemote: "remote edit"},
+		{policy: LocalWins, wantLocal: "local edit", wantRemote: "local edit"},
+		{policy: RemoteWins, wantLocal: "remote edit", wantRemote: "remote edit"},
+		{policy: NewerWins, localMTime: time.Now().Add(24 * time.Hour), wantLocal: "local edit", wantRemote: "local edit"},
+		{policy: NewerWins, localMTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), wantLocal: "remote edit", wantRemote: "remote edit"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			e := newEnv(t, WithConflictPolicy(tc.policy))
+			e.writeRemote(t, "doc.txt", "base")
+			e.run(t)
+
+			e.writeLocal(t, "doc.txt", "local edit")
+			if !tc.localMTime.IsZero() {
+				if err := os.Chtimes(filepath.Join(e.local, "doc.txt"), tc.localMTime, tc.localMTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+			e.writeRemote(t, "doc.txt", "remote edit")
+			r := e.run(t)
+			assertList(t, "冲突", r.Conflicts, "doc.txt")
+			if got := e.readLocal(t, "doc.txt"); got != tc.wantLocal {
+				t.Fatalf("本地内容: 得到 %q，期望 %q", got, tc.wantLocal)
+			}
+			if got := e.readRemote(t, "doc.txt"); got != tc.wantRemote {
+				t.Fatalf("云端内容: 得到 %q，期望 %q", got, tc.wantRemote)
+			}
+			if tc.policy == KeepBoth {
+				if e.readLocal(t, copyName) != "local edit" || e.readRemote(t, copyName) != "local edit" {
+					t.Fatalf("冲突副本应同时保存在两侧")
+				}
+			}
+			assertIdle(t, e.run(t))
+		})
+	}
+}
+
+// TestEngine_TypeMismatch 同名条目一侧是文件一侧是文件夹时跳过并报告。
+func TestEngine_TypeMismatch(t *testing.T) {
+	e := newEnv(t)
+	e.writeLocal(t, "item", "file")
+	e.writeRemote(t, "item/child.txt", "c")
+	report, err := e.engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("同步失败: %v", err)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Path != "item" {
+		t.Fatalf("应报告类型冲突: %+v", report.Failed)
+	}
+	if len(report.Downloaded)+len(report.Uploaded) != 0 {
+		t.Fatalf("类型冲突的子树不应同步: %+v", report)
+	}
+}
@@ -0,0 +1,309 @@
+package foldersync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+	"github.com/dnslin/cloud189-desktop/core/store"
+	"github.com/dnslin/cloud189-desktop/core/task"
+)
+
+var errModified = errors.New("foldersync: 文件在同步过程中被修改，留待下次同步")
+
+// job 已提交到 task.Manager 的传输。
+type job struct {
+	rel    string
+	taskID string
+	// 上传
+	local *localEntry
+	// 下载
+	remote model.File
+	writer *fileWriter
+}
+
+// execute 依次建目录、改名冲突副本、传输、删除，最后清理空文件夹。
+func (e *Engine) execute(ctx context.Context, p *plan) *Report {
+	report := &Report{Conflicts: p.conflicts, Failed: p.failed}
+	fail := func(rel string, err error) {
+		report.Failed = append(report.Failed, ItemError{Path: rel, Err: err})
+	}
+
+	for _, f := range p.mkdirLocal {
+		if err := os.MkdirAll(e.localPath(f.Path), 0o755); err != nil {
+			fail(f.Path, err)
+			continue
+		}
+		p.records = append(p.records, e.folderRecord(f.Path, f.ID))
+	}
+	for _, rel := range p.mkdirRemote {
+		dir, err := e.drive.Mkdir(ctx, e.remotePath(rel))
+		if err != nil {
+			fail(rel, err)
+			continue
+		}
+		p.records = append(p.records, e.folderRecord(rel, dir.ID))
+	}
+
+	blocked := make(map[string]bool)
+	for _, rn := range p.renames {
+		if err := os.Rename(e.localPath(rn.from), e.localPath(rn.to)); err != nil {
+			fail(rn.from, err)
+			blocked[rn.from], blocked[rn.to] = true, true
+		}
+	}
+
+	// 先全部提交再等待，由 Manager 控制并发。
+	var jobs []*job
+	for _, rel := range p.uploads {
+		if blocked[rel] {
+			continue
+		}
+		j, err := e.startUpload(ctx, rel, p.locals[rel])
+		if err != nil {
+			fail(rel, err)
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	for _, f := range p.downloads {
+		if blocked[f.Path] {
+			continue
+		}
+		j, err := e.startDownload(f)
+		if err != nil {
+			fail(f.Path, err)
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	for _, j := range jobs {
+		err := e.wait(ctx, j.taskID)
+		var rec store.SyncRecord
+		if j.writer == nil {
+			rec, err = e.finishUpload(ctx, j, err)
+		} else {
+			rec, err = e.finishDownload(j, p.locals[j.rel], err)
+		}
+		if err != nil {
+			fail(j.rel, err)
+			continue
+		}
+		p.records = append(p.records, rec)
+		if j.writer == nil {
+			report.Uploaded = append(report.Uploaded, j.rel)
+		} else {
+			report.Downloaded = append(report.Downloaded, j.rel)
+		}
+	}
+
+	for _, rel := range p.deleteLocal {
+		if err := e.removeLocal(rel, p.locals[rel]); err != nil {
+			fail(rel, err)
+			continue
+		}
+		p.drops = append(p.drops, rel)
+		report.DeletedLocal = append(report.DeletedLocal, rel)
+	}
+	for _, rel := range p.deleteRemote {
+		if err := e.drive.Remove(ctx, e.remotePath(rel)); err != nil {
+			fail(rel, err)
+			continue
+		}
+		p.drops = append(p.drops, rel)
+		report.DeletedRemote = append(report.DeletedRemote, rel)
+	}
+
+	// 逆序遍历使子文件夹先于父文件夹处理。
+	for i := len(p.pruneLocal) - 1; i >= 0; i-- {
+		rel := p.pruneLocal[i]
+		removed, err := e.pruneLocal(ctx, rel, p)
+		if err != nil {
+			fail(rel, err)
+		} else if removed {
+			report.DeletedLocal = append(report.DeletedLocal, rel)
+		}
+	}
+	for i := len(p.pruneRemote) - 1; i >= 0; i-- {
+		rel := p.pruneRemote[i]
+		removed, err := e.pruneRemote(ctx, rel, p)
+		if err != nil {
+			fail(rel, err)
+		} else if removed {
+			report.DeletedRemote = append(report.DeletedRemote, rel)
+		}
+	}
+
+	sortReport(report)
+	return report
+}
+
+func (e *Engine) startUpload(ctx context.Context, rel string, l *localEntry) (*job, error) {
+	parent, err := e.drive.Mkdir(ctx, path.Dir(e.remotePath(rel)))
+	if err != nil {
+		return nil, err
+	}
+	sum, err := e.localMD5(rel, l)
+	if err != nil {
+		return nil, err
+	}
+	local := e.localPath(rel)
+	reader, err := openReader(local)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := reader.Stat(); err != nil || !l.matches(info) {
+		reader.Close()
+		return nil, errModified
+	}
+	id, err := e.manager.AddUpload(task.UploadConfig{
+		LocalPath: local,
+		FileName:  path.Base(rel),
+		ParentID:  parent.ID,
+		FileMD5:   sum,
+	}, &uploader{client: e.client, md5: sum}, reader)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+	return &job{rel: rel, taskID: id, local: l}, nil
+}
+
+func (e *Engine) finishUpload(ctx context.Context, j *job, err error) (store.SyncRecord, error) {
+	if err != nil {
+		return store.SyncRecord{}, err
+	}
+	f, err := e.drive.Stat(ctx, e.remotePath(j.rel))
+	if err != nil {
+		return store.SyncRecord{}, err
+	}
+	return e.fileRecord(j.rel, j.local, &f), nil
+}
+
+// startDownload 下载到同目录的临时文件，完成并校验后再替换目标文件。
+func (e *Engine) startDownload(f model.File) (*job, error) {
+	local := e.localPath(f.Path)
+	if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+		return nil, err
+	}
+	writer, err := createWriter(local + tempSuffix)
+	if err != nil {
+		return nil, err
+	}
+	id, err := e.manager.AddDownload(task.DownloadConfig{FileID: f.ID, LocalPath: local + tempSuffix}, downloader{client: e.client}, writer)
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+	return &job{rel: f.Path, taskID: id, remote: f, writer: writer}, nil
+}
+
+// finishDownload 校验 MD5 并把修改时间设为云端时间后替换目标文件；
+// 目标文件在下载期间被改动时放弃替换。
+func (e *Engine) finishDownload(j *job, scanned *localEntry, err error) (store.SyncRecord, error) {
+	local := e.localPath(j.rel)
+	tmp := local + tempSuffix
+	if err != nil {
+		_ = os.Remove(tmp)
+		return store.SyncRecord{}, err
+	}
+	<-j.writer.closed
+	sum, err := fileMD5(tmp)
+	if err == nil && j.remote.MD5 != "" && sum != strings.ToLower(j.remote.MD5) {
+		err = fmt.Errorf("foldersync: 下载内容校验失败，期望 MD5 %s，实际 %s", strings.ToLower(j.remote.MD5), sum)
+	}
+	if err == nil {
+		if info, statErr := os.Lstat(local); statErr == nil && (scanned == nil || !scanned.matches(info)) {
+			err = errModified
+		}
+	}
+	if err == nil && !j.remote.UpdatedAt.IsZero() {
+		err = os.Chtimes(tmp, j.remote.UpdatedAt, j.remote.UpdatedAt)
+	}
+	if err == nil {
+		err = os.Rename(tmp, local)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return store.SyncRecord{}, err
+	}
+	info, err := os.Stat(local)
+	if err != nil {
+		return store.SyncRecord{}, err
+	}
+	l := &localEntry{size: info.Size(), mtime: info.ModTime().UnixNano(), md5: sum}
+	return e.fileRecord(j.rel, l, &j.remote), nil
+}
+
+// removeLocal 删除本地文件，扫描后被改动过的文件保留。
+func (e *Engine) removeLocal(rel string, scanned *localEntry) error {
+	local := e.localPath(rel)
+	info, err := os.Lstat(local)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !scanned.matches(info) {
+		return errModified
+	}
+	return os.Remove(local)
+}
+
+// pruneLocal 删除云端已删除且已清空的本地文件夹，仍有内容时保留并补建云端文件夹。
+func (e *Engine) pruneLocal(ctx context.Context, rel string, p *plan) (bool, error) {
+	local := e.localPath(rel)
+	entries, err := os.ReadDir(local)
+	if err != nil {
+		return false, err
+	}
+	if len(entries) == 0 {
+		if err := os.Remove(local); err != nil {
+			return false, err
+		}
+		p.drops = append(p.drops, rel)
+		return true, nil
+	}
+	dir, err := e.drive.Mkdir(ctx, e.remotePath(rel))
+	if err != nil {
+		return false, err
+	}
+	p.records = append(p.records, e.folderRecord(rel, dir.ID))
+	return false, nil
+}
+
+// pruneRemote 删除本地已删除且已清空的云端文件夹，仍有内容时保留并补建本地文件夹。
+func (e *Engine) pruneRemote(ctx context.Context, rel string, p *plan) (bool, error) {
+	remote := e.remotePath(rel)
+	children, err := e.drive.List(ctx, remote)
+	if err != nil {
+		return false, err
+	}
+	if len(children) == 0 {
+		if err := e.drive.Remove(ctx, remote); err != nil {
+			return false, err
+		}
+		p.drops = append(p.drops, rel)
+		return true, nil
+	}
+	dir, err := e.drive.Stat(ctx, remote)
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(e.localPath(rel), 0o755); err != nil {
+		return false, err
+	}
+	p.records = append(p.records, e.folderRecord(rel, dir.ID))
+	return false, nil
+}
+
+// matches 判断文件自扫描后是否未被改动。
+func (l *localEntry) matches(info os.FileInfo) bool {
+	return info.Mode().IsRegular() && info.Size() == l.size && info.ModTime().UnixNano() == l.mtime
+}
@@ -0,0 +1,274 @@
+package foldersync
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/model"
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+// plan 一次同步要执行的操作，路径均为相对路径。
+type plan struct {
+	mkdirLocal   []model.File // Path 已换成相对路径
+	mkdirRemote  []string
+	renames      []rename // 冲突副本改名，先于传输执行
+	uploads      []string
+	downloads    []model.File // Path 已换成相对路径
+	deleteLocal  []string
+	deleteRemote []string
+	pruneLocal   []string // 云端已删除的文件夹，传输后为空才删除
+	pruneRemote  []string
+	conflicts    []string
+	failed       []ItemError
+
+	locals  map[string]*localEntry
+	records []store.SyncRecord // 同步完成后写入状态库
+	drops   []string           // 同步完成后从状态库删除
+}
+
+type rename struct {
+	from, to string
+}
+
+// plan 比较两侧与同步记录，生成操作计划。
+func (e *Engine) plan(locals map[string]*localEntry, remotes map[string]model.File, records map[string]*store.SyncRecord) *plan {
+	p := &plan{locals: locals}
+	seen := make(map[string]bool, len(locals)+len(remotes)+len(records))
+	var all []string
+	for rel := range locals {
+		seen[rel] = true
+		all = append(all, rel)
+	}
+	for rel := range remotes {
+		if !seen[rel] {
+			seen[rel] = true
+			all = append(all, rel)
+		}
+	}
+	for rel := range records {
+		if !seen[rel] {
+			seen[rel] = true
+			all = append(all, rel)
+		}
+	}
+	// 排序后父目录总在子条目之前。
+	sort.Strings(all)
+
+	var skipped []string
+	for _, rel := range all {
+		if underAny(rel, skipped) {
+			continue
+		}
+		l := locals[rel]
+		var r *model.File
+		if f, ok := remotes[rel]; ok {
+			f.Path = rel
+			r = &f
+		}
+		rec := records[rel]
+		switch {
+		case l != nil && r != nil && l.isDir != r.IsFolder:
+			p.failed = append(p.failed, ItemError{Path: rel, Err: errTypeMismatch})
+			skipped = append(skipped, rel)
+		case (l != nil && l.isDir) || (r != nil && r.IsFolder):
+			if rec != nil && !rec.IsFolder {
+				rec = nil
+			}
+			e.planFolder(p, rel, l, r, rec)
+		case l == nil && r == nil:
+			p.drops = append(p.drops, rel)
+		default:
+			if rec != nil && rec.IsFolder {
+				rec = nil
+			}
+			if err := e.planFile(p, rel, l, r, rec); err != nil {
+				p.failed = append(p.failed, ItemError{Path: rel, Err: err})
+			}
+		}
+	}
+	return p
+}
+
+func (e *Engine) planFolder(p *plan, rel string, l *localEntry, r *model.File, rec *store.SyncRecord) {
+	switch {
+	case l != nil && r != nil:
+		if rec == nil || rec.RemoteID != r.ID {
+			p.records = append(p.records, e.folderRecord(rel, r.ID))
+		}
+	case l != nil && rec == nil:
+		p.mkdirRemote = append(p.mkdirRemote, rel)
+	case l != nil:
+		p.pruneLocal = append(p.pruneLocal, rel)
+	case rec == nil:
+		p.mkdirLocal = append(p.mkdirLocal, *r)
+	default:
+		p.pruneRemote = append(p.pruneRemote, rel)
+	}
+}
+
+// planFile 按文件决策表生成操作，l 与 r 至少有一个不为空。
+func (e *Engine) planFile(p *plan, rel string, l *localEntry, r *model.File, rec *store.SyncRecord) error {
+	if rec == nil {
+		switch {
+		case r == nil:
+			p.uploads = append(p.uploads, rel)
+		case l == nil:
+			p.downloads = append(p.downloads, *r)
+		default:
+			// 两侧各自新建了同名文件。
+			same, err := e.sameContent(rel, l, r)
+			if err != nil {
+				return err
+			}
+			if same {
+				p.records = append(p.records, e.fileRecord(rel, l, r))
+			} else {
+				e.planConflict(p, rel, l, r)
+			}
+		}
+		return nil
+	}
+
+	remoteChanged := r != nil && isRemoteChanged(r, rec)
+	if l == nil {
+		if remoteChanged {
+			p.downloads = append(p.downloads, *r)
+		} else {
+			p.deleteRemote = append(p.deleteRemote, rel)
+		}
+		return nil
+	}
+	localChanged, err := e.isLocalChanged(rel, l, rec)
+	if err != nil {
+		return err
+	}
+	switch {
+	case r == nil && localChanged:
+		p.uploads = append(p.uploads, rel)
+	case r == nil:
+		p.deleteLocal = append(p.deleteLocal, rel)
+	case localChanged && remoteChanged:
+		same, err := e.sameContent(rel, l, r)
+		if err != nil {
+			return err
+		}
+		if same {
+			p.records = append(p.records, e.fileRecord(rel, l, r))
+		} else {
+			e.planConflict(p, rel, l, r)
+		}
+	case localChanged:
+		p.uploads = append(p.uploads, rel)
+	case remoteChanged:
+		p.downloads = append(p.downloads, *r)
+	case l.mtime != rec.LocalMTime || r.Revision != rec.RemoteRev || r.ID != rec.RemoteID:
+		// 内容未变，仅刷新基线，下次可直接按修改时间与修订号跳过。
+		if l.md5 == "" {
+			l.md5 = rec.MD5
+		}
+		p.records = append(p.records, e.fileRecord(rel, l, r))
+	}
+	return nil
+}
+
+func (e *Engine) planConflict(p *plan, rel string, l *localEntry, r *model.File) {
+	p.conflicts = append(p.conflicts, rel)
+	switch e.policy {
+	case LocalWins:
+		p.uploads = append(p.uploads, rel)
+	case RemoteWins:
+		p.downloads = append(p.downloads, *r)
+	case NewerWins:
+		if l.mtime > r.UpdatedAt.UnixNano() {
+			p.uploads = append(p.uploads, rel)
+		} else {
+			p.downloads = append(p.downloads, *r)
+		}
+	default:
+		copyRel := conflictName(rel, e.now())
+		p.renames = append(p.renames, rename{from: rel, to: copyRel})
+		p.locals[copyRel] = l
+		p.uploads = append(p.uploads, copyRel)
+		p.downloads = append(p.downloads, *r)
+	}
+}
+
+// isLocalChanged 大小或修改时间变化时才计算 MD5，避免仅 touch 过的文件被当作修改。
+func (e *Engine) isLocalChanged(rel string, l *localEntry, rec *store.SyncRecord) (bool, error) {
+	if l.size != rec.Size {
+		return true, nil
+	}
+	if l.mtime == rec.LocalMTime {
+		return false, nil
+	}
+	sum, err := e.localMD5(rel, l)
+	if err != nil {
+		return false, err
+	}
+	return sum != rec.MD5, nil
+}
+
+// isRemoteChanged 修订号与文件 ID 都未变时视为未修改，否则比较大小与 MD5。
+func isRemoteChanged(r *model.File, rec *store.SyncRecord) bool {
+	if r.ID == rec.RemoteID && r.Revision == rec.RemoteRev {
+		return false
+	}
+	return r.Size != rec.Size || r.MD5 == "" || strings.ToLower(r.MD5) != rec.MD5
+}
+
+func (e *Engine) sameContent(rel string, l *localEntry, r *model.File) (bool, error) {
+	if l.size != r.Size || r.MD5 == "" {
+		return false, nil
+	}
+	sum, err := e.localMD5(rel, l)
+	if err != nil {
+		return false, err
+	}
+	return sum == strings.ToLower(r.MD5), nil
+}
+
+func (e *Engine) localMD5(rel string, l *localEntry) (string, error) {
+	if l.md5 == "" {
+		sum, err := fileMD5(e.localPath(rel))
+		if err != nil {
+			return "", err
+		}
+		l.md5 = sum
+	}
+	return l.md5, nil
+}
+
+func (e *Engine) fileRecord(rel string, l *localEntry, r *model.File) store.SyncRecord {
+	return store.SyncRecord{
+		Path:       rel,
+		Size:       l.size,
+		MD5:        l.md5,
+		LocalMTime: l.mtime,
+		RemoteID:   r.ID,
+		RemoteRev:  r.Revision,
+		SyncedAt:   e.now().Unix(),
+	}
+}
+
+func (e *Engine) folderRecord(rel, remoteID string) store.SyncRecord {
+	return store.SyncRecord{Path: rel, IsFolder: true, RemoteID: remoteID, SyncedAt: e.now().Unix()}
+}
+
+// conflictName 生成冲突副本名，如 "a/report (冲突副本 20240102-150405).txt"。
+func conflictName(rel string, t time.Time) string {
+	dir, base := path.Split(rel)
+	ext := path.Ext(base)
+	return dir + strings.TrimSuffix(base, ext) + " (冲突副本 " + t.Format("20060102-150405") + ")" + ext
+}
+
+func underAny(rel string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
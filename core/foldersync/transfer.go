@@ -0,0 +1,177 @@
+package foldersync
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	"github.com/dnslin/cloud189-desktop/core/task"
+)
+
+// uploader 以 App 接口实现 task.Uploader，覆盖云端同名文件。
+type uploader struct {
+	client *cloud189.Client
+	md5    string // 扫描时已算出的整文件 MD5，续传或空文件时据此提交
+
+	mu      sync.Mutex
+	session *cloud189.UploadSession
+}
+
+func (u *uploader) Mode() task.UploadMode {
+	return task.UploadModeApp
+}
+
+func (u *uploader) InitUpload(ctx context.Context, parentID, filename string, size int64, resumeState *task.ResumeState) (string, bool, int64, error) {
+	var (
+		session  *cloud189.UploadSession
+		uploaded int64
+	)
+	if resumeState != nil && resumeState.UploadFileID != "" {
+		session = u.client.ResumeUploadSession(parentID, filename, size, resumeState.UploadFileID, resumeState.UploadedSize, resumeState.PartHashes)
+		uploaded = resumeState.UploadedSize
+	} else {
+		var err error
+		if session, err = u.client.InitUpload(ctx, parentID, filename, size); err != nil {
+			return "", false, 0, err
+		}
+	}
+	session.Overwrite = true
+	u.mu.Lock()
+	u.session = session
+	u.mu.Unlock()
+	return session.UploadFileID, session.Exists(), uploaded, nil
+}
+
+func (u *uploader) UploadPart(ctx context.Context, uploadFileID string, partNum int, data io.Reader) error {
+	session, err := u.current()
+	if err != nil {
+		return err
+	}
+	return u.client.UploadPart(ctx, session, partNum, data)
+}
+
+func (u *uploader) CommitUpload(ctx context.Context, uploadFileID string, fileMD5, sliceMD5 string) (string, error) {
+	session, err := u.current()
+	if err != nil {
+		return "", err
+	}
+	// 续传会话只有后半段数据经过哈希，统一使用扫描时的 MD5；空文件没有分片，SliceMD5 同为整文件 MD5。
+	if fileMD5 == "" {
+		fileMD5 = u.md5
+	}
+	session.FileMD5 = fileMD5
+	if sliceMD5 == "" && len(session.GetPartHashes()) == 0 {
+		sliceMD5 = fileMD5
+	}
+	if sliceMD5 != "" {
+		session.SliceMD5 = sliceMD5
+	}
+	info, err := u.client.CommitUpload(ctx, session)
+	if err != nil {
+		return "", err
+	}
+	return info.ID.String(), nil
+}
+
+func (u *uploader) GetPartHashes() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.session.GetPartHashes()
+}
+
+func (u *uploader) current() (*cloud189.UploadSession, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.session == nil {
+		return nil, cloud189.WrapCloudError(cloud189.ErrCodeInvalidRequest, "上传会话未初始化", errors.New("foldersync: session 为空"))
+	}
+	return u.session, nil
+}
+
+// downloader 以 App 接口实现 task.Downloader。
+type downloader struct {
+	client *cloud189.Client
+}
+
+func (d downloader) Mode() task.DownloadMode {
+	return task.DownloadModeApp
+}
+
+func (d downloader) GetDownloadURL(ctx context.Context, fileID string) (string, error) {
+	return d.client.GetDownloadURL(ctx, fileID)
+}
+
+func (d downloader) GetFileInfo(ctx context.Context, fileID string) (string, int64, error) {
+	info, err := d.client.GetFileInfo(ctx, fileID)
+	if err != nil {
+		return "", 0, err
+	}
+	return info.FileName, info.FileSize, nil
+}
+
+func (d downloader) HTTPClient() *http.Client {
+	return d.client.HTTPClient()
+}
+
+// fileReader 实现 task.UploadReader。
+type fileReader struct {
+	*os.File
+	size int64
+}
+
+func openReader(name string) (*fileReader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileReader{File: f, size: info.Size()}, nil
+}
+
+func (r *fileReader) Size() int64 { return r.size }
+
+// fileWriter 实现 task.DownloadWriter。任务结束后才由 Manager 关闭，
+// closed 用于确认文件已关闭再校验与改名。
+type fileWriter struct {
+	*os.File
+	once   sync.Once
+	closed chan struct{}
+}
+
+func createWriter(name string) (*fileWriter, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileWriter{File: f, closed: make(chan struct{})}, nil
+}
+
+func (w *fileWriter) Close() error {
+	err := w.File.Close()
+	w.once.Do(func() { close(w.closed) })
+	return err
+}
+
+// fileMD5 计算本地文件的 MD5（小写十六进制）。
+func fileMD5(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
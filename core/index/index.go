@@ -0,0 +1,372 @@
+// Package index 维护远端目录树的本地文件名索引，支持离线的即时搜索。
+//
+// Build 全量遍历一次建立索引，之后 Refresh 借助目录修订号（lastRev）只重新列出
+// 发生变化的目录。索引可通过 store.IndexStore 持久化，断网时 Load 后即可搜索。
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	coreerrors "github.com/dnslin/cloud189-desktop/core/errors"
+	"github.com/dnslin/cloud189-desktop/core/model"
+	"github.com/dnslin/cloud189-desktop/core/store"
+)
+
+// defaultConcurrency 默认同时列目录的数量。
+const defaultConcurrency = 4
+
+// ErrNotBuilt 尚未建立或加载索引。
+var ErrNotBuilt = coreerrors.New(coreerrors.ErrCodeInvalidState, "index: 索引未建立")
+
+// Index 远端目录树的内存索引，并发安全。
+type Index struct {
+	client      *cloud189.Client
+	store       store.IndexStore
+	concurrency int
+	now         func() time.Time
+
+	// refreshMu 串行化 Build 与 Refresh，mu 保护索引数据，搜索只需持有读锁。
+	refreshMu sync.Mutex
+	mu        sync.RWMutex
+	detector  *cloud189.ChangeDetector
+	rootID    string
+	rootPath  string
+	updatedAt time.Time
+	entries   map[string]*entry
+	children  map[string]map[string]struct{}
+}
+
+type entry struct {
+	file      model.File
+	lowerName string
+	lowerPath string
+}
+
+// Option 配置 Index。
+type Option func(*Index)
+
+// WithConcurrency 设置同时列目录的最大数量。
+func WithConcurrency(n int) Option {
+	return func(idx *Index) {
+		if n > 0 {
+			idx.concurrency = n
+		}
+	}
+}
+
+// New 创建索引，st 为 nil 时不持久化。
+func New(client *cloud189.Client, st store.IndexStore, opts ...Option) *Index {
+	idx := &Index{
+		client:      client,
+		store:       st,
+		concurrency: defaultConcurrency,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(idx)
+		}
+	}
+	idx.resetLocked("", "")
+	return idx
+}
+
+// Len 返回已索引的文件与文件夹数量。
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.entries)
+}
+
+// UpdatedAt 返回最近一次成功建立或刷新的时间。
+func (idx *Index) UpdatedAt() time.Time {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.updatedAt
+}
+
+// Build 丢弃现有索引，全量遍历 rootID 下的目录树，rootPath 为其远端路径。
+func (idx *Index) Build(ctx context.Context, rootID, rootPath string) error {
+	if idx.client == nil {
+		return coreerrors.Wrap(coreerrors.ErrCodeInvalidConfig, "index: 客户端未初始化", errors.New("index: client 为空"))
+	}
+	if rootID == "" {
+		return coreerrors.New(coreerrors.ErrCodeInvalidArgument, "index: rootID 不能为空")
+	}
+	idx.refreshMu.Lock()
+	defer idx.refreshMu.Unlock()
+	idx.mu.Lock()
+	idx.resetLocked(rootID, cloud189.CleanPath(rootPath))
+	idx.mu.Unlock()
+	return idx.sync(ctx, []string{rootID})
+}
+
+// Refresh 增量刷新：修订号未变的目录只发出一次轻量请求，变化的目录重新列出并展开新增子目录。
+func (idx *Index) Refresh(ctx context.Context) error {
+	if idx.client == nil {
+		return coreerrors.Wrap(coreerrors.ErrCodeInvalidConfig, "index: 客户端未初始化", errors.New("index: client 为空"))
+	}
+	idx.refreshMu.Lock()
+	defer idx.refreshMu.Unlock()
+	idx.mu.RLock()
+	if idx.rootID == "" {
+		idx.mu.RUnlock()
+		return ErrNotBuilt
+	}
+	folders := []string{idx.rootID}
+	for id, e := range idx.entries {
+		if e.file.IsFolder {
+			folders = append(folders, id)
+		}
+	}
+	idx.mu.RUnlock()
+	return idx.sync(ctx, folders)
+}
+
+type folderResult struct {
+	id      string
+	changes []cloud189.FolderChange
+	err     error
+}
+
+// sync 逐层检测目录变化并应用，新增的子目录在下一层展开。
+func (idx *Index) sync(ctx context.Context, folders []string) error {
+	for len(folders) > 0 {
+		results := idx.detect(ctx, folders)
+		idx.mu.Lock()
+		var (
+			next     []string
+			firstErr error
+		)
+		for _, res := range results {
+			if res.err == nil {
+				continue
+			}
+			if isNotFound(res.err) && res.id != idx.rootID {
+				idx.removeLocked(res.id)
+			} else if firstErr == nil {
+				firstErr = res.err
+			}
+		}
+		// 先删除再新增，跨目录移动的条目才不会在新位置被误删。
+		for _, res := range results {
+			if res.err == nil && idx.knownFolderLocked(res.id) {
+				idx.applyDeletesLocked(res.id, res.changes)
+			}
+		}
+		for _, res := range results {
+			if res.err == nil && idx.knownFolderLocked(res.id) {
+				next = append(next, idx.applyUpsertsLocked(res.id, res.changes)...)
+			}
+		}
+		idx.mu.Unlock()
+		if firstErr != nil {
+			return firstErr
+		}
+		folders = next
+	}
+	idx.mu.Lock()
+	idx.updatedAt = idx.now()
+	idx.mu.Unlock()
+	return idx.save()
+}
+
+// detect 以有限并发获取各目录的变化。
+func (idx *Index) detect(ctx context.Context, folders []string) []folderResult {
+	results := make([]folderResult, len(folders))
+	sem := make(chan struct{}, idx.concurrency)
+	var wg sync.WaitGroup
+	for i, id := range folders {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			changes, err := idx.detector.Changes(ctx, id)
+			results[i] = folderResult{id: id, changes: changes, err: err}
+		}(i, id)
+	}
+	wg.Wait()
+	return results
+}
+
+func (idx *Index) knownFolderLocked(id string) bool {
+	if id == idx.rootID {
+		return true
+	}
+	e, ok := idx.entries[id]
+	return ok && e.file.IsFolder
+}
+
+func (idx *Index) applyDeletesLocked(folderID string, changes []cloud189.FolderChange) {
+	for _, ch := range changes {
+		if ch.Kind != cloud189.FolderChangeDeleted {
+			continue
+		}
+		if e, ok := idx.entries[ch.File.ID]; ok && e.file.ParentID == folderID {
+			idx.removeLocked(ch.File.ID)
+		}
+	}
+}
+
+// applyUpsertsLocked 写入新增、修改与重命名的条目，返回需要展开的文件夹。
+func (idx *Index) applyUpsertsLocked(folderID string, changes []cloud189.FolderChange) []string {
+	parentPath := idx.pathLocked(folderID)
+	var expand []string
+	for _, ch := range changes {
+		if ch.Kind == cloud189.FolderChangeDeleted {
+			continue
+		}
+		file := ch.File
+		file.ParentID = folderID
+		file.ParentPath = parentPath
+		file.Path = path.Join(parentPath, file.Name)
+		old, existed := idx.entries[file.ID]
+		idx.putLocked(file)
+		if !file.IsFolder {
+			continue
+		}
+		switch {
+		case !existed || !old.file.IsFolder:
+			expand = append(expand, file.ID)
+		case old.file.Path != file.Path:
+			idx.repathLocked(file.ID)
+		}
+	}
+	return expand
+}
+
+func (idx *Index) putLocked(file model.File) {
+	if old, ok := idx.entries[file.ID]; ok && old.file.ParentID != file.ParentID {
+		delete(idx.children[old.file.ParentID], file.ID)
+	}
+	idx.entries[file.ID] = &entry{
+		file:      file,
+		lowerName: strings.ToLower(file.Name),
+		lowerPath: strings.ToLower(file.Path),
+	}
+	kids, ok := idx.children[file.ParentID]
+	if !ok {
+		kids = make(map[string]struct{})
+		idx.children[file.ParentID] = kids
+	}
+	kids[file.ID] = struct{}{}
+}
+
+// repathLocked 文件夹重命名或移动后更新子树中所有条目的路径。
+func (idx *Index) repathLocked(folderID string) {
+	parentPath := idx.pathLocked(folderID)
+	for id := range idx.children[folderID] {
+		e := idx.entries[id]
+		e.file.ParentPath = parentPath
+		e.file.Path = path.Join(parentPath, e.file.Name)
+		e.lowerPath = strings.ToLower(e.file.Path)
+		if e.file.IsFolder {
+			idx.repathLocked(id)
+		}
+	}
+}
+
+// removeLocked 删除条目及其子树，并丢弃相关目录的变化基线。
+func (idx *Index) removeLocked(id string) {
+	for child := range idx.children[id] {
+		idx.removeLocked(child)
+	}
+	delete(idx.children, id)
+	if e, ok := idx.entries[id]; ok {
+		delete(idx.children[e.file.ParentID], id)
+		delete(idx.entries, id)
+		if e.file.IsFolder {
+			idx.detector.Forget(id)
+		}
+	}
+}
+
+func (idx *Index) pathLocked(folderID string) string {
+	if folderID == idx.rootID {
+		return idx.rootPath
+	}
+	if e, ok := idx.entries[folderID]; ok {
+		return e.file.Path
+	}
+	return idx.rootPath
+}
+
+func (idx *Index) resetLocked(rootID, rootPath string) {
+	idx.detector = cloud189.NewChangeDetector(idx.client)
+	idx.rootID = rootID
+	idx.rootPath = rootPath
+	idx.updatedAt = time.Time{}
+	idx.entries = make(map[string]*entry)
+	idx.children = make(map[string]map[string]struct{})
+}
+
+// snapshot 索引的持久化格式，Folders 为各目录的变化基线，用于恢复后继续增量刷新。
+type snapshot struct {
+	RootID    string                    `json:"rootId"`
+	RootPath  string                    `json:"rootPath"`
+	UpdatedAt time.Time                 `json:"updatedAt"`
+	Files     []model.File              `json:"files"`
+	Folders   []cloud189.FolderSnapshot `json:"folders"`
+}
+
+// Load 从存储恢复索引，从未保存过时保持为空。
+func (idx *Index) Load() error {
+	if idx.store == nil {
+		return nil
+	}
+	data, err := idx.store.LoadIndex()
+	if err != nil || data == nil {
+		return err
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return coreerrors.Wrap(coreerrors.ErrCodeInvalidState, "index: 索引数据损坏", err)
+	}
+	idx.refreshMu.Lock()
+	defer idx.refreshMu.Unlock()
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.resetLocked(snap.RootID, snap.RootPath)
+	idx.updatedAt = snap.UpdatedAt
+	for _, file := range snap.Files {
+		idx.putLocked(file)
+	}
+	idx.detector.LoadSnapshots(snap.Folders)
+	return nil
+}
+
+func (idx *Index) save() error {
+	if idx.store == nil {
+		return nil
+	}
+	idx.mu.RLock()
+	snap := snapshot{
+		RootID:    idx.rootID,
+		RootPath:  idx.rootPath,
+		UpdatedAt: idx.updatedAt,
+		Files:     make([]model.File, 0, len(idx.entries)),
+		Folders:   idx.detector.Snapshots(),
+	}
+	for _, e := range idx.entries {
+		snap.Files = append(snap.Files, e.file)
+	}
+	idx.mu.RUnlock()
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return coreerrors.Wrap(coreerrors.ErrCodeUnknown, "index: 序列化索引失败", err)
+	}
+	return idx.store.SaveIndex(data)
+}
+
+func isNotFound(err error) bool {
+	var ce *cloud189.CloudError
+	return errors.As(err, &ce) && ce.Code == cloud189.ErrCodeFileNotFound
+}
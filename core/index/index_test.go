@@ -0,0 +1,174 @@
+package index
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	"github.com/dnslin/cloud189-desktop/core/internal/fakecloud"
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+type memoryIndexStore struct {
+	data []byte
+}
+
+func (s *memoryIndexStore) LoadIndex() ([]byte, error) { return s.data, nil }
+
+func (s *memoryIndexStore) SaveIndex(data []byte) error {
+	s.data = append([]byte(nil), data...)
+	return nil
+}
+
+func paths(files []model.File) string {
+	var out []string
+	for _, f := range files {
+		out = append(out, f.Path)
+	}
+	return strings.Join(out, ",")
+}
+
+func search(t *testing.T, idx *Index, q Query) string {
+	t.Helper()
+	files, err := idx.Search(q)
+	if err != nil {
+		t.Fatalf("搜索 %+v 失败: %v", q, err)
+	}
+	return paths(files)
+}
+
+// TestIndex_SearchModes 覆盖子串、通配符、正则与各类过滤条件。
+func TestIndex_SearchModes(t *testing.T) {
+	api := fakecloud.New(t)
+	docs := api.AddFolder(fakecloud.RootID, "Docs")
+	api.AddFile(docs, "Report-2024.PDF", []byte("report"))
+	api.AddFile(docs, "notes.txt", []byte("n"))
+	media := api.AddFolder(fakecloud.RootID, "Media")
+	api.AddFile(media, "holiday.mp4", make([]byte, 2048))
+	api.AddFile(media, "report.jpg", []byte("jpg"))
+
+	idx := New(api.Client(t), nil)
+	if err := idx.Build(context.Background(), fakecloud.RootID, "/"); err != nil {
+		t.Fatalf("建立索引失败: %v", err)
+	}
+	if idx.Len() != 6 || idx.UpdatedAt().IsZero() {
+		t.Fatalf("索引条目数异常: %d", idx.Len())
+	}
+
+	cases := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"子串不区分大小写", Query{Pattern: "REPORT"}, "/Docs/Report-2024.PDF,/Media/report.jpg"},
+		{"子串区分大小写", Query{Pattern: "Report", CaseSensitive: true}, "/Docs/Report-2024.PDF"},
+		{"通配符", Query{Pattern: "*.pdf", Mode: MatchGlob}, "/Docs/Report-2024.PDF"},
+		{"路径通配符", Query{Pattern: "/media/*", Mode: MatchGlob, MatchPath: true}, "/Media/holiday.mp4,/Media/report.jpg"},
+		{"正则", Query{Pattern: `^report-\d{4}`, Mode: MatchRegexp}, "/Docs/Report-2024.PDF"},
+		{"仅文件夹", Query{Kind: KindFolder}, "/Docs,/Media"},
+		{"媒体类型", Query{MediaKinds: []cloud189.MediaKind{cloud189.MediaVideo, cloud189.MediaPhoto}}, "/Media/holiday.mp4,/Media/report.jpg"},
+		{"扩展名", Query{Extensions: []string{"TXT", ".mp4"}}, "/Docs/notes.txt,/Media/holiday.mp4"},
+		{"大小", Query{MinSize: 1024}, "/Media/holiday.mp4"},
+		{"目录范围", Query{Under: "/Docs", Kind: KindFile, Limit: 1}, "/Docs/Report-2024.PDF"},
+	}
+	for _, tc := range cases {
+		if got := search(t, idx, tc.query); got != tc.want {
+			t.Errorf("%s: 得到 %q，期望 %q", tc.name, got, tc.want)
+		}
+	}
+
+	all, _ := idx.Search(Query{Kind: KindFile})
+	cutoff := all[1].UpdatedAt
+	if got := search(t, idx, Query{Kind: KindFile, ModifiedAfter: cutoff}); strings.Count(got, ",") != 2 {
+		t.Errorf("按修改时间过滤异常: %q", got)
+	}
+	if got := search(t, idx, Query{Kind: KindFile, ModifiedBefore: cutoff}); got != all[0].Path {
+		t.Errorf("按修改时间过滤异常: %q", got)
+	}
+	if _, err := idx.Search(Query{Pattern: "(", Mode: MatchRegexp}); err == nil {
+		t.Fatalf("非法正则应返回错误")
+	}
+	if _, err := idx.Search(Query{Pattern: "[", Mode: MatchGlob}); err == nil {
+		t.Fatalf("非法通配符应返回错误")
+	}
+}
+
+// TestIndex_RefreshAndOffline 增量刷新只重列变化的目录，持久化后可离线搜索。
+func TestIndex_RefreshAndOffline(t *testing.T) {
+	ctx := context.Background()
+	api := fakecloud.New(t)
+	a := api.AddFolder(fakecloud.RootID, "a")
+	sub := api.AddFolder(a, "sub")
+	api.AddFile(sub, "deep.txt", []byte("d"))
+	b := api.AddFolder(fakecloud.RootID, "b")
+	api.AddFile(b, "old.txt", []byte("o"))
+	api.AddFolder(fakecloud.RootID, "untouched")
+
+	client := api.Client(t)
+	st := &memoryIndexStore{}
+	idx := New(client, st, WithConcurrency(2))
+	if err := idx.Build(ctx, fakecloud.RootID, "/"); err != nil {
+		t.Fatalf("建立索引失败: %v", err)
+	}
+
+	// 无变化时每个目录只请求一次首页。
+	before := api.Hits("/listFiles.action")
+	if err := idx.Refresh(ctx); err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	if got := api.Hits("/listFiles.action") - before; got != 5 {
+		t.Fatalf("无变化时应请求 5 次，实际 %d", got)
+	}
+
+	if err := client.RenameFile(ctx, a, "renamed"); err != nil {
+		t.Fatalf("重命名失败: %v", err)
+	}
+	if err := client.MoveFiles(ctx, []cloud189.FileInfo{{ID: cloud189.FlexString(sub), FileName: "sub", IsFolder: true}}, b); err != nil {
+		t.Fatalf("移动失败: %v", err)
+	}
+	oldID, _ := api.Lookup("/b/old.txt")
+	if err := client.DeleteFiles(ctx, []cloud189.FileInfo{{ID: cloud189.FlexString(oldID.ID), FileName: oldID.Name}}); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	newDir := api.AddFolder(b, "new")
+	api.AddFile(newDir, "fresh.txt", []byte("f"))
+	if err := idx.Refresh(ctx); err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	want := "/b,/b/new,/b/new/fresh.txt,/b/sub,/b/sub/deep.txt,/renamed,/untouched"
+	if got := search(t, idx, Query{}); got != want {
+		t.Fatalf("刷新后索引异常:\n得到 %s\n期望 %s", got, want)
+	}
+
+	offline := New(nil, st)
+	if err := offline.Load(); err != nil {
+		t.Fatalf("加载索引失败: %v", err)
+	}
+	if got := search(t, offline, Query{Pattern: "*.txt", Mode: MatchGlob}); got != "/b/new/fresh.txt,/b/sub/deep.txt" {
+		t.Fatalf("离线搜索结果异常: %q", got)
+	}
+	if !offline.UpdatedAt().Equal(idx.UpdatedAt()) {
+		t.Fatalf("加载后应保留刷新时间")
+	}
+
+	// 恢复后的索引可继续增量刷新。
+	restored := New(client, st)
+	if err := restored.Load(); err != nil {
+		t.Fatalf("加载索引失败: %v", err)
+	}
+	api.AddFile(fakecloud.RootID, "top.txt", []byte("t"))
+	before = api.Hits("/listFiles.action")
+	if err := restored.Refresh(ctx); err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	if got := search(t, restored, Query{Pattern: "top"}); got != "/top.txt" {
+		t.Fatalf("恢复后刷新未发现新文件: %q", got)
+	}
+	if got := api.Hits("/listFiles.action") - before; got != 7 {
+		t.Fatalf("恢复后应沿用目录基线，只重列根目录，实际请求 %d 次", got)
+	}
+	if err := New(client, nil).Refresh(ctx); err == nil {
+		t.Fatalf("未建立索引时刷新应返回错误")
+	}
+}
@@ -0,0 +1,175 @@
+package index
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	coreerrors "github.com/dnslin/cloud189-desktop/core/errors"
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// MatchMode 名称匹配方式。
+type MatchMode int
+
+const (
+	// MatchSubstring 子串匹配。
+	MatchSubstring MatchMode = iota
+	// MatchGlob 通配符匹配，语法同 path.Match，* 不匹配 /。
+	MatchGlob
+	// MatchRegexp 正则匹配，语法同 regexp，匹配任意位置。
+	MatchRegexp
+)
+
+// Kind 条目类型过滤。
+type Kind int
+
+const (
+	// KindAny 文件与文件夹。
+	KindAny Kind = iota
+	// KindFile 仅文件。
+	KindFile
+	// KindFolder 仅文件夹。
+	KindFolder
+)
+
+// Query 搜索条件，零值字段表示不过滤。
+type Query struct {
+	Pattern        string    // 为空时匹配全部
+	Mode           MatchMode // 匹配方式
+	MatchPath      bool      // 匹配完整路径而非文件名
+	CaseSensitive  bool      // 区分大小写，默认不区分
+	Kind           Kind
+	MediaKinds     []cloud189.MediaKind // 按服务端媒体类型过滤
+	Extensions     []string             // 按扩展名过滤，可带或不带点，不区分大小写
+	Under          string               // 限定在该远端路径下（不含自身）
+	MinSize        int64
+	MaxSize        int64 // <= 0 表示不限
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	Limit          int // <= 0 表示不限
+}
+
+// Search 在本地索引中搜索，结果按路径排序，不访问网络。
+func (idx *Index) Search(q Query) ([]model.File, error) {
+	match, err := q.matcher()
+	if err != nil {
+		return nil, err
+	}
+	exts := make(map[string]bool, len(q.Extensions))
+	for _, ext := range q.Extensions {
+		exts["."+strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+	}
+	under := ""
+	if q.Under != "" {
+		under = strings.TrimSuffix(cloud189.CleanPath(q.Under), "/") + "/"
+	}
+
+	idx.mu.RLock()
+	var result []model.File
+	for _, e := range idx.entries {
+		f := &e.file
+		if under != "" && !strings.HasPrefix(f.Path, under) {
+			continue
+		}
+		if !q.matchAttrs(f, exts) {
+			continue
+		}
+		subject, lower := f.Name, e.lowerName
+		if q.MatchPath {
+			subject, lower = f.Path, e.lowerPath
+		}
+		if match(subject, lower) {
+			result = append(result, e.file)
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+	return result, nil
+}
+
+// matcher 编译匹配函数，参数为原文与其小写形式，不区分大小写时子串与通配符按小写匹配。
+func (q Query) matcher() (func(s, lower string) bool, error) {
+	pattern := q.Pattern
+	if pattern == "" {
+		return func(string, string) bool { return true }, nil
+	}
+	switch q.Mode {
+	case MatchSubstring:
+		if q.CaseSensitive {
+			return func(s, _ string) bool { return strings.Contains(s, pattern) }, nil
+		}
+		pattern = strings.ToLower(pattern)
+		return func(_, lower string) bool { return strings.Contains(lower, pattern) }, nil
+	case MatchGlob:
+		if !q.CaseSensitive {
+			pattern = strings.ToLower(pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, coreerrors.Wrap(coreerrors.ErrCodeInvalidArgument, "index: 通配符格式错误", err)
+		}
+		return func(s, lower string) bool {
+			if !q.CaseSensitive {
+				s = lower
+			}
+			ok, _ := path.Match(pattern, s)
+			return ok
+		}, nil
+	case MatchRegexp:
+		if !q.CaseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, coreerrors.Wrap(coreerrors.ErrCodeInvalidArgument, "index: 正则表达式格式错误", err)
+		}
+		return func(s, _ string) bool { return re.MatchString(s) }, nil
+	default:
+		return nil, coreerrors.New(coreerrors.ErrCodeInvalidArgument, "index: 未知的匹配方式")
+	}
+}
+
+func (q Query) matchAttrs(f *model.File, exts map[string]bool) bool {
+	switch q.Kind {
+	case KindFile:
+		if f.IsFolder {
+			return false
+		}
+	case KindFolder:
+		if !f.IsFolder {
+			return false
+		}
+	}
+	if len(q.MediaKinds) > 0 {
+		found := false
+		for _, kind := range q.MediaKinds {
+			if f.MediaType == int(kind) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(exts) > 0 && (f.IsFolder || !exts[strings.ToLower(path.Ext(f.Name))]) {
+		return false
+	}
+	if f.Size < q.MinSize || (q.MaxSize > 0 && f.Size > q.MaxSize) {
+		return false
+	}
+	if !q.ModifiedAfter.IsZero() && f.UpdatedAt.Before(q.ModifiedAfter) {
+		return false
+	}
+	if !q.ModifiedBefore.IsZero() && !f.UpdatedAt.Before(q.ModifiedBefore) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,817 @@
+// Package fakecloud 提供内存版的天翼云盘 App 接口，供 core 内各包的端到端测试使用。
+//
+// 覆盖目录列表、文件信息、创建/重命名、移动/复制/删除批量任务（含同名冲突）、下载链接与分片上传，
+// 行为尽量贴近真实接口：不存在的 ID 返回 FileNotFound，下载支持 Range。
+package fakecloud
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/auth"
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	"github.com/dnslin/cloud189-desktop/core/crypto"
+)
+
+// RootID 个人云根目录 ID。
+const RootID = cloud189.RootFolderID
+
+// sessionSecret 测试会话密钥，上传参数以其前 16 位加密。
+const sessionSecret = "0123456789abcdef0123"
+
+// Node 云端文件或文件夹。
+type Node struct {
+	ID       string
+	ParentID string
+	Name     string
+	IsFolder bool
+	Data     []byte
+	Rev      int
+	Modified time.Time
+}
+
+// MD5 返回文件内容的 MD5。
+func (n Node) MD5() string {
+	if n.IsFolder {
+		return ""
+	}
+	sum := md5.Sum(n.Data)
+	return hex.EncodeToString(sum[:])
+}
+
+type upload struct {
+	parentID string
+	name     string
+	md5      string
+	size     int64 // initMultiUpload 声明的大小
+	parts    map[int][]byte
+}
+
+// Server 内存云盘服务。
+type Server struct {
+	srv *httptest.Server
+
+	mu      sync.Mutex
+	nodes   map[string]*Node
+	uploads map[string]*upload
+	tasks   map[string]*batchTask
+	nextID  int
+	epoch   int
+	hits    map[string]int
+	clock   time.Time
+}
+
+// New 启动假服务，测试结束时自动关闭。
+func New(tb testing.TB) *Server {
+	tb.Helper()
+	s := &Server{
+		nodes:   map[string]*Node{RootID: {ID: RootID, Name: "", IsFolder: true}},
+		uploads: map[string]*upload{},
+		tasks:   map[string]*batchTask{},
+		hits:    map[string]int{},
+		nextID:  100,
+		clock:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/getFileInfo.action", s.handleFileInfo)
+	mux.HandleFunc("/listFiles.action", s.handleList)
+	mux.HandleFunc("/createFolder.action", s.handleCreateFolder)
+	mux.HandleFunc("/renameFile.action", s.handleRename)
+	mux.HandleFunc("/batch/createBatchTask.action", s.handleCreateBatchTask)
+	mux.HandleFunc("/batch/checkBatchTask.action", s.handleCheckBatchTask)
+	mux.HandleFunc("/batch/getConflictTaskInfo.action", s.handleConflictTaskInfo)
+	mux.HandleFunc("/batch/manageBatchTask.action", s.handleManageBatchTask)
+	mux.HandleFunc("/getFileDownloadUrl.action", s.handleDownloadURL)
+	mux.HandleFunc("/getUserInfo.action", s.handleUserInfo)
+	mux.HandleFunc("/download/", s.handleDownload)
+	mux.HandleFunc("/person/initMultiUpload", s.handleInitUpload)
+	mux.HandleFunc("/person/getMultiUploadUrls", s.handleUploadURLs)
+	mux.HandleFunc("/person/commitMultiUploadFile", s.handleCommitUpload)
+	mux.HandleFunc("/part/", s.handlePart)
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[r.URL.Path]++
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	tb.Cleanup(s.srv.Close)
+	return s
+}
+
+// URL 返回服务地址。
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Client 创建指向假服务的客户端。
+func (s *Server) Client(tb testing.TB, opts ...cloud189.Option) *cloud189.Client {
+	tb.Helper()
+	store := &sessionStore{}
+	_ = store.SaveSession(&auth.Session{
+		SessionKey:      "test-session-key",
+		SessionSecret:   sessionSecret,
+		SSON:            "sson",
+		CookieLoginUser: "user",
+	})
+	manager := auth.NewAuthManager()
+	if err := manager.AddAccount("test", auth.AccountSession{Store: store}); err != nil {
+		tb.Fatalf("添加账号失败: %v", err)
+	}
+	opts = append([]cloud189.Option{cloud189.WithBaseURLs(s.srv.URL, s.srv.URL, s.srv.URL)}, opts...)
+	return cloud189.NewClient(manager, opts...).WithAccount("test")
+}
+
+// AddFolder 在 parentID 下直接创建文件夹，返回新 ID。
+func (s *Server) AddFolder(parentID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addLocked(parentID, name, true, nil).ID
+}
+
+// AddFile 在 parentID 下直接创建文件，返回新 ID。
+func (s *Server) AddFile(parentID, name string, data []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addLocked(parentID, name, false, data).ID
+}
+
+// Lookup 按绝对路径查找节点。
+func (s *Server) Lookup(p string) (Node, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node := s.nodes[RootID]
+	for _, name := range strings.Split(strings.Trim(path.Clean("/"+p), "/"), "/") {
+		if name == "" {
+			continue
+		}
+		if node = s.childLocked(node.ID, name); node == nil {
+			return Node{}, false
+		}
+	}
+	return s.copyNode(node), true
+}
+
+// Node 按 ID 返回节点。
+func (s *Server) Node(id string) (Node, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node, ok := s.nodes[id]
+	if !ok {
+		return Node{}, false
+	}
+	return s.copyNode(node), true
+}
+
+// Hits 返回接口路径被请求的次数。
+func (s *Server) Hits(p string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[p]
+}
+
+// ExpireDownloadURLs 使此前下发的下载链接全部失效（返回 403）。
+func (s *Server) ExpireDownloadURLs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.epoch++
+}
+
+func (s *Server) copyNode(n *Node) Node {
+	cp := *n
+	cp.Data = append([]byte(nil), n.Data...)
+	return cp
+}
+
+func (s *Server) newIDLocked() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+func (s *Server) tickLocked() time.Time {
+	s.clock = s.clock.Add(time.Second)
+	return s.clock
+}
+
+func (s *Server) addLocked(parentID, name string, folder bool, data []byte) *Node {
+	node := &Node{
+		ID:       s.newIDLocked(),
+		ParentID: parentID,
+		Name:     name,
+		IsFolder: folder,
+		Data:     append([]byte(nil), data...),
+		Rev:      1,
+		Modified: s.tickLocked(),
+	}
+	s.nodes[node.ID] = node
+	s.touchLocked(parentID)
+	return node
+}
+
+// touchLocked 子项变化时递增父目录版本。
+func (s *Server) touchLocked(id string) {
+	if node, ok := s.nodes[id]; ok {
+		node.Rev++
+		node.Modified = s.tickLocked()
+	}
+}
+
+func (s *Server) childLocked(parentID, name string) *Node {
+	for _, node := range s.nodes {
+		if node.ParentID == parentID && node.Name == name && node.ID != RootID {
+			return node
+		}
+	}
+	return nil
+}
+
+func (s *Server) childrenLocked(parentID string) []*Node {
+	var children []*Node
+	for _, node := range s.nodes {
+		if node.ParentID == parentID && node.ID != RootID {
+			children = append(children, node)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].IsFolder != children[j].IsFolder {
+			return children[i].IsFolder
+		}
+		return children[i].Name < children[j].Name
+	})
+	return children
+}
+
+func (s *Server) isAncestorLocked(ancestorID, id string) bool {
+	for cur, ok := s.nodes[id]; ok; cur, ok = s.nodes[cur.ParentID] {
+		if cur.ID == ancestorID {
+			return true
+		}
+		if cur.ID == RootID {
+			break
+		}
+	}
+	return false
+}
+
+func (s *Server) deleteLocked(id string) {
+	for _, child := range s.childrenLocked(id) {
+		s.deleteLocked(child.ID)
+	}
+	delete(s.nodes, id)
+}
+
+func (s *Server) copyTreeLocked(src *Node, parentID string) {
+	dup := s.addLocked(parentID, src.Name, src.IsFolder, src.Data)
+	for _, child := range s.childrenLocked(src.ID) {
+		s.copyTreeLocked(child, dup.ID)
+	}
+}
+
+func toJSON(n *Node) map[string]any {
+	item := map[string]any{
+		"id":         n.ID,
+		"parentId":   n.ParentID,
+		"name":       n.Name,
+		"rev":        strconv.Itoa(n.Rev),
+		"lastOpTime": n.Modified.Format("2006-01-02 15:04:05"),
+		"createDate": n.Modified.Format("2006-01-02 15:04:05"),
+	}
+	if n.IsFolder {
+		item["isFolder"] = true
+	} else {
+		item["size"] = len(n.Data)
+		item["md5"] = strings.ToUpper(n.MD5())
+		if media := mediaTypeOf(n.Name); media != 0 {
+			item["mediaType"] = media
+		}
+	}
+	return item
+}
+
+// mediaTypeOf 与真实服务端一样按扩展名推断媒体类型。
+func mediaTypeOf(name string) int {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".heic":
+		return int(cloud189.MediaPhoto)
+	case ".mp3", ".flac", ".m4a", ".wav":
+		return int(cloud189.MediaMusic)
+	case ".mp4", ".mkv", ".mov", ".avi":
+		return int(cloud189.MediaVideo)
+	case ".pdf", ".doc", ".docx", ".txt":
+		return int(cloud189.MediaDocument)
+	default:
+		return 0
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code, msg string) {
+	writeJSON(w, map[string]any{"res_code": code, "res_message": msg})
+}
+
+func writeOK(w http.ResponseWriter) {
+	writeJSON(w, map[string]any{"res_code": 0})
+}
+
+func (s *Server) handleFileInfo(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node, ok := s.nodes[r.Form.Get("fileId")]
+	if !ok {
+		writeError(w, "FileNotFound", "文件不存在")
+		return
+	}
+	rsp := toJSON(node)
+	rsp["res_code"] = 0
+	writeJSON(w, rsp)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	folder, ok := s.nodes[r.Form.Get("folderId")]
+	if !ok || !folder.IsFolder {
+		writeError(w, "FileNotFound", "文件夹不存在")
+		return
+	}
+	children := s.childrenLocked(folder.ID)
+	pageNum, _ := strconv.Atoi(r.Form.Get("pageNum"))
+	pageSize, _ := strconv.Atoi(r.Form.Get("pageSize"))
+	if pageNum < 1 {
+		pageNum = 1
+	}
+	if pageSize < 1 {
+		pageSize = 100
+	}
+	start := min((pageNum-1)*pageSize, len(children))
+	end := min(start+pageSize, len(children))
+	files, folders := []map[string]any{}, []map[string]any{}
+	for _, node := range children[start:end] {
+		if node.IsFolder {
+			folders = append(folders, toJSON(node))
+		} else {
+			files = append(files, toJSON(node))
+		}
+	}
+	writeJSON(w, map[string]any{
+		"res_code": 0,
+		"lastRev":  folder.Rev,
+		"fileListAO": map[string]any{
+			"count":      len(children),
+			"fileList":   files,
+			"folderList": folders,
+		},
+	})
+}
+
+func (s *Server) handleCreateFolder(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parentID, name := r.Form.Get("parentFolderId"), r.Form.Get("folderName")
+	parent, ok := s.nodes[parentID]
+	if !ok || !parent.IsFolder {
+		writeError(w, "FileNotFound", "上级文件夹不存在")
+		return
+	}
+	if existing := s.childLocked(parentID, name); existing != nil {
+		// 与真实接口一致：同名文件夹已存在时直接返回。
+		if existing.IsFolder {
+			rsp := toJSON(existing)
+			rsp["res_code"] = 0
+			writeJSON(w, rsp)
+			return
+		}
+		writeError(w, "FileAlreadyExists", "存在同名文件")
+		return
+	}
+	rsp := toJSON(s.addLocked(parentID, name, true, nil))
+	rsp["res_code"] = 0
+	writeJSON(w, rsp)
+}
+
+func (s *Server) handleRename(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node, ok := s.nodes[r.Form.Get("fileId")]
+	if !ok {
+		writeError(w, "FileNotFound", "文件不存在")
+		return
+	}
+	name := r.Form.Get("destFileName")
+	if existing := s.childLocked(node.ParentID, name); existing != nil && existing != node {
+		writeError(w, "FileAlreadyExists", "存在同名文件")
+		return
+	}
+	node.Name = name
+	node.Rev++
+	node.Modified = s.tickLocked()
+	s.touchLocked(node.ParentID)
+	writeOK(w)
+}
+
+// batchInfo 对应批量任务 taskInfos 中的单个文件。
+type batchInfo struct {
+	FileID   string `json:"fileId"`
+	FileName string `json:"fileName"`
+	IsFolder int    `json:"isFolder"`
+	DealWay  int    `json:"dealWay,omitempty"`
+}
+
+// batchTask 批量任务在提交时同步执行，同名冲突挂起等待 manageBatchTask 处理。
+type batchTask struct {
+	typ       string
+	targetID  string
+	conflicts []batchInfo
+	succeeded int
+	failed    int
+	skipped   int
+}
+
+func (s *Server) handleCreateBatchTask(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	var infos []batchInfo
+	if err := json.Unmarshal([]byte(r.Form.Get("taskInfos")), &infos); err != nil {
+		writeError(w, "InvalidArgument", "taskInfos 格式错误")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task := &batchTask{typ: r.Form.Get("type"), targetID: r.Form.Get("targetFolderId")}
+	switch task.typ {
+	case cloud189.BatchTaskMove, cloud189.BatchTaskCopy:
+		if dest, ok := s.nodes[task.targetID]; !ok || !dest.IsFolder {
+			writeError(w, "FileNotFound", "目标文件夹不存在")
+			return
+		}
+	case cloud189.BatchTaskDelete:
+	default:
+		writeError(w, "InvalidArgument", "不支持的任务类型")
+		return
+	}
+	for _, info := range infos {
+		node, ok := s.nodes[info.FileID]
+		if !ok {
+			task.failed++
+			continue
+		}
+		if task.typ == cloud189.BatchTaskDelete {
+			s.touchLocked(node.ParentID)
+			s.deleteLocked(node.ID)
+			task.succeeded++
+			continue
+		}
+		if existing := s.childLocked(task.targetID, node.Name); existing != nil && existing != node {
+			task.conflicts = append(task.conflicts, info)
+			continue
+		}
+		s.transferLocked(task, node, node.Name)
+	}
+	id := "task-" + strconv.Itoa(len(s.tasks)+1)
+	s.tasks[id] = task
+	writeJSON(w, map[string]any{"res_code": 0, "taskId": id})
+}
+
+// transferLocked 将 node 以 name 移动或复制到任务目标目录。
+func (s *Server) transferLocked(task *batchTask, node *Node, name string) {
+	if node.ID == task.targetID || s.isAncestorLocked(node.ID, task.targetID) {
+		task.failed++
+		return
+	}
+	task.succeeded++
+	if task.typ == cloud189.BatchTaskCopy {
+		dup := *node
+		dup.Name = name
+		s.copyTreeLocked(&dup, task.targetID)
+		return
+	}
+	if node.ParentID == task.targetID && node.Name == name {
+		return
+	}
+	s.touchLocked(node.ParentID)
+	node.ParentID, node.Name = task.targetID, name
+	node.Rev++
+	node.Modified = s.tickLocked()
+	s.touchLocked(task.targetID)
+}
+
+// freeNameLocked 按 "name(1).ext" 形式生成目标目录内不重名的名称。
+func (s *Server) freeNameLocked(parentID, name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		candidate := base + "(" + strconv.Itoa(i) + ")" + ext
+		if s.childLocked(parentID, candidate) == nil {
+			return candidate
+		}
+	}
+}
+
+func (s *Server) handleCheckBatchTask(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.tasks[r.Form.Get("taskId")]
+	if !ok {
+		writeError(w, "InvalidArgument", "任务不存在")
+		return
+	}
+	status := 4
+	if len(task.conflicts) > 0 {
+		status = 2
+	}
+	writeJSON(w, map[string]any{
+		"res_code":       0,
+		"taskStatus":     status,
+		"successedCount": task.succeeded,
+		"failedCount":    task.failed,
+		"skipCount":      task.skipped,
+	})
+}
+
+func (s *Server) handleConflictTaskInfo(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.tasks[r.Form.Get("taskId")]
+	if !ok {
+		writeError(w, "InvalidArgument", "任务不存在")
+		return
+	}
+	writeJSON(w, map[string]any{"res_code": 0, "taskInfos": task.conflicts})
+}
+
+func (s *Server) handleManageBatchTask(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	var infos []batchInfo
+	if err := json.Unmarshal([]byte(r.Form.Get("taskInfos")), &infos); err != nil {
+		writeError(w, "InvalidArgument", "taskInfos 格式错误")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.tasks[r.Form.Get("taskId")]
+	if !ok {
+		writeError(w, "InvalidArgument", "任务不存在")
+		return
+	}
+	for _, info := range infos {
+		node, ok := s.nodes[info.FileID]
+		if !ok {
+			task.failed++
+			continue
+		}
+		switch info.DealWay {
+		case 2:
+			s.transferLocked(task, node, s.freeNameLocked(task.targetID, node.Name))
+		case 3:
+			if existing := s.childLocked(task.targetID, node.Name); existing != nil && existing != node {
+				s.deleteLocked(existing.ID)
+			}
+			s.transferLocked(task, node, node.Name)
+		default:
+			task.skipped++
+		}
+	}
+	task.conflicts = nil
+	writeOK(w)
+}
+
+func (s *Server) handleDownloadURL(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node, ok := s.nodes[r.Form.Get("fileId")]
+	if !ok || node.IsFolder {
+		writeError(w, "FileNotFound", "文件不存在")
+		return
+	}
+	link := s.srv.URL + "/download/" + url.PathEscape(node.ID) + "?token=" + strconv.Itoa(s.epoch)
+	writeJSON(w, map[string]any{"res_code": 0, "fileDownloadUrl": link})
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	node, ok := s.nodes[strings.TrimPrefix(r.URL.Path, "/download/")]
+	expired := r.URL.Query().Get("token") != strconv.Itoa(s.epoch)
+	var (
+		data    []byte
+		modTime time.Time
+	)
+	if ok {
+		data, modTime = node.Data, node.Modified
+	}
+	s.mu.Unlock()
+	switch {
+	case expired:
+		http.Error(w, "link expired", http.StatusForbidden)
+	case !ok:
+		http.NotFound(w, r)
+	default:
+		http.ServeContent(w, r, "", modTime, bytes.NewReader(data))
+	}
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var used int
+	for _, node := range s.nodes {
+		used += len(node.Data)
+	}
+	const capacity = 1 << 40
+	writeJSON(w, map[string]any{
+		"res_code":  0,
+		"userId":    "fake",
+		"capacity":  capacity,
+		"usedSize":  used,
+		"available": capacity - used,
+	})
+}
+
+// uploadParams 解密 AppUpload 的 params 参数。
+func uploadParams(r *http.Request) url.Values {
+	cipher, err := hex.DecodeString(r.URL.Query().Get("params"))
+	if err != nil {
+		return url.Values{}
+	}
+	plain, err := crypto.DecryptECB([]byte(sessionSecret[:16]), cipher)
+	if err != nil {
+		return url.Values{}
+	}
+	values, _ := url.ParseQuery(string(plain))
+	return values
+}
+
+func (s *Server) findByMD5Locked(sum string) *Node {
+	for _, node := range s.nodes {
+		if !node.IsFolder && strings.EqualFold(node.MD5(), sum) {
+			return node
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleInitUpload(w http.ResponseWriter, r *http.Request) {
+	params := uploadParams(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parent, ok := s.nodes[params.Get("parentFolderId")]
+	if !ok || !parent.IsFolder {
+		writeError(w, "FileNotFound", "上级文件夹不存在")
+		return
+	}
+	id := "up-" + s.newIDLocked()
+	size, _ := strconv.ParseInt(params.Get("fileSize"), 10, 64)
+	up := &upload{parentID: parent.ID, name: params.Get("fileName"), size: size, parts: map[int][]byte{}}
+	exists := 0
+	if params.Get("lazyCheck") == "" && params.Get("fileMd5") != "" && s.findByMD5Locked(params.Get("fileMd5")) != nil {
+		up.md5 = params.Get("fileMd5")
+		exists = 1
+	}
+	s.uploads[id] = up
+	writeJSON(w, map[string]any{"code": "SUCCESS", "data": map[string]any{
+		"uploadFileId":   id,
+		"fileDataExists": exists,
+	}})
+}
+
+func (s *Server) handleUploadURLs(w http.ResponseWriter, r *http.Request) {
+	params := uploadParams(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := params.Get("uploadFileId")
+	if _, ok := s.uploads[id]; !ok {
+		writeError(w, "UploadFileNotFound", "上传会话不存在")
+		return
+	}
+	part, _, _ := strings.Cut(params.Get("partInfo"), "-")
+	writeJSON(w, map[string]any{"code": "SUCCESS", "uploadUrls": map[string]any{
+		"partNumber_" + part: map[string]any{"requestURL": s.srv.URL + "/part/" + id + "/" + part},
+	}})
+}
+
+func (s *Server) handlePart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, part, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/part/"), "/")
+	num, err := strconv.Atoi(part)
+	var buf bytes.Buffer
+	if _, copyErr := buf.ReadFrom(r.Body); copyErr != nil || err != nil {
+		http.Error(w, "bad part", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	up.parts[num] = buf.Bytes()
+}
+
+func (s *Server) handleCommitUpload(w http.ResponseWriter, r *http.Request) {
+	params := uploadParams(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := params.Get("uploadFileId")
+	up, ok := s.uploads[id]
+	if !ok {
+		writeError(w, "UploadFileNotFound", "上传会话不存在")
+		return
+	}
+	delete(s.uploads, id)
+	var data []byte
+	if up.md5 != "" {
+		data = append([]byte(nil), s.findByMD5Locked(up.md5).Data...)
+	} else {
+		nums := make([]int, 0, len(up.parts))
+		for n := range up.parts {
+			nums = append(nums, n)
+		}
+		sort.Ints(nums)
+		for _, n := range nums {
+			data = append(data, up.parts[n]...)
+		}
+		if int64(len(data)) != up.size {
+			writeError(w, "InvalidFileSize", "上传数据与声明大小不符")
+			return
+		}
+	}
+	name := up.name
+	existing := s.childLocked(up.parentID, name)
+	var node *Node
+	switch {
+	case existing != nil && !existing.IsFolder && params.Get("opertype") == "3":
+		node = existing
+		node.Data = data
+		node.Rev++
+		node.Modified = s.tickLocked()
+		s.touchLocked(up.parentID)
+	case existing != nil:
+		// 未指定覆盖时与真实接口一样自动重命名。
+		ext := path.Ext(name)
+		for i := 1; s.childLocked(up.parentID, name) != nil; i++ {
+			name = strings.TrimSuffix(up.name, ext) + "(" + strconv.Itoa(i) + ")" + ext
+		}
+		fallthrough
+	default:
+		node = s.addLocked(up.parentID, name, false, data)
+	}
+	writeJSON(w, map[string]any{"code": "SUCCESS", "file": map[string]any{
+		"userFileId": node.ID,
+		"file_name":  node.Name,
+		"file_size":  len(node.Data),
+		"file_md_5":  node.MD5(),
+	}})
+}
+
+// sessionStore 内存会话存储。
+type sessionStore struct {
+	mu      sync.Mutex
+	session *auth.Session
+}
+
+func (s *sessionStore) SaveSession(session *auth.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = session.Clone()
+	return nil
+}
+
+func (s *sessionStore) LoadSession() (*auth.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
+	return s.session.Clone(), nil
+}
+
+func (s *sessionStore) ClearSession() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = nil
+	return nil
+}
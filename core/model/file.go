@@ -16,7 +16,22 @@ type File struct {
 	IsFolder    bool
 	ChildCount  int
 	ParentPath  string
+	Path        string // 完整远端路径，仅遍历、索引等场景填充
 	DownloadURL string
+	Thumbnails  Thumbnails // 缩略图地址，仅列表请求附带图标时填充
 	UpdatedAt   time.Time
 	CreatedAt   time.Time
+	TakenAt     time.Time // 拍摄时间，仅照片/视频且服务端返回时填充
+}
+
+// Thumbnails 图片、视频文件的缩略图地址。
+type Thumbnails struct {
+	Small  string
+	Medium string
+	Large  string
+}
+
+// Empty 判断是否没有任何缩略图。
+func (t Thumbnails) Empty() bool {
+	return t.Small == "" && t.Medium == "" && t.Large == ""
 }
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Share 表示一个分享链接，供业务层展示与管理。
+type Share struct {
+	ID            string
+	FileID        string
+	FileName      string
+	IsFolder      bool
+	URL           string
+	AccessCode    string
+	Permanent     bool
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+	ViewCount     int
+	DownloadCount int
+	SaveCount     int
+}
+
+// Expired 判断分享是否已过期。
+func (s Share) Expired(now time.Time) bool {
+	if s.Permanent || s.ExpiresAt.IsZero() {
+		return false
+	}
+	return !now.Before(s.ExpiresAt)
+}
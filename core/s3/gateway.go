@@ -0,0 +1,607 @@
+// Package s3 提供最小化的 S3 兼容网关，供只支持 S3 协议的工具访问云盘。
+//
+// 存储桶映射为云盘根目录下的文件夹，对象键映射为桶内的相对路径，仅支持 path-style 地址。
+// 覆盖 ListBuckets、ListObjectsV2、GetObject（含 Range）、HeadObject、PutObject、DeleteObject
+// 与分片上传；全部请求需通过 SigV4 签名校验。
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	"github.com/dnslin/cloud189-desktop/core/drive"
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+const (
+	s3NS            = "http://s3.amazonaws.com/doc/2006-03-01/"
+	defaultMaxKeys  = 1000
+	lastModifiedFmt = "2006-01-02T15:04:05.000Z"
+)
+
+// Gateway S3 兼容网关，实现 http.Handler。
+type Gateway struct {
+	drive     *drive.Cloud189Drive
+	ownsDrive bool // Drive 由网关创建，Close 时一并关闭
+	creds     Credentials
+	now       func() time.Time
+
+	// 分片上传暂存配置
+	spoolDir      string        // 暂存目录，为空时使用系统临时目录
+	spoolMaxBytes int64         // 全部上传暂存字节数上限，0 表示不限制
+	uploadExpiry  time.Duration // 未完成上传的保留时长
+	stop          chan struct{}
+	closeOnce     sync.Once
+
+	mu      sync.Mutex
+	uploads map[string]*multipartUpload
+	spooled int64 // 当前已暂存的字节数
+}
+
+// Option 配置 Gateway。
+type Option func(*Gateway)
+
+// WithDrive 复用已有的 Drive，便于与 WebDAV 等组件共享路径缓存。
+func WithDrive(d *drive.Cloud189Drive) Option {
+	return func(g *Gateway) {
+		if d != nil {
+			g.drive = d
+		}
+	}
+}
+
+// WithMultipartDir 指定分片上传的暂存目录，目录需已存在。
+func WithMultipartDir(dir string) Option {
+	return func(g *Gateway) {
+		g.spoolDir = dir
+	}
+}
+
+// WithMultipartMaxBytes 限制全部分片上传暂存的总字节数，超出时拒绝新分片。
+func WithMultipartMaxBytes(n int64) Option {
+	return func(g *Gateway) {
+		if n > 0 {
+			g.spoolMaxBytes = n
+		}
+	}
+}
+
+// WithMultipartExpiry 设置未完成分片上传的保留时长，默认 24 小时。
+func WithMultipartExpiry(d time.Duration) Option {
+	return func(g *Gateway) {
+		if d > 0 {
+			g.uploadExpiry = d
+		}
+	}
+}
+
+// NewGateway 创建网关，creds 为客户端签名所用的密钥对。
+func NewGateway(client *cloud189.Client, creds Credentials, opts ...Option) *Gateway {
+	g := &Gateway{
+		creds:        creds,
+		now:          time.Now,
+		uploadExpiry: multipartExpiry,
+		stop:         make(chan struct{}),
+		uploads:      make(map[string]*multipartUpload),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(g)
+		}
+	}
+	if g.drive == nil {
+		g.drive = drive.NewCloud189Drive(client)
+		g.ownsDrive = true
+	}
+	go g.reapUploads()
+	return g
+}
+
+// Close 停止过期清理，丢弃未完成的分片上传并关闭网关自行创建的 Drive，经 WithDrive 共享的 Drive 由调用方关闭。
+func (g *Gateway) Close() error {
+	g.closeOnce.Do(func() { close(g.stop) })
+	g.mu.Lock()
+	ids := make([]string, 0, len(g.uploads))
+	for id := range g.uploads {
+		ids = append(ids, id)
+	}
+	g.mu.Unlock()
+	for _, id := range ids {
+		g.removeUpload(id)
+	}
+	if g.ownsDrive {
+		return g.drive.Close()
+	}
+	return nil
+}
+
+// ServeHTTP 实现 http.Handler。
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if g.creds.AccessKey == "" || g.creds.SecretKey == "" {
+		writeError(w, r, http.StatusForbidden, "AccessDenied", "网关未配置访问密钥")
+		return
+	}
+	payloadHash, err := verifySigV4(r, g.creds, g.now().UTC())
+	if err != nil {
+		var se *sigError
+		errors.As(err, &se)
+		writeError(w, r, http.StatusForbidden, se.Code, se.Message)
+		return
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+	switch {
+	case bucket == "" && r.Method == http.MethodGet:
+		g.listBuckets(w, r)
+	case bucket == "":
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "不支持的操作")
+	case key == "":
+		g.serveBucket(w, r, bucket)
+	case query.Has("uploads") && r.Method == http.MethodPost:
+		g.createMultipart(w, r, bucket, key)
+	case query.Has("uploadId"):
+		switch r.Method {
+		case http.MethodPut:
+			g.uploadPart(w, r, key, payloadHash)
+		case http.MethodPost:
+			g.completeMultipart(w, r, bucket, key)
+		case http.MethodDelete:
+			g.abortMultipart(w, r)
+		default:
+			writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "不支持的操作")
+		}
+	default:
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			g.getObject(w, r, bucket, key)
+		case http.MethodPut:
+			g.putObject(w, r, bucket, key, payloadHash)
+		case http.MethodDelete:
+			g.deleteObject(w, r, bucket, key)
+		default:
+			writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "不支持的操作")
+		}
+	}
+}
+
+func (g *Gateway) serveBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodPut:
+		if _, err := g.drive.Mkdir(ctx, "/"+bucket); err != nil {
+			g.writeDriveError(w, r, err, "NoSuchBucket")
+			return
+		}
+		w.Header().Set("Location", "/"+bucket)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodHead:
+		if _, err := g.statBucket(ctx, bucket); err != nil {
+			g.writeDriveError(w, r, err, "NoSuchBucket")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") != "2" {
+			writeError(w, r, http.StatusNotImplemented, "NotImplemented", "仅支持 ListObjectsV2")
+			return
+		}
+		g.listObjectsV2(w, r, bucket)
+	default:
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "不支持的操作")
+	}
+}
+
+func (g *Gateway) statBucket(ctx context.Context, bucket string) (model.File, error) {
+	if bucket == "." || bucket == ".." {
+		return model.File{}, drive.ErrNotFound
+	}
+	file, err := g.drive.Stat(ctx, "/"+bucket)
+	if err == nil && !file.IsFolder {
+		return model.File{}, drive.ErrNotFound
+	}
+	return file, err
+}
+
+// objectPath 将对象键转换为云盘路径，拒绝会被路径规范化改写的键。
+func objectPath(bucket, key string) (string, bool) {
+	p := "/" + bucket + "/" + strings.TrimSuffix(key, "/")
+	return p, cloud189.CleanPath(p) == p
+}
+
+type bucketXML struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type listBucketsResult struct {
+	XMLName xml.Name    `xml:"ListAllMyBucketsResult"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	OwnerID string      `xml:"Owner>ID"`
+	Buckets []bucketXML `xml:"Buckets>Bucket"`
+}
+
+func (g *Gateway) listBuckets(w http.ResponseWriter, r *http.Request) {
+	files, err := g.drive.List(r.Context(), "/")
+	if err != nil {
+		g.writeDriveError(w, r, err, "NoSuchBucket")
+		return
+	}
+	result := listBucketsResult{Xmlns: s3NS, OwnerID: "cloud189"}
+	for _, f := range files {
+		if f.IsFolder {
+			result.Buckets = append(result.Buckets, bucketXML{Name: f.Name, CreationDate: formatTime(f.CreatedAt)})
+		}
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+type objectXML struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type prefixXML struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listObjectsV2Result struct {
+	XMLName               xml.Name    `xml:"ListBucketResult"`
+	Xmlns                 string      `xml:"xmlns,attr"`
+	Name                  string      `xml:"Name"`
+	Prefix                string      `xml:"Prefix"`
+	Delimiter             string      `xml:"Delimiter,omitempty"`
+	MaxKeys               int         `xml:"MaxKeys"`
+	KeyCount              int         `xml:"KeyCount"`
+	IsTruncated           bool        `xml:"IsTruncated"`
+	ContinuationToken     string      `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string      `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string      `xml:"StartAfter,omitempty"`
+	Contents              []objectXML `xml:"Contents"`
+	CommonPrefixes        []prefixXML `xml:"CommonPrefixes"`
+}
+
+// listEntry 列表中的对象或公共前缀。
+type listEntry struct {
+	key    string
+	file   model.File
+	prefix bool
+}
+
+func (g *Gateway) listObjectsV2(w http.ResponseWriter, r *http.Request, bucket string) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	prefix, delimiter := q.Get("prefix"), q.Get("delimiter")
+	if delimiter != "" && delimiter != "/" {
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented", "仅支持以 / 作为分隔符")
+		return
+	}
+	maxKeys := defaultMaxKeys
+	if raw := q.Get("max-keys"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeError(w, r, http.StatusBadRequest, "InvalidArgument", "max-keys 无效")
+			return
+		}
+		maxKeys = min(n, defaultMaxKeys)
+	}
+	marker := q.Get("start-after")
+	if token := q.Get("continuation-token"); token != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "InvalidArgument", "continuation-token 无效")
+			return
+		}
+		marker = string(decoded)
+	}
+	if _, err := g.statBucket(ctx, bucket); err != nil {
+		g.writeDriveError(w, r, err, "NoSuchBucket")
+		return
+	}
+
+	entries, err := g.collect(ctx, bucket, prefix, delimiter, marker, maxKeys+1)
+	if err != nil {
+		g.writeDriveError(w, r, err, "NoSuchBucket")
+		return
+	}
+
+	result := listObjectsV2Result{
+		Xmlns:             s3NS,
+		Name:              bucket,
+		Prefix:            prefix,
+		Delimiter:         delimiter,
+		MaxKeys:           maxKeys,
+		ContinuationToken: q.Get("continuation-token"),
+		StartAfter:        q.Get("start-after"),
+	}
+	if len(entries) > maxKeys {
+		entries = entries[:maxKeys]
+		result.IsTruncated = true
+		if maxKeys > 0 {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(entries[maxKeys-1].key))
+		}
+	}
+	for _, e := range entries {
+		if e.prefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, prefixXML{Prefix: e.key})
+			continue
+		}
+		result.Contents = append(result.Contents, objectXML{
+			Key:          e.key,
+			LastModified: formatTime(e.file.UpdatedAt),
+			ETag:         etag(e.file),
+			Size:         e.file.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+	result.KeyCount = len(entries)
+	writeXML(w, http.StatusOK, result)
+}
+
+// errListFull 已收集到足够条目，提前结束遍历。
+var errListFull = errors.New("s3: 列表已满")
+
+// collect 从前缀所在目录开始按键的字典序收集 marker 之后的至多 limit 个条目；
+// 有分隔符时只列一层，否则递归。键不大于 marker 的子树整体跳过，翻页时无需重新遍历整个桶。
+func (g *Gateway) collect(ctx context.Context, bucket, prefix, delimiter, marker string, limit int) ([]listEntry, error) {
+	dir := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = prefix[:i]
+	}
+	var entries []listEntry
+	add := func(e listEntry) error {
+		if e.key <= marker {
+			return nil
+		}
+		if len(entries) >= limit {
+			return errListFull
+		}
+		entries = append(entries, e)
+		return nil
+	}
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		remote := path.Join("/"+bucket, dir)
+		if remote != "/"+bucket && !strings.HasPrefix(remote, "/"+bucket+"/") {
+			return nil
+		}
+		files, err := g.drive.List(ctx, remote)
+		if errors.Is(err, drive.ErrNotFound) || errors.Is(err, drive.ErrNotDir) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// 文件夹以 name/ 参与排序，深度优先遍历即得到全局有序的键。
+		sortKey := func(f model.File) string {
+			if f.IsFolder {
+				return f.Name + "/"
+			}
+			return f.Name
+		}
+		sort.Slice(files, func(i, j int) bool { return sortKey(files[i]) < sortKey(files[j]) })
+		for _, f := range files {
+			key := strings.TrimPrefix(dir+"/"+f.Name, "/")
+			if !f.IsFolder {
+				if strings.HasPrefix(key, prefix) {
+					if err := add(listEntry{key: key, file: f}); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			folderKey := key + "/"
+			switch {
+			case delimiter != "" && strings.HasPrefix(folderKey, prefix):
+				if err := add(listEntry{key: folderKey, prefix: true}); err != nil {
+					return err
+				}
+			case delimiter == "" && (strings.HasPrefix(folderKey, prefix) || strings.HasPrefix(prefix, folderKey)):
+				if marker > folderKey && !strings.HasPrefix(marker, folderKey) {
+					continue
+				}
+				if err := walk(key); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(dir); err != nil && !errors.Is(err, errListFull) {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (g *Gateway) getObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	name, ok := objectPath(bucket, key)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchKey", "对象不存在")
+		return
+	}
+	if _, err := g.statBucket(ctx, bucket); err != nil {
+		g.writeDriveError(w, r, err, "NoSuchBucket")
+		return
+	}
+	file, err := g.drive.Stat(ctx, name)
+	if err == nil && file.IsFolder {
+		err = drive.ErrNotFound
+	}
+	if err != nil {
+		g.writeDriveError(w, r, err, "NoSuchKey")
+		return
+	}
+	reader, err := g.drive.OpenRandom(ctx, name)
+	if err != nil {
+		g.writeDriveError(w, r, err, "NoSuchKey")
+		return
+	}
+	defer reader.Close()
+	w.Header().Set("ETag", etag(file))
+	if ct := mime.TypeByExtension(path.Ext(file.Name)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	http.ServeContent(w, r, "", file.UpdatedAt, reader)
+}
+
+func (g *Gateway) putObject(w http.ResponseWriter, r *http.Request, bucket, key, payloadHash string) {
+	ctx := r.Context()
+	if r.Header.Get("X-Amz-Copy-Source") != "" {
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented", "不支持 CopyObject")
+		return
+	}
+	name, ok := objectPath(bucket, key)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "InvalidArgument", "对象键无效")
+		return
+	}
+	if _, err := g.statBucket(ctx, bucket); err != nil {
+		g.writeDriveError(w, r, err, "NoSuchBucket")
+		return
+	}
+	body := payloadReader(r, payloadHash)
+	// 以 / 结尾的空对象是目录标记，映射为文件夹。
+	if strings.HasSuffix(key, "/") && decodedLength(r, payloadHash) <= 0 {
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			g.writeDriveError(w, r, err, "NoSuchKey")
+			return
+		}
+		if _, err := g.drive.Mkdir(ctx, name); err != nil {
+			g.writeDriveError(w, r, err, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", `"`+hex.EncodeToString(md5.New().Sum(nil))+`"`)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if _, err := g.drive.Mkdir(ctx, path.Dir(name)); err != nil {
+		g.writeDriveError(w, r, err, "NoSuchKey")
+		return
+	}
+	sum := md5.New()
+	if _, err := g.drive.Create(ctx, name, io.TeeReader(body, sum), decodedLength(r, payloadHash)); err != nil {
+		g.writeDriveError(w, r, err, "NoSuchKey")
+		return
+	}
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum.Sum(nil))+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *Gateway) deleteObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	name, ok := objectPath(bucket, key)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if _, err := g.statBucket(ctx, bucket); err != nil {
+		g.writeDriveError(w, r, err, "NoSuchBucket")
+		return
+	}
+	// 与 S3 一致：对象不存在时同样返回 204。文件夹不是对象，只有 key/ 目录标记
+	// 指向空文件夹时才删除，避免误删整棵子树。
+	file, err := g.drive.Stat(ctx, name)
+	if errors.Is(err, drive.ErrNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		g.writeDriveError(w, r, err, "NoSuchKey")
+		return
+	}
+	if file.IsFolder != strings.HasSuffix(key, "/") {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if file.IsFolder {
+		children, err := g.drive.List(ctx, name)
+		if err != nil {
+			g.writeDriveError(w, r, err, "NoSuchKey")
+			return
+		}
+		if len(children) > 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	if err := g.drive.Remove(ctx, name); err != nil && !errors.Is(err, drive.ErrNotFound) {
+		g.writeDriveError(w, r, err, "NoSuchKey")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// errorXML S3 错误响应。
+type errorXML struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	writeXML(w, status, errorXML{Code: code, Message: message, Resource: r.URL.Path})
+}
+
+// writeDriveError 将 Drive 错误映射为 S3 错误，notFoundCode 为不存在时使用的错误码。
+func (g *Gateway) writeDriveError(w http.ResponseWriter, r *http.Request, err error, notFoundCode string) {
+	switch {
+	case errors.Is(err, drive.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, notFoundCode, "目标不存在")
+	case errors.Is(err, errPayloadHash):
+		writeError(w, r, http.StatusBadRequest, "XAmzContentSHA256Mismatch", "请求体哈希不匹配")
+	case errors.Is(err, drive.ErrExist), errors.Is(err, drive.ErrNotDir), errors.Is(err, drive.ErrIsDir):
+		writeError(w, r, http.StatusConflict, "InvalidRequest", err.Error())
+	default:
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+	}
+}
+
+func writeXML(w http.ResponseWriter, status int, v any) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, xml.Header)
+	_, _ = w.Write(data)
+}
+
+func etag(file model.File) string {
+	if file.MD5 != "" {
+		return `"` + strings.ToLower(file.MD5) + `"`
+	}
+	return `"` + file.ID + "-" + file.Revision + `"`
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(lastModifiedFmt)
+}
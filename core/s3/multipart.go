@@ -0,0 +1,394 @@
+package s3
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// multipartExpiry 未完成的分片上传默认保留时长，超时后丢弃其暂存数据。
+	multipartExpiry = 24 * time.Hour
+	// multipartReapInterval 过期清理的最长间隔。
+	multipartReapInterval = 10 * time.Minute
+)
+
+// multipartUpload 一次 S3 分片上传。分片先暂存到本地临时目录，
+// 完成时按编号拼接并以确定的总大小上传到云端。
+type multipartUpload struct {
+	mu      sync.Mutex
+	bucket  string
+	key     string
+	path    string
+	dir     string // 暂存分片的临时目录
+	created time.Time
+	parts   map[int]multipartPart
+	// completing 正在向云端传输，期间拒绝新的分片与重复的完成请求，也不会被过期清理
+	completing bool
+	removed    bool // 已完成、中止或过期，暂存数据已删除
+}
+
+// multipartPart 已暂存的单个分片。
+type multipartPart struct {
+	file string
+	size int64
+	etag string
+}
+
+type initiateMultipartResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completeMultipartRequest struct {
+	Parts []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type completeMultipartResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+func (g *Gateway) createMultipart(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	name, ok := objectPath(bucket, key)
+	if !ok || strings.HasSuffix(key, "/") {
+		writeError(w, r, http.StatusBadRequest, "InvalidArgument", "对象键无效")
+		return
+	}
+	if _, err := g.statBucket(ctx, bucket); err != nil {
+		g.writeDriveError(w, r, err, "NoSuchBucket")
+		return
+	}
+	if existing, err := g.drive.Stat(ctx, name); err == nil && existing.IsFolder {
+		writeError(w, r, http.StatusConflict, "InvalidRequest", "目标是文件夹")
+		return
+	}
+	if _, err := g.drive.Mkdir(ctx, path.Dir(name)); err != nil {
+		g.writeDriveError(w, r, err, "NoSuchKey")
+		return
+	}
+	dir, err := os.MkdirTemp(g.spoolDir, "cloud189-s3-*")
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", "创建分片暂存目录失败")
+		return
+	}
+	now := g.now()
+	id := newUploadID()
+	g.mu.Lock()
+	g.uploads[id] = &multipartUpload{
+		bucket:  bucket,
+		key:     key,
+		path:    name,
+		dir:     dir,
+		created: now,
+		parts:   make(map[int]multipartPart),
+	}
+	g.mu.Unlock()
+	writeXML(w, http.StatusOK, initiateMultipartResult{Xmlns: s3NS, Bucket: bucket, Key: key, UploadID: id})
+}
+
+func (g *Gateway) upload(r *http.Request) (*multipartUpload, string) {
+	id := r.URL.Query().Get("uploadId")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.uploads[id], id
+}
+
+// removeUpload 从表中移除上传并删除暂存数据，id 不存在时忽略。
+func (g *Gateway) removeUpload(id string) {
+	g.mu.Lock()
+	up := g.uploads[id]
+	delete(g.uploads, id)
+	g.mu.Unlock()
+	if up != nil {
+		up.mu.Lock()
+		freed := up.discard()
+		up.mu.Unlock()
+		g.reserveSpool(-freed)
+	}
+}
+
+// reapUploads 定期丢弃过期的分片上传，直到 Close。
+func (g *Gateway) reapUploads() {
+	interval := g.uploadExpiry
+	if interval > multipartReapInterval {
+		interval = multipartReapInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+			g.expireUploads(g.now().Add(-g.uploadExpiry))
+		}
+	}
+}
+
+// expireUploads 丢弃 before 之前创建且未在传输中的上传。
+func (g *Gateway) expireUploads(before time.Time) {
+	g.mu.Lock()
+	expired := make(map[string]*multipartUpload)
+	for id, up := range g.uploads {
+		if up.created.Before(before) {
+			expired[id] = up
+		}
+	}
+	g.mu.Unlock()
+	for id, up := range expired {
+		up.mu.Lock()
+		if up.completing {
+			up.mu.Unlock()
+			continue
+		}
+		freed := up.discard()
+		up.mu.Unlock()
+		g.reserveSpool(-freed)
+		g.mu.Lock()
+		if g.uploads[id] == up {
+			delete(g.uploads, id)
+		}
+		g.mu.Unlock()
+	}
+}
+
+// reserveSpool 按 delta 调整暂存字节数，增加后超出上限时不做调整并返回 false。
+func (g *Gateway) reserveSpool(delta int64) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if delta > 0 && g.spoolMaxBytes > 0 && g.spooled+delta > g.spoolMaxBytes {
+		return false
+	}
+	g.spooled += delta
+	return true
+}
+
+// discard 删除暂存数据并返回释放的字节数，调用方持有 up.mu。
+func (up *multipartUpload) discard() int64 {
+	var freed int64
+	for _, part := range up.parts {
+		freed += part.size
+	}
+	up.removed = true
+	up.parts = nil
+	_ = os.RemoveAll(up.dir)
+	return freed
+}
+
+func (g *Gateway) uploadPart(w http.ResponseWriter, r *http.Request, key, payloadHash string) {
+	up, _ := g.upload(r)
+	if up == nil || up.key != key {
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	num, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || num < 1 || num > 10000 {
+		writeError(w, r, http.StatusBadRequest, "InvalidArgument", "partNumber 无效")
+		return
+	}
+	// 分片写入独立的临时文件，不持有 up.mu，允许客户端并发上传。
+	tmp, err := os.CreateTemp(up.dir, "part-*")
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	sum := md5.New()
+	body := payloadReader(r, payloadHash)
+	if g.spoolMaxBytes > 0 {
+		// 超出上限的分片无需完整落盘，多读一个字节即可判定
+		body = io.LimitReader(body, g.spoolMaxBytes+1)
+	}
+	size, err := io.Copy(io.MultiWriter(tmp, sum), body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		g.writeDriveError(w, r, err, "NoSuchUpload")
+		return
+	}
+	tag := `"` + hex.EncodeToString(sum.Sum(nil)) + `"`
+
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	if up.removed {
+		_ = os.RemoveAll(up.dir)
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	if up.completing {
+		_ = os.Remove(tmp.Name())
+		writeError(w, r, http.StatusConflict, "OperationAborted", "分片上传正在完成")
+		return
+	}
+	// 与 S3 一致：重传同一编号的分片时替换旧数据。
+	old, replaced := up.parts[num]
+	if !g.reserveSpool(size - old.size) {
+		_ = os.Remove(tmp.Name())
+		writeError(w, r, http.StatusBadRequest, "EntityTooLarge", "分片暂存空间不足")
+		return
+	}
+	if replaced {
+		_ = os.Remove(old.file)
+	}
+	up.parts[num] = multipartPart{file: tmp.Name(), size: size, etag: tag}
+	w.Header().Set("ETag", tag)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *Gateway) completeMultipart(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	up, id := g.upload(r)
+	if up == nil || up.key != key {
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	var req completeMultipartRequest
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		writeError(w, r, http.StatusBadRequest, "MalformedXML", "请求体格式错误")
+		return
+	}
+
+	up.mu.Lock()
+	if up.removed {
+		up.mu.Unlock()
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	if up.completing {
+		up.mu.Unlock()
+		writeError(w, r, http.StatusConflict, "OperationAborted", "分片上传正在完成")
+		return
+	}
+	// 分片编号须严格递增且 ETag 与已上传分片一致，未列出的分片被丢弃。
+	final := md5.New()
+	files := make([]string, 0, len(req.Parts))
+	var total int64
+	for i, part := range req.Parts {
+		tag := `"` + strings.Trim(part.ETag, `"`) + `"`
+		stored, ok := up.parts[part.PartNumber]
+		if !ok || stored.etag != tag {
+			up.mu.Unlock()
+			writeError(w, r, http.StatusBadRequest, "InvalidPart", "分片不存在或 ETag 不符")
+			return
+		}
+		if i > 0 && part.PartNumber <= req.Parts[i-1].PartNumber {
+			up.mu.Unlock()
+			writeError(w, r, http.StatusBadRequest, "InvalidPartOrder", "分片编号未按升序排列")
+			return
+		}
+		raw, _ := hex.DecodeString(strings.Trim(tag, `"`))
+		final.Write(raw)
+		files = append(files, stored.file)
+		total += stored.size
+	}
+	// 传输期间不持有 up.mu，completing 标记阻止分片被替换或重复完成。
+	up.completing = true
+	up.mu.Unlock()
+
+	body := &partsReader{files: files}
+	_, err := g.drive.Create(r.Context(), up.path, body, total)
+	body.Close()
+
+	up.mu.Lock()
+	up.completing = false
+	var freed int64
+	if err == nil {
+		freed = up.discard()
+	}
+	up.mu.Unlock()
+	if err != nil {
+		// 失败时保留暂存分片，客户端可重试完成请求
+		g.writeDriveError(w, r, err, "NoSuchUpload")
+		return
+	}
+	g.reserveSpool(-freed)
+	g.mu.Lock()
+	if g.uploads[id] == up {
+		delete(g.uploads, id)
+	}
+	g.mu.Unlock()
+
+	tag := `"` + hex.EncodeToString(final.Sum(nil)) + "-" + strconv.Itoa(len(req.Parts)) + `"`
+	writeXML(w, http.StatusOK, completeMultipartResult{
+		Xmlns:    s3NS,
+		Location: "/" + bucket + "/" + key,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     tag,
+	})
+}
+
+// abortMultipart 删除暂存的分片数据。
+func (g *Gateway) abortMultipart(w http.ResponseWriter, r *http.Request) {
+	up, id := g.upload(r)
+	if up == nil {
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "分片上传不存在")
+		return
+	}
+	g.removeUpload(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// partsReader 依次读取暂存的分片文件，同一时刻只打开一个文件。
+type partsReader struct {
+	files []string
+	cur   *os.File
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.cur == nil {
+			if len(p.files) == 0 {
+				return 0, io.EOF
+			}
+			f, err := os.Open(p.files[0])
+			if err != nil {
+				return 0, err
+			}
+			p.cur, p.files = f, p.files[1:]
+		}
+		n, err := p.cur.Read(b)
+		if err == io.EOF {
+			p.Close()
+			err = nil
+		}
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// Close 关闭当前打开的分片文件。
+func (p *partsReader) Close() {
+	if p.cur != nil {
+		_ = p.cur.Close()
+		p.cur = nil
+	}
+}
+
+func newUploadID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
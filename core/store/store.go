@@ -43,3 +43,55 @@ type UploadStateStore interface {
 	// DeleteState 删除上传状态。
 	DeleteState(localPath string) error
 }
+
+// SyncRecord 双向同步中条目上次同步完成时的状态。
+type SyncRecord struct {
+	Path       string // 相对同步根目录的路径，以 / 分隔（唯一标识）
+	IsFolder   bool   // 是否文件夹
+	Size       int64  // 文件大小
+	MD5        string // 文件 MD5（小写）
+	LocalMTime int64  // 本地修改时间（Unix 纳秒）
+	RemoteID   string // 云端文件 ID
+	RemoteRev  string // 云端修订号
+	SyncedAt   int64  // 同步完成时间戳
+}
+
+// SyncStateStore 双向同步状态库，pairID 区分不同的同步目录对。
+type SyncStateStore interface {
+	// LoadRecords 加载目录对的全部同步记录。
+	LoadRecords(pairID string) ([]SyncRecord, error)
+	// SaveRecords 写入或更新同步记录。
+	SaveRecords(pairID string, records []SyncRecord) error
+	// DeleteRecords 删除指定路径的同步记录，路径不存在时忽略。
+	DeleteRecords(pairID string, paths []string) error
+}
+
+// MetaCacheStore 文件元数据缓存接口，内容由调用方序列化，key 可直接用作文件名。
+type MetaCacheStore interface {
+	// LoadMeta 读取缓存，未命中时返回 nil, nil。
+	LoadMeta(key string) ([]byte, error)
+	// SaveMeta 写入缓存。
+	SaveMeta(key string, data []byte) error
+	// DeleteMeta 删除缓存，key 不存在时不报错。
+	DeleteMeta(key string) error
+	// ClearMeta 清空全部缓存。
+	ClearMeta() error
+}
+
+// IndexStore 离线文件索引持久化接口，内容由调用方序列化。
+type IndexStore interface {
+	// LoadIndex 读取索引，从未保存时返回 nil, nil。
+	LoadIndex() ([]byte, error)
+	// SaveIndex 覆盖保存索引。
+	SaveIndex(data []byte) error
+}
+
+// ThumbnailStore 缩略图缓存接口，key 由调用方生成且可直接用作文件名。
+type ThumbnailStore interface {
+	// LoadThumbnail 读取缓存，未命中时返回 nil, nil。
+	LoadThumbnail(key string) ([]byte, error)
+	// SaveThumbnail 写入缓存。
+	SaveThumbnail(key string, data []byte) error
+	// DeleteThumbnail 删除缓存。
+	DeleteThumbnail(key string) error
+}
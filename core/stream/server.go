@@ -0,0 +1,233 @@
+// Package stream 提供本地媒体流代理，让 VLC、mpv 等播放器直接播放云盘中的音视频。
+//
+// 代理以稳定地址 /stream/{fileID}/{name} 暴露文件，将 Range 请求转发到下载直链，
+// 直链过期时自动重新获取，播放器无需感知；/playlist/{folderID}.m3u 生成文件夹的 M3U 播放列表。
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+)
+
+// DefaultLinkTTL 下载直链的默认缓存时长，应短于服务端直链有效期。
+const DefaultLinkTTL = 10 * time.Minute
+
+// forwardHeaders 透传给上游的请求头。
+var forwardHeaders = []string{"Range", "If-Range", "If-Modified-Since", "If-None-Match"}
+
+// copyHeaders 回传给播放器的响应头。
+var copyHeaders = []string{"Content-Length", "Content-Range", "Accept-Ranges", "Last-Modified", "ETag"}
+
+// Server 媒体流代理，实现 http.Handler。
+type Server struct {
+	client  *cloud189.Client
+	baseURL string
+	linkTTL time.Duration
+	now     func() time.Time
+	mux     *http.ServeMux
+
+	mu    sync.Mutex
+	links map[string]cachedLink
+}
+
+type cachedLink struct {
+	url     string
+	expires time.Time
+}
+
+// Option 配置 Server。
+type Option func(*Server)
+
+// WithBaseURL 设置播放列表中使用的外部地址，如 "http://192.168.1.2:8189"，默认按请求 Host 生成。
+func WithBaseURL(base string) Option {
+	return func(s *Server) {
+		s.baseURL = strings.TrimSuffix(base, "/")
+	}
+}
+
+// WithLinkTTL 设置下载直链缓存时长。
+func WithLinkTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		if ttl > 0 {
+			s.linkTTL = ttl
+		}
+	}
+}
+
+// NewServer 创建媒体流代理。
+func NewServer(client *cloud189.Client, opts ...Option) *Server {
+	s := &Server{
+		client:  client,
+		linkTTL: DefaultLinkTTL,
+		now:     time.Now,
+		links:   make(map[string]cachedLink),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /stream/{id}/{name...}", s.serveStream)
+	s.mux.HandleFunc("GET /playlist/{file}", s.servePlaylist)
+	return s
+}
+
+// ServeHTTP 实现 http.Handler。
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// StreamPath 返回文件的稳定播放路径，name 仅用于播放器展示与类型识别。
+func StreamPath(fileID, name string) string {
+	return "/stream/" + url.PathEscape(fileID) + "/" + url.PathEscape(name)
+}
+
+// PlaylistPath 返回文件夹播放列表路径。
+func PlaylistPath(folderID string) string {
+	return "/playlist/" + url.PathEscape(folderID) + ".m3u"
+}
+
+// serveStream 将请求转发到下载直链，上游返回过期状态时刷新直链重试一次。
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, name := r.PathValue("id"), r.PathValue("name")
+	for attempt := 0; attempt < 2; attempt++ {
+		link, err := s.link(ctx, id, attempt > 0)
+		if err != nil {
+			http.Error(w, http.StatusText(statusOf(err)), statusOf(err))
+			return
+		}
+		req, err := http.NewRequestWithContext(ctx, r.Method, link, nil)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			return
+		}
+		for _, h := range forwardHeaders {
+			if v := r.Header.Get(h); v != "" {
+				req.Header.Set(h, v)
+			}
+		}
+		resp, err := s.client.HTTPClient().Do(req)
+		if err != nil {
+			if ctx.Err() == nil {
+				http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			}
+			return
+		}
+		if attempt == 0 && isExpiredStatus(resp.StatusCode) {
+			resp.Body.Close()
+			continue
+		}
+		s.relay(w, resp, name)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+}
+
+func (s *Server) relay(w http.ResponseWriter, resp *http.Response, name string) {
+	defer resp.Body.Close()
+	header := w.Header()
+	for _, h := range copyHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			header.Set(h, v)
+		}
+	}
+	// 直链通常返回通用类型，优先按扩展名确定，便于播放器选择解码器。
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = resp.Header.Get("Content-Type")
+	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if header.Get("Accept-Ranges") == "" {
+		header.Set("Accept-Ranges", "bytes")
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// link 返回缓存的下载直链，refresh 为 true 时强制重新获取。
+func (s *Server) link(ctx context.Context, fileID string, refresh bool) (string, error) {
+	s.mu.Lock()
+	cached, ok := s.links[fileID]
+	s.mu.Unlock()
+	if ok && !refresh && s.now().Before(cached.expires) {
+		return cached.url, nil
+	}
+	link, err := s.client.GetDownloadURL(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.links[fileID] = cachedLink{url: link, expires: s.now().Add(s.linkTTL)}
+	s.mu.Unlock()
+	return link, nil
+}
+
+// servePlaylist 输出文件夹内音视频文件的 M3U 播放列表，按文件名排序。
+func (s *Server) servePlaylist(w http.ResponseWriter, r *http.Request) {
+	folderID, ok := strings.CutSuffix(r.PathValue("file"), ".m3u")
+	if !ok || folderID == "" {
+		http.NotFound(w, r)
+		return
+	}
+	items, err := s.client.ListAllFiles(r.Context(), folderID)
+	if err != nil {
+		http.Error(w, http.StatusText(statusOf(err)), statusOf(err))
+		return
+	}
+	var media []cloud189.FileInfo
+	for _, item := range items {
+		if !item.IsFolder && isPlayable(item.MediaType) {
+			media = append(media, item)
+		}
+	}
+	sort.Slice(media, func(i, j int) bool {
+		return media[i].FileName < media[j].FileName
+	})
+
+	base := s.baseURL
+	if base == "" {
+		base = "http://" + r.Host
+	}
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, item := range media {
+		// EXTINF 标题不能包含换行。
+		title := strings.NewReplacer("\r", " ", "\n", " ").Replace(item.FileName)
+		b.WriteString("#EXTINF:-1," + title + "\n")
+		b.WriteString(base + StreamPath(item.ID.String(), item.FileName) + "\n")
+	}
+	w.Header().Set("Content-Type", "audio/x-mpegurl; charset=utf-8")
+	_, _ = io.WriteString(w, b.String())
+}
+
+func isPlayable(mediaType int) bool {
+	return mediaType == int(cloud189.MediaMusic) || mediaType == int(cloud189.MediaVideo)
+}
+
+// isExpiredStatus 判断状态码是否表示下载直链失效。
+func isExpiredStatus(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusGone
+}
+
+func statusOf(err error) int {
+	var ce *cloud189.CloudError
+	if errors.As(err, &ce) && ce.Code == cloud189.ErrCodeFileNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
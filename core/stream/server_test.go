@@ -0,0 +1,101 @@
+package stream
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dnslin/cloud189-desktop/core/internal/fakecloud"
+)
+
+// TestServer_RangeAndRenew 代理透传 Range，直链过期后自动续期。
+func TestServer_RangeAndRenew(t *testing.T) {
+	api := fakecloud.New(t)
+	id := api.AddFile(fakecloud.RootID, "movie.mp4", []byte("0123456789abcdef"))
+	srv := httptest.NewServer(NewServer(api.Client(t)))
+	t.Cleanup(srv.Close)
+
+	get := func(rng string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+StreamPath(id, "movie.mp4"), nil)
+		if rng != "" {
+			req.Header.Set("Range", rng)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+
+	resp, data := get("bytes=4-7")
+	if resp.StatusCode != http.StatusPartialContent || data != "4567" || resp.Header.Get("Content-Range") != "bytes 4-7/16" {
+		t.Fatalf("范围请求异常: %d %q %v", resp.StatusCode, data, resp.Header)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "video/mp4" {
+		t.Fatalf("应按扩展名补全类型，实际 %q", ct)
+	}
+	if _, data := get("bytes=10-"); data != "abcdef" {
+		t.Fatalf("缓存直链应直接复用: %q", data)
+	}
+	if hits := api.Hits("/getFileDownloadUrl.action"); hits != 1 {
+		t.Fatalf("直链未过期时不应重复获取，实际 %d 次", hits)
+	}
+
+	api.ExpireDownloadURLs()
+	resp, data = get("")
+	if resp.StatusCode != http.StatusOK || data != "0123456789abcdef" {
+		t.Fatalf("过期后应透明续期: %d %q", resp.StatusCode, data)
+	}
+	if hits := api.Hits("/getFileDownloadUrl.action"); hits != 2 {
+		t.Fatalf("应重新获取一次直链，实际 %d 次", hits)
+	}
+	if resp, _ := get("bytes=0-1"); resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("续期后的直链应被缓存复用: %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+StreamPath("404", "x.mp4"), nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("不存在的文件应返回 404: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+}
+
+// TestServer_Playlist 播放列表只包含音视频文件并指向代理地址。
+func TestServer_Playlist(t *testing.T) {
+	api := fakecloud.New(t)
+	folder := api.AddFolder(fakecloud.RootID, "Media")
+	song := api.AddFile(folder, "b song.mp3", []byte("mp3"))
+	movie := api.AddFile(folder, "a movie.mkv", []byte("mkv"))
+	api.AddFile(folder, "cover.jpg", []byte("jpg"))
+	api.AddFile(folder, "notes.txt", []byte("txt"))
+	api.AddFolder(folder, "Extras")
+	srv := httptest.NewServer(NewServer(api.Client(t), WithBaseURL("http://media.local:8189/")))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + PlaylistPath(folder))
+	if err != nil {
+		t.Fatalf("请求播放列表失败: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	want := strings.Join([]string{
+		"#EXTM3U",
+		"#EXTINF:-1,a movie.mkv",
+		"http://media.local:8189/stream/" + movie + "/a%20movie.mkv",
+		"#EXTINF:-1,b song.mp3",
+		"http://media.local:8189/stream/" + song + "/b%20song.mp3",
+		"",
+	}, "\n")
+	if string(data) != want {
+		t.Fatalf("播放列表不符:\n%s\n期望:\n%s", data, want)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "audio/x-mpegurl") {
+		t.Fatalf("Content-Type 异常: %q", ct)
+	}
+}
@@ -84,8 +84,10 @@ func (m *Manager) runDownload(task *Task, cfg DownloadConfig, downloader Downloa
 		m.notifyProgress(task)
 		return
 	}
+	task.mu.Lock()
 	task.FileName = fileName
 	task.Total = fileSize
+	task.mu.Unlock()
 
 	// 获取下载链接
 	downloadURL, err := downloader.GetDownloadURL(ctx, cfg.FileID)
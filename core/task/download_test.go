@@ -0,0 +1,72 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeDownloader struct {
+	url  string
+	size int64
+}
+
+func (d *fakeDownloader) GetDownloadURL(ctx context.Context, fileID string) (string, error) {
+	return d.url, nil
+}
+
+func (d *fakeDownloader) GetFileInfo(ctx context.Context, fileID string) (string, int64, error) {
+	return "data.bin", d.size, nil
+}
+
+func (d *fakeDownloader) HTTPClient() *http.Client { return nil }
+
+func (d *fakeDownloader) Mode() DownloadMode { return DownloadModeApp }
+
+type memoryWriter struct {
+	bytes.Buffer
+}
+
+func (w *memoryWriter) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd {
+		return int64(w.Len()), nil
+	}
+	return offset, nil
+}
+
+func (w *memoryWriter) Close() error { return nil }
+
+// TestManager_DownloadConcurrentGetTask 下载过程中并发读取任务快照不应产生数据竞争（配合 -race）。
+func TestManager_DownloadConcurrentGetTask(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 256*1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(payload)
+	}))
+	defer srv.Close()
+
+	m := NewManager()
+	writer := &memoryWriter{}
+	id, err := m.AddDownload(DownloadConfig{FileID: "f1", LocalPath: "data.bin"}, &fakeDownloader{url: srv.URL, size: int64(len(payload))}, writer)
+	if err != nil {
+		t.Fatalf("添加下载任务失败: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			task, err := m.GetTask(id)
+			if err != nil || task.Status == TaskStatusCompleted || task.Status == TaskStatusFailed {
+				return
+			}
+		}
+	}()
+	task := waitStatus(t, m, id, TaskStatusCompleted)
+	<-done
+	if task.FileName != "data.bin" || task.Total != int64(len(payload)) {
+		t.Fatalf("任务文件信息不符: name=%s total=%d", task.FileName, task.Total)
+	}
+}
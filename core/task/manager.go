@@ -20,7 +20,8 @@ var (
 type Manager struct {
 	mu        sync.RWMutex
 	tasks     map[string]*Task              // 任务映射
-	callbacks []ProgressCallback            // 进度回调列表
+	callbacks []progressSubscriber          // 进度回调列表
+	nextSub   uint64                        // 下一个订阅 ID
 	cancels   map[string]context.CancelFunc // 任务取消函数
 
 	maxConcurrent    int                    // 最大并发数
@@ -51,7 +52,7 @@ func WithUploadStateStore(s store.UploadStateStore) ManagerOption {
 func NewManager(opts ...ManagerOption) *Manager {
 	m := &Manager{
 		tasks:         make(map[string]*Task),
-		callbacks:     make([]ProgressCallback, 0),
+		callbacks:     make([]progressSubscriber, 0),
 		cancels:       make(map[string]context.CancelFunc),
 		maxConcurrent: 3, // 默认最大并发数
 	}
@@ -192,23 +193,41 @@ func (m *Manager) Resume(taskID string) error {
 	return nil
 }
 
-// Subscribe 订阅进度更新。
-func (m *Manager) Subscribe(callback ProgressCallback) {
+// progressSubscriber 一个进度订阅。
+type progressSubscriber struct {
+	id       uint64
+	callback ProgressCallback
+}
+
+// Subscribe 订阅进度更新，返回的函数用于取消订阅（可重复调用）。
+func (m *Manager) Subscribe(callback ProgressCallback) (unsubscribe func()) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.callbacks = append(m.callbacks, callback)
+	m.nextSub++
+	id := m.nextSub
+	m.callbacks = append(m.callbacks, progressSubscriber{id: id, callback: callback})
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, sub := range m.callbacks {
+			if sub.id == id {
+				m.callbacks = append(m.callbacks[:i:i], m.callbacks[i+1:]...)
+				return
+			}
+		}
+	}
 }
 
 // notifyProgress 通知进度更新。
 func (m *Manager) notifyProgress(task *Task) {
 	m.mu.RLock()
-	callbacks := make([]ProgressCallback, len(m.callbacks))
-	copy(callbacks, m.callbacks)
+	subs := make([]progressSubscriber, len(m.callbacks))
+	copy(subs, m.callbacks)
 	m.mu.RUnlock()
 
 	clone := task.Clone()
-	for _, cb := range callbacks {
-		cb(clone)
+	for _, sub := range subs {
+		sub.callback(clone)
 	}
 }
 
@@ -95,3 +95,20 @@ func mustStatus(t *testing.T, m *Manager, id string) TaskStatus {
 	}
 	return task.GetStatus()
 }
+
+// TestManager_Unsubscribe 取消订阅后不再收到进度通知，其他订阅者不受影响。
+func TestManager_Unsubscribe(t *testing.T) {
+	m := NewManager()
+	var first, second int
+	unsubscribe := m.Subscribe(func(*Task) { first++ })
+	m.Subscribe(func(*Task) { second++ })
+	task := m.CreateTask(TaskTypeDownload)
+
+	m.notifyProgress(task)
+	unsubscribe()
+	unsubscribe()
+	m.notifyProgress(task)
+	if first != 1 || second != 2 {
+		t.Fatalf("通知次数异常: first=%d second=%d", first, second)
+	}
+}
@@ -0,0 +1,176 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+)
+
+// DefaultOfflinePollInterval 离线任务默认状态轮询间隔。
+const DefaultOfflinePollInterval = 3 * time.Second
+
+// OfflineFetcher 离线下载接口，*cloud189.Client 可直接满足。
+type OfflineFetcher interface {
+	// AddOfflineTask 提交离线下载，返回云端任务 ID。
+	AddOfflineTask(ctx context.Context, rawURL, folderID string) (string, error)
+	// GetOfflineTask 查询云端任务状态。
+	GetOfflineTask(ctx context.Context, taskID string) (*cloud189.OfflineTask, error)
+	// CancelOfflineTasks 取消云端任务。
+	CancelOfflineTasks(ctx context.Context, taskIDs []string) error
+}
+
+// OfflineConfig 离线下载配置。
+type OfflineConfig struct {
+	URL          string        // 下载源链接（http/https/magnet/ed2k）
+	ParentID     string        // 云端保存目录 ID
+	PollInterval time.Duration // 状态轮询间隔，<= 0 时使用默认值
+}
+
+// OfflineError 云端离线下载失败。
+type OfflineError struct {
+	Message string
+}
+
+func (e *OfflineError) Error() string {
+	if e.Message == "" {
+		return "离线下载失败"
+	}
+	return "离线下载失败: " + e.Message
+}
+
+// AddOffline 添加离线下载任务。
+// 下载在服务端进行，不占用本地并发名额；任务在管理器中取消时同步取消云端任务。
+func (m *Manager) AddOffline(cfg OfflineConfig, fetcher OfflineFetcher) (string, error) {
+	if err := cloud189.ValidateOfflineURL(cfg.URL); err != nil {
+		return "", err
+	}
+	task := m.CreateTask(TaskTypeOffline)
+	task.SourceURL = cfg.URL
+	task.ParentID = cfg.ParentID
+
+	go m.runOffline(task, cfg, fetcher)
+	return task.ID, nil
+}
+
+// runOffline 提交云端任务并轮询进度直至终态。
+func (m *Manager) runOffline(task *Task, cfg OfflineConfig, fetcher OfflineFetcher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.registerCancel(task.ID, cancel)
+	defer m.unregisterCancel(task.ID)
+
+	if task.GetStatus() == TaskStatusCanceled {
+		return
+	}
+
+	remoteID, err := fetcher.AddOfflineTask(ctx, cfg.URL, cfg.ParentID)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			task.SetError(err)
+			m.notifyProgress(task)
+		}
+		return
+	}
+	// 提交期间可能已被取消：Cancel 先取消 ctx 再置状态，持锁检查两者避免覆盖取消。
+	task.mu.Lock()
+	task.RemoteID = remoteID
+	canceled := task.Status == TaskStatusCanceled || ctx.Err() != nil
+	if !canceled && task.Status == TaskStatusPending {
+		task.Status = TaskStatusRunning
+		task.UpdatedAt = time.Now()
+	}
+	task.mu.Unlock()
+	if canceled {
+		m.cancelRemoteOffline(fetcher, remoteID)
+		return
+	}
+	m.notifyProgress(task)
+
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = DefaultOfflinePollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			m.cancelRemoteOffline(fetcher, remoteID)
+			return
+		}
+		// 暂停仅停止刷新进度，云端下载不受影响
+		if task.GetStatus() != TaskStatusPaused {
+			info, err := fetcher.GetOfflineTask(ctx, remoteID)
+			if err != nil {
+				if ctx.Err() != nil {
+					m.cancelRemoteOffline(fetcher, remoteID)
+					return
+				}
+				task.SetError(err)
+				m.notifyProgress(task)
+				return
+			}
+			if m.applyOfflineStatus(task, info) {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			m.cancelRemoteOffline(fetcher, remoteID)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyOfflineStatus 同步云端状态到任务，返回任务是否结束。
+func (m *Manager) applyOfflineStatus(task *Task, info *cloud189.OfflineTask) bool {
+	task.mu.Lock()
+	if info.FileName != "" {
+		task.FileName = info.FileName
+	}
+	if info.FileID != "" {
+		task.FileID = info.FileID.String()
+	}
+	if info.TotalSize > 0 {
+		task.Total = info.TotalSize
+	}
+	task.mu.Unlock()
+
+	switch info.Status {
+	case cloud189.OfflineStatusCompleted:
+		total := info.TotalSize
+		if total <= 0 {
+			total = info.Downloaded
+		}
+		task.SetProgress(total)
+		task.SetStatus(TaskStatusCompleted)
+	case cloud189.OfflineStatusFailed:
+		task.SetError(&OfflineError{Message: info.ErrMsg})
+	case cloud189.OfflineStatusCanceled:
+		task.SetStatus(TaskStatusCanceled)
+	default:
+		switch task.GetStatus() {
+		case TaskStatusCanceled:
+			// 本地已取消，由轮询循环通知云端
+			return false
+		case TaskStatusPending:
+			// 暂停后恢复
+			task.SetStatus(TaskStatusRunning)
+		}
+		task.SetProgress(info.Downloaded)
+		m.notifyProgress(task)
+		return false
+	}
+	m.notifyProgress(task)
+	return true
+}
+
+// cancelRemoteOffline 本地取消后通知服务端停止下载。
+func (m *Manager) cancelRemoteOffline(fetcher OfflineFetcher, remoteID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = fetcher.CancelOfflineTasks(ctx, []string{remoteID})
+}
@@ -0,0 +1,164 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+)
+
+type fakeOfflineFetcher struct {
+	mu       sync.Mutex
+	polls    int
+	finishAt int // 第几次查询时完成，0 表示永不完成
+	canceled []string
+
+	// 非空时提交在 added 关闭后阻塞到 release 关闭，模拟云端已创建任务但响应尚未返回。
+	added, release chan struct{}
+}
+
+func (f *fakeOfflineFetcher) AddOfflineTask(ctx context.Context, rawURL, folderID string) (string, error) {
+	if f.release != nil {
+		close(f.added)
+		<-f.release
+	}
+	return "remote-1", nil
+}
+
+func (f *fakeOfflineFetcher) GetOfflineTask(ctx context.Context, taskID string) (*cloud189.OfflineTask, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.polls++
+	info := &cloud189.OfflineTask{
+		TaskID:     cloud189.FlexString(taskID),
+		FileName:   "movie.mkv",
+		TotalSize:  100,
+		Downloaded: int64(f.polls * 10),
+		Status:     cloud189.OfflineStatusDownloading,
+	}
+	if f.finishAt > 0 && f.polls >= f.finishAt {
+		info.Status = cloud189.OfflineStatusCompleted
+		info.FileID = "file-9"
+	}
+	return info, nil
+}
+
+func (f *fakeOfflineFetcher) CancelOfflineTasks(ctx context.Context, taskIDs []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.canceled = append(f.canceled, taskIDs...)
+	return nil
+}
+
+func (f *fakeOfflineFetcher) canceledIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.canceled...)
+}
+
+func waitStatus(t *testing.T, m *Manager, taskID string, want TaskStatus) *Task {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		task, err := m.GetTask(taskID)
+		if err != nil {
+			t.Fatalf("获取任务失败: %v", err)
+		}
+		if task.Status == want {
+			return task
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("等待任务进入 %s 超时", want)
+	return nil
+}
+
+// TestManager_OfflineCompletes 离线任务应轮询云端进度直至完成。
+func TestManager_OfflineCompletes(t *testing.T) {
+	m := NewManager()
+	fetcher := &fakeOfflineFetcher{finishAt: 3}
+	id, err := m.AddOffline(OfflineConfig{
+		URL:          "magnet:?xt=urn:btih:abcdef",
+		ParentID:     "-11",
+		PollInterval: time.Millisecond,
+	}, fetcher)
+	if err != nil {
+		t.Fatalf("添加离线任务失败: %v", err)
+	}
+	task := waitStatus(t, m, id, TaskStatusCompleted)
+	if task.Type != TaskTypeOffline || task.RemoteID != "remote-1" {
+		t.Fatalf("任务信息不符: type=%s remote=%s", task.Type, task.RemoteID)
+	}
+	if task.FileID != "file-9" || task.FileName != "movie.mkv" || task.Progress != 100 {
+		t.Fatalf("完成后文件信息不符: %+v", task)
+	}
+}
+
+// TestManager_OfflineCancel 本地取消应同步取消云端任务。
+func TestManager_OfflineCancel(t *testing.T) {
+	m := NewManager()
+	fetcher := &fakeOfflineFetcher{}
+	id, err := m.AddOffline(OfflineConfig{
+		URL:          "https://example.com/a.iso",
+		ParentID:     "-11",
+		PollInterval: time.Millisecond,
+	}, fetcher)
+	if err != nil {
+		t.Fatalf("添加离线任务失败: %v", err)
+	}
+	waitStatus(t, m, id, TaskStatusRunning)
+	if err := m.Cancel(id); err != nil {
+		t.Fatalf("取消失败: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(fetcher.canceledIDs()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := fetcher.canceledIDs(); len(got) != 1 || got[0] != "remote-1" {
+		t.Fatalf("应取消云端任务 remote-1，实际 %v", got)
+	}
+	if _, err := m.AddOffline(OfflineConfig{URL: "ftp://x"}, fetcher); err == nil {
+		t.Fatalf("不支持的链接应返回错误")
+	}
+}
+
+// TestManager_OfflineCancelDuringSubmit 提交期间取消不应被覆盖为运行中，已创建的云端任务需一并取消。
+func TestManager_OfflineCancelDuringSubmit(t *testing.T) {
+	m := NewManager()
+	fetcher := &fakeOfflineFetcher{added: make(chan struct{}), release: make(chan struct{})}
+	id, err := m.AddOffline(OfflineConfig{URL: "https://example.com/a.iso", PollInterval: time.Millisecond}, fetcher)
+	if err != nil {
+		t.Fatalf("添加离线任务失败: %v", err)
+	}
+	<-fetcher.added
+	if err := m.Cancel(id); err != nil {
+		t.Fatalf("取消失败: %v", err)
+	}
+	close(fetcher.release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(fetcher.canceledIDs()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := fetcher.canceledIDs(); len(got) != 1 || got[0] != "remote-1" {
+		t.Fatalf("应取消已创建的云端任务，实际 %v", got)
+	}
+	task, err := m.GetTask(id)
+	if err != nil {
+		t.Fatalf("获取任务失败: %v", err)
+	}
+	if task.Status != TaskStatusCanceled {
+		t.Fatalf("任务应保持已取消，实际 %s", task.Status)
+	}
+	if task.RemoteID != "remote-1" {
+		t.Fatalf("应记录云端任务 ID，实际 %q", task.RemoteID)
+	}
+	fetcher.mu.Lock()
+	polls := fetcher.polls
+	fetcher.mu.Unlock()
+	if polls != 0 {
+		t.Fatalf("已取消的任务不应继续轮询，实际 %d 次", polls)
+	}
+}
@@ -14,6 +14,8 @@ const (
 	TaskTypeDownload TaskType = iota
 	// TaskTypeUpload 上传任务。
 	TaskTypeUpload
+	// TaskTypeOffline 云端离线下载任务。
+	TaskTypeOffline
 )
 
 // String 返回任务类型的字符串表示。
@@ -23,6 +25,8 @@ func (t TaskType) String() string {
 		return "download"
 	case TaskTypeUpload:
 		return "upload"
+	case TaskTypeOffline:
+		return "offline"
 	default:
 		return "unknown"
 	}
@@ -66,7 +70,7 @@ func (s TaskStatus) String() string {
 	}
 }
 
-// Task 表示一个上传或下载任务。
+// Task 表示一个上传、下载或离线下载任务。
 type Task struct {
 	mu sync.RWMutex
 
@@ -86,7 +90,9 @@ type Task struct {
 	FileID    string // 云端文件 ID（下载时使用）
 	FileName  string // 文件名
 	LocalPath string // 本地路径
-	ParentID  string // 云端父目录 ID（上传时使用）
+	ParentID  string // 云端父目录 ID（上传、离线下载时使用）
+	SourceURL string // 离线下载源链接
+	RemoteID  string // 云端任务 ID（离线下载时使用）
 
 	// 错误信息
 	Error error // 任务错误
@@ -198,6 +204,8 @@ func (t *Task) Clone() *Task {
 		FileName:  t.FileName,
 		LocalPath: t.LocalPath,
 		ParentID:  t.ParentID,
+		SourceURL: t.SourceURL,
+		RemoteID:  t.RemoteID,
 		Error:     t.Error,
 	}
 }
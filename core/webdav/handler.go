@@ -0,0 +1,443 @@
+// Package webdav 基于 drive.Drive 提供 WebDAV（RFC 4918）服务，便于在文件管理器与办公软件中挂载云盘。
+//
+// 支持 OPTIONS、PROPFIND、PROPPATCH、GET/HEAD（含 Range）、PUT、MKCOL、MOVE、COPY、DELETE，
+// LOCK/UNLOCK 仅为兼容客户端的桩实现，不提供真实的锁语义。
+package webdav
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/drive"
+	coreerrors "github.com/dnslin/cloud189-desktop/core/errors"
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+// Handler WebDAV 请求处理器。
+type Handler struct {
+	drive    drive.Drive
+	prefix   string
+	username string
+	password string
+	realm    string
+}
+
+// Option 配置 Handler。
+type Option func(*Handler)
+
+// WithPrefix 设置挂载前缀，如 "/dav"，请求路径去掉前缀后映射到云盘根目录。
+func WithPrefix(prefix string) Option {
+	return func(h *Handler) {
+		h.prefix = strings.TrimSuffix(path.Clean("/"+prefix), "/")
+	}
+}
+
+// WithBasicAuth 开启 Basic 认证，用户名为空时不校验。
+func WithBasicAuth(username, password string) Option {
+	return func(h *Handler) {
+		h.username = username
+		h.password = password
+	}
+}
+
+// WithRealm 设置 Basic 认证的 realm，默认 "cloud189"。
+func WithRealm(realm string) Option {
+	return func(h *Handler) {
+		if realm != "" {
+			h.realm = realm
+		}
+	}
+}
+
+// NewHandler 创建 WebDAV 处理器。
+func NewHandler(d drive.Drive, opts ...Option) *Handler {
+	h := &Handler{drive: d, realm: "cloud189"}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+	return h
+}
+
+// ServeHTTP 实现 http.Handler。
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="`+h.realm+`"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	name, ok := h.stripPrefix(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var status int
+	var err error
+	switch r.Method {
+	case http.MethodOptions:
+		status, err = h.handleOptions(w, r, name)
+	case http.MethodGet, http.MethodHead:
+		status, err = h.handleGet(w, r, name)
+	case http.MethodPut:
+		status, err = h.handlePut(w, r, name)
+	case http.MethodDelete:
+		status, err = h.handleDelete(w, r, name)
+	case "MKCOL":
+		status, err = h.handleMkcol(w, r, name)
+	case "MOVE", "COPY":
+		status, err = h.handleTransfer(w, r, name)
+	case "PROPFIND":
+		status, err = h.handlePropfind(w, r, name)
+	case "PROPPATCH":
+		status, err = h.handleProppatch(w, r, name)
+	case "LOCK":
+		status, err = h.handleLock(w, r, name)
+	case "UNLOCK":
+		status = http.StatusNoContent
+	default:
+		status = http.StatusMethodNotAllowed
+	}
+	if status == 0 {
+		return
+	}
+	if err != nil && status == http.StatusInternalServerError {
+		status = statusOf(err)
+	}
+	if status >= http.StatusBadRequest {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	w.WriteHeader(status)
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.username == "" {
+		return true
+	}
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(h.username)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(h.password)) == 1
+	return userOK && passOK
+}
+
+// stripPrefix 去掉挂载前缀并返回云盘绝对路径。
+func (h *Handler) stripPrefix(p string) (string, bool) {
+	if h.prefix == "" {
+		return path.Clean("/" + p), true
+	}
+	if p != h.prefix && !strings.HasPrefix(p, h.prefix+"/") {
+		return "", false
+	}
+	return path.Clean("/" + strings.TrimPrefix(p, h.prefix)), true
+}
+
+// href 返回资源在响应中的转义地址，文件夹以 / 结尾。
+func (h *Handler) href(name string, folder bool) string {
+	p := h.prefix + name
+	if folder && !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+func (h *Handler) handleOptions(w http.ResponseWriter, r *http.Request, name string) (int, error) {
+	allow := "OPTIONS, PROPFIND, PROPPATCH, LOCK, UNLOCK, DELETE, MOVE, COPY"
+	if file, err := h.drive.Stat(r.Context(), name); err == nil {
+		if file.IsFolder {
+			allow += ", MKCOL"
+		} else {
+			allow += ", GET, HEAD, PUT"
+		}
+	} else {
+		allow = "OPTIONS, PUT, MKCOL, LOCK"
+	}
+	w.Header().Set("Allow", allow)
+	w.Header().Set("DAV", "1, 2")
+	w.Header().Set("MS-Author-Via", "DAV")
+	return http.StatusOK, nil
+}
+
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request, name string) (int, error) {
+	ctx := r.Context()
+	file, err := h.drive.Stat(ctx, name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if file.IsFolder {
+		return http.StatusMethodNotAllowed, nil
+	}
+	w.Header().Set("ETag", etag(file))
+	w.Header().Set("Content-Type", contentType(file))
+	if ra, ok := h.drive.(drive.RandomAccessDrive); ok {
+		reader, err := ra.OpenRandom(ctx, name)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		defer reader.Close()
+		http.ServeContent(w, r, file.Name, file.UpdatedAt, reader)
+		return 0, nil
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	w.Header().Set("Last-Modified", file.UpdatedAt.UTC().Format(http.TimeFormat))
+	if r.Method == http.MethodHead {
+		return http.StatusOK, nil
+	}
+	body, err := h.drive.Open(ctx, name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	defer body.Close()
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, body)
+	return 0, nil
+}
+
+func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request, name string) (int, error) {
+	ctx := r.Context()
+	if name == "/" {
+		return http.StatusMethodNotAllowed, nil
+	}
+	if status, err := h.requireParent(ctx, name); status != 0 {
+		return status, err
+	}
+	existing, err := h.drive.Stat(ctx, name)
+	switch {
+	case err == nil && existing.IsFolder:
+		return http.StatusMethodNotAllowed, nil
+	case err != nil && !errors.Is(err, drive.ErrNotFound):
+		return http.StatusInternalServerError, err
+	}
+	size := r.ContentLength
+	file, err := h.drive.Create(ctx, name, r.Body, size)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	w.Header().Set("ETag", etag(file))
+	if existing.ID != "" {
+		return http.StatusNoContent, nil
+	}
+	return http.StatusCreated, nil
+}
+
+func (h *Handler) handleDelete(_ http.ResponseWriter, r *http.Request, name string) (int, error) {
+	if name == "/" {
+		return http.StatusForbidden, nil
+	}
+	if err := h.drive.Remove(r.Context(), name); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+func (h *Handler) handleMkcol(_ http.ResponseWriter, r *http.Request, name string) (int, error) {
+	ctx := r.Context()
+	if r.ContentLength > 0 {
+		return http.StatusUnsupportedMediaType, nil
+	}
+	if _, err := h.drive.Stat(ctx, name); err == nil {
+		return http.StatusMethodNotAllowed, nil
+	} else if !errors.Is(err, drive.ErrNotFound) {
+		return http.StatusInternalServerError, err
+	}
+	if status, err := h.requireParent(ctx, name); status != 0 {
+		return status, err
+	}
+	if _, err := h.drive.Mkdir(ctx, name); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusCreated, nil
+}
+
+// handleTransfer 处理 MOVE/COPY，按 Destination 与 Overwrite 头决定目标。
+func (h *Handler) handleTransfer(_ http.ResponseWriter, r *http.Request, name string) (int, error) {
+	ctx := r.Context()
+	dest, status := h.destination(r)
+	if status != 0 {
+		return status, nil
+	}
+	if name == "/" || dest == "/" || dest == name {
+		return http.StatusForbidden, nil
+	}
+	src, err := h.drive.Stat(ctx, name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if status, err := h.requireParent(ctx, dest); status != 0 {
+		return status, err
+	}
+	if src.IsFolder && strings.HasPrefix(dest, name+"/") {
+		return http.StatusForbidden, nil
+	}
+	// 目标是源的上级目录时覆盖会连同源一起删除。
+	if strings.HasPrefix(name, dest+"/") {
+		return http.StatusForbidden, nil
+	}
+	var backup string
+	if _, err := h.drive.Stat(ctx, dest); err == nil {
+		if r.Header.Get("Overwrite") == "F" {
+			return http.StatusPreconditionFailed, nil
+		}
+		// 原目标先改名让位，转移成功后再删除，失败时恢复原名。
+		destName := path.Base(dest)
+		moved, err := h.drive.Rename(ctx, dest, "."+destName+".overwrite-"+strconv.FormatInt(time.Now().UnixNano(), 36))
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		backup = moved.Path
+	} else if !errors.Is(err, drive.ErrNotFound) {
+		return http.StatusInternalServerError, err
+	}
+
+	if r.Method == "MOVE" {
+		err = h.move(ctx, src, dest)
+	} else {
+		err = h.copy(ctx, src, dest, r.Header.Get("Depth"))
+	}
+	if err != nil {
+		if backup != "" {
+			if _, restoreErr := h.drive.Rename(ctx, backup, path.Base(dest)); restoreErr != nil {
+				err = errors.Join(err, restoreErr)
+			}
+		}
+		return http.StatusInternalServerError, err
+	}
+	if backup == "" {
+		return http.StatusCreated, nil
+	}
+	if err := h.drive.Remove(ctx, backup); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+func (h *Handler) move(ctx context.Context, src model.File, dest string) error {
+	destDir, destName := path.Split(dest)
+	destDir = path.Clean(destDir)
+	if destDir == src.ParentPath {
+		_, err := h.drive.Rename(ctx, src.Path, destName)
+		return err
+	}
+	moved, err := h.drive.Move(ctx, src.Path, destDir)
+	if err != nil || moved.Name == destName {
+		return err
+	}
+	_, err = h.drive.Rename(ctx, moved.Path, destName)
+	return err
+}
+
+func (h *Handler) copy(ctx context.Context, src model.File, dest, depth string) error {
+	destDir, destName := path.Split(dest)
+	destDir = path.Clean(destDir)
+	if src.IsFolder && depth == "0" {
+		_, err := h.drive.Mkdir(ctx, dest)
+		return err
+	}
+	if destDir == src.ParentPath {
+		// 云端不支持同目录复制，文件改为下载后重新上传。
+		if src.IsFolder {
+			return coreerrors.New(coreerrors.ErrCodeInvalidArgument, "webdav: 不支持在同一目录内复制文件夹")
+		}
+		body, err := h.drive.Open(ctx, src.Path)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		_, err = h.drive.Create(ctx, dest, body, src.Size)
+		return err
+	}
+	copied, err := h.drive.Copy(ctx, src.Path, destDir)
+	if err != nil || copied.Name == destName {
+		return err
+	}
+	_, err = h.drive.Rename(ctx, copied.Path, destName)
+	return err
+}
+
+// destination 解析 Destination 头，支持绝对 URL 与绝对路径；指向其他主机时返回 502。
+func (h *Handler) destination(r *http.Request) (string, int) {
+	raw := r.Header.Get("Destination")
+	if raw == "" {
+		return "", http.StatusBadRequest
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", http.StatusBadRequest
+	}
+	if u.Host != "" && u.Host != r.Host {
+		return "", http.StatusBadGateway
+	}
+	dest, ok := h.stripPrefix(u.Path)
+	if !ok {
+		return "", http.StatusBadGateway
+	}
+	return dest, 0
+}
+
+// requireParent 确认上级目录存在，不存在时返回 409。
+func (h *Handler) requireParent(ctx context.Context, name string) (int, error) {
+	parent, err := h.drive.Stat(ctx, path.Dir(name))
+	switch {
+	case err == nil && parent.IsFolder:
+		return 0, nil
+	case err == nil, errors.Is(err, drive.ErrNotFound):
+		return http.StatusConflict, nil
+	default:
+		return http.StatusInternalServerError, err
+	}
+}
+
+// statusOf 将 Drive 错误映射为 HTTP 状态码。
+func statusOf(err error) int {
+	var ce *coreerrors.CoreError
+	switch {
+	case errors.Is(err, drive.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, drive.ErrExist):
+		return http.StatusConflict
+	case errors.Is(err, drive.ErrNotDir):
+		return http.StatusConflict
+	case errors.Is(err, drive.ErrIsDir):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &ce) && ce.Code == coreerrors.ErrCodeInvalidArgument:
+		return http.StatusForbidden
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func etag(file model.File) string {
+	if file.MD5 != "" {
+		return `"` + strings.ToLower(file.MD5) + `"`
+	}
+	return `"` + file.ID + "-" + file.Revision + `"`
+}
+
+func contentType(file model.File) string {
+	if ct := mime.TypeByExtension(path.Ext(file.Name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// httpDate 以 RFC 1123 格式输出时间，零值返回空串。
+func httpDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(http.TimeFormat)
+}
@@ -0,0 +1,194 @@
+package webdav
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dnslin/cloud189-desktop/core/cloud189"
+	"github.com/dnslin/cloud189-desktop/core/drive"
+	"github.com/dnslin/cloud189-desktop/core/internal/fakecloud"
+)
+
+type davEnv struct {
+	t    *testing.T
+	api  *fakecloud.Server
+	base string
+}
+
+func newDavEnv(t *testing.T, opts ...Option) *davEnv {
+	t.Helper()
+	api := fakecloud.New(t)
+	docs := api.AddFolder(fakecloud.RootID, "Documents")
+	api.AddFile(docs, "report.txt", []byte("0123456789"))
+	d := drive.NewCloud189Drive(api.Client(t), drive.WithReaderOptions(cloud189.WithReaderBlockSize(4)))
+	srv := httptest.NewServer(NewHandler(d, append([]Option{WithPrefix("/dav")}, opts...)...))
+	t.Cleanup(srv.Close)
+	return &davEnv{t: t, api: api, base: srv.URL + "/dav"}
+}
+
+func (e *davEnv) do(method, p string, body string, headers map[string]string) (*http.Response, string) {
+	e.t.Helper()
+	req, err := http.NewRequest(method, e.base+p, strings.NewReader(body))
+	if err != nil {
+		e.t.Fatalf("构建请求失败: %v", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		e.t.Fatalf("%s %s 失败: %v", method, p, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, string(data)
+}
+
+func (e *davEnv) expect(method, p string, want int, body string, headers map[string]string) string {
+	e.t.Helper()
+	resp, data := e.do(method, p, body, headers)
+	if resp.StatusCode != want {
+		e.t.Fatalf("%s %s 状态码=%d，期望 %d: %s", method, p, resp.StatusCode, want, data)
+	}
+	return data
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				DisplayName   string    `xml:"displayname"`
+				ContentLength string    `xml:"getcontentlength"`
+				Collection    *struct{} `xml:"resourcetype>collection"`
+			} `xml:"prop"`
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// TestHandler_EndToEnd 通过 WebDAV 完成上传、列目录、范围下载、移动、复制与删除。
+func TestHandler_EndToEnd(t *testing.T) {
+	e := newDavEnv(t)
+
+	e.expect("MKCOL", "/Projects", http.StatusCreated, "", nil)
+	e.expect("MKCOL", "/Projects", http.StatusMethodNotAllowed, "", nil)
+	e.expect("MKCOL", "/missing/child", http.StatusConflict, "", nil)
+	e.expect(http.MethodPut, "/Projects/a%20b.txt", http.StatusCreated, "hello webdav", nil)
+	e.expect(http.MethodPut, "/Projects/a%20b.txt", http.StatusNoContent, "hello again", nil)
+	if node, ok := e.api.Lookup("/Projects/a b.txt"); !ok || string(node.Data) != "hello again" {
+		t.Fatalf("上传内容未写入云端: %+v", node)
+	}
+	if e.api.Hits("/person/commitMultiUploadFile") != 2 {
+		t.Fatalf("上传应走分片提交流程")
+	}
+
+	body := e.expect("PROPFIND", "/Projects/", http.StatusMultiStatus, "", map[string]string{"Depth": "1"})
+	var ms multistatus
+	if err := xml.Unmarshal([]byte(body), &ms); err != nil {
+		t.Fatalf("解析 PROPFIND 响应失败: %v", err)
+	}
+	if len(ms.Responses) != 2 || ms.Responses[0].Href != "/dav/Projects/" || ms.Responses[0].Propstat[0].Prop.Collection == nil {
+		t.Fatalf("目录响应异常: %s", body)
+	}
+	if child := ms.Responses[1]; child.Href != "/dav/Projects/a%20b.txt" || child.Propstat[0].Prop.ContentLength != "11" {
+		t.Fatalf("子项响应异常: %s", body)
+	}
+	body = e.expect("PROPFIND", "/Documents/report.txt", http.StatusMultiStatus,
+		`<?xml version="1.0"?><D:propfind xmlns:D="DAV:"><D:prop><D:getcontentlength/><x:color xmlns:x="urn:x"/></D:prop></D:propfind>`,
+		map[string]string{"Depth": "0"})
+	if !strings.Contains(body, "<D:getcontentlength>10</D:getcontentlength>") || !strings.Contains(body, "404 Not Found") {
+		t.Fatalf("指定属性查询应区分 200/404: %s", body)
+	}
+	e.expect("PROPFIND", "/", http.StatusForbidden, "", map[string]string{"Depth": "infinity"})
+
+	resp, data := e.do(http.MethodGet, "/Documents/report.txt", "", map[string]string{"Range": "bytes=3-8"})
+	if resp.StatusCode != http.StatusPartialContent || data != "345678" {
+		t.Fatalf("范围下载异常: %d %q", resp.StatusCode, data)
+	}
+	if data := e.expect(http.MethodGet, "/Documents/report.txt", http.StatusOK, "", nil); data != "0123456789" {
+		t.Fatalf("完整下载内容不符: %q", data)
+	}
+
+	dest := map[string]string{"Destination": e.base + "/Documents/moved.txt"}
+	e.expect("MOVE", "/Projects/a%20b.txt", http.StatusCreated, "", dest)
+	if _, ok := e.api.Lookup("/Documents/moved.txt"); !ok {
+		t.Fatalf("跨目录改名移动失败")
+	}
+	e.expect("COPY", "/Documents/report.txt", http.StatusPreconditionFailed, "",
+		map[string]string{"Destination": "/dav/Documents/moved.txt", "Overwrite": "F"})
+	e.expect("COPY", "/Documents/report.txt", http.StatusCreated, "",
+		map[string]string{"Destination": "/dav/Documents/copy.txt"})
+	if node, ok := e.api.Lookup("/Documents/copy.txt"); !ok || string(node.Data) != "0123456789" {
+		t.Fatalf("同目录复制失败")
+	}
+	e.expect("COPY", "/Documents", http.StatusCreated, "", map[string]string{"Destination": "/dav/Projects/Docs"})
+	if _, ok := e.api.Lookup("/Projects/Docs/copy.txt"); !ok {
+		t.Fatalf("目录复制失败")
+	}
+
+	e.expect(http.MethodDelete, "/Documents", http.StatusNoContent, "", nil)
+	e.expect(http.MethodGet, "/Documents/report.txt", http.StatusNotFound, "", nil)
+	lock := e.expect("LOCK", "/Projects/new.txt", http.StatusCreated, "", nil)
+	if !strings.Contains(lock, "opaquelocktoken:") {
+		t.Fatalf("LOCK 应返回锁令牌: %s", lock)
+	}
+	e.expect("UNLOCK", "/Projects/new.txt", http.StatusNoContent, "", nil)
+}
+
+// TestHandler_OverwriteTransfer 覆盖时原目标在转移成功后才删除，失败时保留；不允许覆盖源的上级目录。
+func TestHandler_OverwriteTransfer(t *testing.T) {
+	e := newDavEnv(t)
+	e.expect(http.MethodPut, "/Documents/target.txt", http.StatusCreated, "old", nil)
+
+	e.expect("MOVE", "/Documents/report.txt", http.StatusForbidden, "", map[string]string{"Destination": "/dav/Documents"})
+	if _, ok := e.api.Lookup("/Documents/report.txt"); !ok {
+		t.Fatalf("拒绝覆盖上级目录后源文件应保留")
+	}
+
+	e.expect("MKCOL", "/Documents/Sub", http.StatusCreated, "", nil)
+	e.expect("COPY", "/Documents/Sub", http.StatusForbidden, "", map[string]string{"Destination": "/dav/Documents/target.txt"})
+	if node, ok := e.api.Lookup("/Documents/target.txt"); !ok || string(node.Data) != "old" {
+		t.Fatalf("转移失败时原目标应恢复: %+v", node)
+	}
+
+	e.expect("COPY", "/Documents/report.txt", http.StatusNoContent, "", map[string]string{"Destination": "/dav/Documents/target.txt"})
+	if node, ok := e.api.Lookup("/Documents/target.txt"); !ok || string(node.Data) != "0123456789" {
+		t.Fatalf("覆盖复制后内容不符: %+v", node)
+	}
+	e.expect("MOVE", "/Documents/target.txt", http.StatusNoContent, "", map[string]string{"Destination": "/dav/Documents/report.txt"})
+	if _, ok := e.api.Lookup("/Documents/target.txt"); ok {
+		t.Fatalf("移动后源文件应不存在")
+	}
+	body := e.expect("PROPFIND", "/Documents/", http.StatusMultiStatus, "", map[string]string{"Depth": "1"})
+	if strings.Contains(body, ".overwrite-") {
+		t.Fatalf("不应残留让位的原目标: %s", body)
+	}
+}
+
+// TestHandler_BasicAuth 开启认证后拒绝缺失或错误的凭据。
+func TestHandler_BasicAuth(t *testing.T) {
+	e := newDavEnv(t, WithBasicAuth("alice", "secret"))
+	resp, _ := e.do("PROPFIND", "/", "", map[string]string{"Depth": "0"})
+	if resp.StatusCode != http.StatusUnauthorized || !strings.Contains(resp.Header.Get("WWW-Authenticate"), "Basic") {
+		t.Fatalf("未认证请求应返回 401: %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodOptions, e.base+"/", nil)
+	req.SetBasicAuth("alice", "wrong")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("错误密码应返回 401: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+	req.SetBasicAuth("alice", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Header.Get("DAV") == "" {
+		t.Fatalf("正确凭据应通过认证: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,286 @@
+package webdav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dnslin/cloud189-desktop/core/drive"
+	"github.com/dnslin/cloud189-desktop/core/model"
+)
+
+const davNS = "DAV:"
+
+// liveProps 支持的 DAV: 属性，顺序即 allprop 的输出顺序。
+var liveProps = []string{
+	"displayname",
+	"resourcetype",
+	"getcontentlength",
+	"getcontenttype",
+	"getetag",
+	"getlastmodified",
+	"creationdate",
+	"supportedlock",
+}
+
+// propfindRequest PROPFIND 请求体，空请求体等价于 allprop。
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     *propList `xml:"DAV: prop"`
+}
+
+type propList struct {
+	Names []propElem `xml:",any"`
+}
+
+type propElem struct {
+	XMLName xml.Name
+}
+
+func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request, name string) (int, error) {
+	ctx := r.Context()
+	depth := r.Header.Get("Depth")
+	switch depth {
+	case "0", "1":
+	case "":
+		// 缺省按 1 处理，与主流客户端的预期一致。
+		depth = "1"
+	default:
+		// 不支持 infinity，避免一次请求遍历整个云盘。
+		return http.StatusForbidden, nil
+	}
+	var req propfindRequest
+	if body, err := io.ReadAll(r.Body); err != nil {
+		return http.StatusBadRequest, nil
+	} else if len(bytes.TrimSpace(body)) > 0 {
+		if err := xml.Unmarshal(body, &req); err != nil {
+			return http.StatusBadRequest, nil
+		}
+	}
+
+	file, err := h.drive.Stat(ctx, name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	files := []model.File{file}
+	if depth == "1" && file.IsFolder {
+		children, err := h.drive.List(ctx, name)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		files = append(files, children...)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<D:multistatus xmlns:D="DAV:">`)
+	for _, f := range files {
+		h.writeResponse(&buf, f, req)
+	}
+	buf.WriteString(`</D:multistatus>`)
+	writeMultistatus(w, buf.Bytes())
+	return 0, nil
+}
+
+// writeResponse 输出单个资源的 response 元素，未知属性归入 404 propstat。
+func (h *Handler) writeResponse(buf *bytes.Buffer, file model.File, req propfindRequest) {
+	buf.WriteString(`<D:response><D:href>`)
+	writeEscaped(buf, h.href(file.Path, file.IsFolder))
+	buf.WriteString(`</D:href>`)
+
+	var found, missing bytes.Buffer
+	switch {
+	case req.PropName != nil:
+		for _, prop := range liveProps {
+			if _, ok := propValue(file, prop); ok {
+				found.WriteString(`<D:` + prop + `/>`)
+			}
+		}
+	case req.Prop != nil:
+		for _, elem := range req.Prop.Names {
+			value, ok := "", false
+			if elem.XMLName.Space == davNS {
+				value, ok = propValue(file, elem.XMLName.Local)
+			}
+			if ok {
+				writeProp(&found, elem.XMLName.Local, value)
+			} else {
+				writeEmptyElem(&missing, elem.XMLName)
+			}
+		}
+	default:
+		for _, prop := range liveProps {
+			if value, ok := propValue(file, prop); ok {
+				writeProp(&found, prop, value)
+			}
+		}
+	}
+	writePropstat(buf, found.Bytes(), http.StatusOK)
+	writePropstat(buf, missing.Bytes(), http.StatusNotFound)
+	buf.WriteString(`</D:response>`)
+}
+
+// propValue 返回属性的 XML 片段（已转义），不适用于该资源时返回 false。
+func propValue(file model.File, prop string) (string, bool) {
+	switch prop {
+	case "displayname":
+		name := file.Name
+		if file.Path == "/" {
+			name = ""
+		}
+		return escape(name), true
+	case "resourcetype":
+		if file.IsFolder {
+			return `<D:collection/>`, true
+		}
+		return "", true
+	case "getcontentlength":
+		if file.IsFolder {
+			return "", false
+		}
+		return strconv.FormatInt(file.Size, 10), true
+	case "getcontenttype":
+		if file.IsFolder {
+			return "", false
+		}
+		return escape(contentType(file)), true
+	case "getetag":
+		if file.IsFolder {
+			return "", false
+		}
+		return escape(etag(file)), true
+	case "getlastmodified":
+		date := httpDate(file.UpdatedAt)
+		return date, date != ""
+	case "creationdate":
+		if file.CreatedAt.IsZero() {
+			return "", false
+		}
+		return file.CreatedAt.UTC().Format(time.RFC3339), true
+	case "supportedlock":
+		return `<D:lockentry><D:lockscope><D:exclusive/></D:lockscope><D:locktype><D:write/></D:locktype></D:lockentry>`, true
+	default:
+		return "", false
+	}
+}
+
+func writeProp(buf *bytes.Buffer, name, value string) {
+	if value == "" {
+		buf.WriteString(`<D:` + name + `/>`)
+		return
+	}
+	buf.WriteString(`<D:` + name + `>` + value + `</D:` + name + `>`)
+}
+
+func writeEmptyElem(buf *bytes.Buffer, name xml.Name) {
+	if name.Space == davNS {
+		buf.WriteString(`<D:` + name.Local + `/>`)
+		return
+	}
+	buf.WriteString(`<` + name.Local + ` xmlns="`)
+	writeEscaped(buf, name.Space)
+	buf.WriteString(`"/>`)
+}
+
+func writePropstat(buf *bytes.Buffer, props []byte, status int) {
+	if len(props) == 0 {
+		return
+	}
+	buf.WriteString(`<D:propstat><D:prop>`)
+	buf.Write(props)
+	buf.WriteString(`</D:prop><D:status>HTTP/1.1 ` + strconv.Itoa(status) + " " + http.StatusText(status) + `</D:status></D:propstat>`)
+}
+
+func writeMultistatus(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = w.Write(body)
+}
+
+func writeEscaped(buf *bytes.Buffer, s string) {
+	_ = xml.EscapeText(buf, []byte(s))
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	writeEscaped(&buf, s)
+	return buf.String()
+}
+
+// proppatchRequest 仅解析需要回显的属性名。
+type proppatchRequest struct {
+	XMLName xml.Name    `xml:"DAV: propertyupdate"`
+	Set     []propPatch `xml:"DAV: set"`
+	Remove  []propPatch `xml:"DAV: remove"`
+}
+
+type propPatch struct {
+	Prop propList `xml:"DAV: prop"`
+}
+
+// handleProppatch 云端不支持自定义属性，按成功回显以兼容修改时间等写入请求。
+func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request, name string) (int, error) {
+	file, err := h.drive.Stat(r.Context(), name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	var req proppatchRequest
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return http.StatusBadRequest, nil
+	}
+	var props bytes.Buffer
+	for _, patch := range append(req.Set, req.Remove...) {
+		for _, elem := range patch.Prop.Names {
+			writeEmptyElem(&props, elem.XMLName)
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<D:multistatus xmlns:D="DAV:"><D:response><D:href>`)
+	writeEscaped(&buf, h.href(file.Path, file.IsFolder))
+	buf.WriteString(`</D:href>`)
+	writePropstat(&buf, props.Bytes(), http.StatusOK)
+	buf.WriteString(`</D:response></D:multistatus>`)
+	writeMultistatus(w, buf.Bytes())
+	return 0, nil
+}
+
+// handleLock 返回一次性的锁令牌，不做互斥；目标不存在时与 RFC 一致创建空文件。
+func (h *Handler) handleLock(w http.ResponseWriter, r *http.Request, name string) (int, error) {
+	ctx := r.Context()
+	status := http.StatusOK
+	if _, err := h.drive.Stat(ctx, name); err != nil {
+		if !errors.Is(err, drive.ErrNotFound) {
+			return http.StatusInternalServerError, err
+		}
+		if code, err := h.requireParent(ctx, name); code != 0 {
+			return code, err
+		}
+		if _, err := h.drive.Create(ctx, name, bytes.NewReader(nil), 0); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		status = http.StatusCreated
+	}
+	token := "opaquelocktoken:" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<D:prop xmlns:D="DAV:"><D:lockdiscovery><D:activelock>`)
+	buf.WriteString(`<D:locktype><D:write/></D:locktype><D:lockscope><D:exclusive/></D:lockscope>`)
+	buf.WriteString(`<D:depth>0</D:depth><D:timeout>Second-3600</D:timeout>`)
+	buf.WriteString(`<D:locktoken><D:href>` + token + `</D:href></D:locktoken>`)
+	buf.WriteString(`<D:lockroot><D:href>`)
+	writeEscaped(&buf, h.href(name, false))
+	buf.WriteString(`</D:href></D:lockroot></D:activelock></D:lockdiscovery></D:prop>`)
+	w.Header().Set("Lock-Token", "<"+token+">")
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+	return 0, nil
+}
@@ -2,4 +2,4 @@ module github.com/dnslin/cloud189-desktop
 
 go 1.22
 
-require github.com/google/uuid v1.6.0 // indirect
+require github.com/google/uuid v1.6.0